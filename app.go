@@ -0,0 +1,532 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/devfacet/gocmd/flagset"
+)
+
+// App represents a command line application that ties the flags, the usage and version printing,
+// the handlers and the exit code together
+type App struct {
+	// Name is the app name
+	Name string
+	// Version is the app version
+	Version string
+	// Description is the app description
+	Description string
+	// Flags hold user defined command line arguments and commands (i.e. a struct pointer or
+	// a slice of struct pointers those are merged into one namespace, see flagset.Options)
+	Flags interface{}
+	// Logger represents the logger that is being used for printing errors
+	Logger Logger
+	// Context is the parent context of the runners. Default is context.Background()
+	Context context.Context
+	// Signals are the signals those cancel the context of the runners. Default is os.Interrupt and syscall.SIGTERM
+	// An empty slice disables the signal handling
+	Signals []os.Signal
+	// GracePeriod is the duration that the runners have for returning after the cancellation
+	// before the process exits. Default is 0 which means no forced exit
+	GracePeriod time.Duration
+	// Plugins runs the executables named `<name>-<command>` on PATH for the unknown top level commands
+	Plugins bool
+	// Prompt is the prompt of the interactive mode (see RunShell method)
+	Prompt string
+	// Recover converts the panics of the handlers and the runners into errors (see PanicError)
+	Recover bool
+	// Jobs enables the background jobs. The `--detach` flag starts the command in the background by
+	// capturing its output and the `jobs`, `logs` and `kill` commands manage them
+	Jobs bool
+	// JobsDir is the directory of the job files. Default is `<name>/jobs` in the cache directory of the user
+	JobsDir string
+	// DryRun enables the `--dry-run` flag. When it's present the runners receive a context those
+	// carries the dry-run mode (see DryRun function) and the app messages are annotated with `[dry-run]`
+	DryRun bool
+	// Config enables the `--config PATH` flag those overrides the config files (see ConfigFile field).
+	// It can be repeated and the later files override the values of the earlier ones.
+	// When there is no config file, `$XDG_CONFIG_HOME/<name>/config.*` and `/etc/<name>/config.*`
+	// are searched in order (json, ini and conf) and the first existing one is used (see Cmd.ConfigFile).
+	// The `config init` command prints the default config (i.e. `app config init --format=ini > app.ini`)
+	// and the `config show` command prints the effective values and their sources (see Cmd.Effective).
+	Config bool
+	// FlagOrder is the order of the options in the usage (see Options.FlagOrder)
+	FlagOrder string
+	// CommandOrder is the order of the commands in the usage (see Options.CommandOrder)
+	CommandOrder string
+	// HideDefaults hides the default values of the options in the usage (see Options.HideDefaults)
+	HideDefaults bool
+	// HideEnvVars hides the env variable names of the options in the usage (see Options.HideEnvVars)
+	HideEnvVars bool
+	// HideRequired hides the required markers of the options in the usage (see Options.HideRequired)
+	HideRequired bool
+	// Color is the color mode of the usage content and the errors: auto (default), always or never.
+	// The auto mode enables the colors when the standard output is a terminal and `NO_COLOR` is not set
+	Color string
+	// Width is the width of the usage content for wrapping the descriptions (see Options.Width)
+	Width int
+	// Chain runs the sibling commands in one invocation sequentially (stop or continue on error, see Options.Chain)
+	Chain string
+	// Localizer translates the errors, the warnings and the usage headings (see Options.Localizer)
+	Localizer flagset.Localizer
+	// Pager pipes the usage through the pager when it doesn't fit the terminal (see Options.Pager)
+	Pager bool
+	// UsageOnError is what is printed after the flag errors: usage, hint or none (see Options.UsageOnError)
+	UsageOnError string
+	// ErrorWriter is the writer of the errors and the warnings when there is no logger (i.e. os.Stderr)
+	ErrorWriter io.Writer
+	// Header is the text those is printed before the usage of all the commands (see Options.Header)
+	Header string
+	// Footer is the text those is printed after the usage of all the commands (see Options.Footer)
+	Footer string
+	// Banner is the text those is printed before the top level usage only (see Options.Banner)
+	Banner string
+	// Topics are the help topics those are printed by the help command (see Options.Topics)
+	Topics []HelpTopic
+	// VersionFormat is the format of the version for the version flags: short, full or json (see Options.VersionFormat)
+	VersionFormat string
+	// HelpStyle is the layout of the usage content (see HelpStyle type)
+	HelpStyle HelpStyle
+	// ConfigFile is the path of the JSON or INI config file (see Options.ConfigFile)
+	ConfigFile string
+	// ConfigFiles are the paths of the config files those are merged in order (see Options.ConfigFiles)
+	ConfigFiles []string
+	// ConfigSource reads the config files those have a URL (see Options.ConfigSource)
+	ConfigSource flagset.ConfigSource
+	// SecretStore is the store of the secrets for the keyring tags (see Options.SecretStore)
+	SecretStore flagset.SecretStore
+	// SecretsDir is the directory of the secret files (see Options.SecretsDir)
+	SecretsDir string
+	// ExpandConfig expands the env variables in the config values (see Options.ExpandConfig)
+	ExpandConfig bool
+	// Precedence is the order of the value sources (see Options.Precedence)
+	Precedence []string
+	// EnvPrefix is the prefix of all the env variable names (see Options.EnvPrefix)
+	EnvPrefix string
+	// AutoEnv derives the env variable names from the long names (see Options.AutoEnv)
+	AutoEnv bool
+	// OnParse is called after the command line arguments are parsed with the flag errors if any
+	OnParse func(cmd *Cmd, errs []error)
+	// OnCommandStart is called before the handlers and the runners with the command path (i.e. `app deploy rollback`).
+	// The returned context is passed to the runners (i.e. for tracing). Nil keeps the current one
+	OnCommandStart func(ctx context.Context, name string) context.Context
+	// OnCommandEnd is called after the handlers and the runners with the command path, the duration and the error
+	OnCommandEnd func(ctx context.Context, name string, d time.Duration, err error)
+	// ExitCode maps the errors to the exit codes. Default is DefaultExitCode
+	// Flag errors are passed as UsageError
+	ExitCode func(err error) int
+
+	configWatchers []func(cmd *Cmd, keys []string) // see WatchConfig method
+	dryRunFlag     *bool                           // see DryRun field
+	detachFlag     *bool                           // see Jobs field
+}
+
+// Run parses the command line arguments, prints the usage or the version when they are requested,
+// otherwise prints all the flag errors or runs the flag handlers and the runners (see Runner interface).
+// It returns the exit code for the process (i.e. `os.Exit(app.Run())`, see ExitCode field).
+func (app *App) Run() int {
+	cmd, err := newCmd(app.options())
+	if err != nil {
+		cmd.printError(err)
+		return 1
+	}
+
+	// Plugin commands
+	if app.Plugins {
+		if path, args := cmd.pluginCommand(); path != "" {
+			code, err := runPlugin(path, args)
+			if err != nil {
+				cmd.printError(err)
+			}
+			return code
+		}
+	}
+
+	var args []string
+	if len(os.Args) > 1 {
+		args = os.Args[1:]
+	}
+
+	if app.DryRun {
+		cmd.logger = &dryRunLogger{Logger: cmd.logger, cmd: cmd}
+	}
+
+	// Background jobs (i.e. `app --detach foo`). The flag errors are reported before detaching and
+	// the jobs keep the other flags (i.e. `--dry-run` or `--config`)
+	if *app.detachFlag {
+		if len(cmd.FlagErrors()) > 0 {
+			return app.run(cmd, args)
+		}
+		detach := cmd.flagSet.FlagByLong("detach")
+		job, err := app.startJob(cmd, withoutFlagArgs(cmd.flagSet, detach, args), withoutFlagArgs(cmd.flagSet, detach, cmd.flagSet.RedactedArgs()))
+		if err != nil {
+			cmd.printError(err)
+			return app.exitCode(err)
+		}
+		fmt.Printf(cmd.message("job %d started (pid %d)")+"\n", job.ID, job.PID)
+		return 0
+	}
+
+	return app.run(cmd, args)
+}
+
+// RunShell runs the app in the interactive mode. It reads the lines from the standard input, splits them
+// into the arguments (see flagset.SplitArgs) and runs each line as the command line arguments by reusing
+// the flags until `exit` or EOF. It returns the exit code for the process.
+func (app *App) RunShell() int {
+	cmd, err := newCmd(app.options())
+	if err != nil {
+		cmd.printError(err)
+		return 1
+	}
+
+	if app.DryRun {
+		cmd.logger = &dryRunLogger{Logger: cmd.logger, cmd: cmd}
+	}
+
+	// Iterate over the lines
+	name := cmd.name
+	if len(os.Args) > 0 {
+		name = os.Args[0]
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		if app.Prompt != "" {
+			fmt.Print(app.Prompt)
+		}
+		if !scanner.Scan() {
+			break
+		}
+		args, err := flagset.SplitArgs(scanner.Text())
+		if err != nil {
+			cmd.printError(err)
+			continue
+		} else if len(args) == 0 {
+			continue
+		} else if len(args) == 1 && args[0] == "exit" {
+			break
+		}
+		cmd.flagSet.Parse(append([]string{name}, args...)) // the flag errors are printed by run
+		app.run(cmd, args)
+	}
+	if err := scanner.Err(); err != nil {
+		cmd.printError(err)
+		return 1
+	}
+
+	return 0
+}
+
+// run prints the usage, the version or the flag errors, or runs the flag handlers and the runners
+// for the parsed command by the given arguments (without the program name). It returns the exit code.
+func (app *App) run(cmd *Cmd, args []string) int {
+	cmd.dryRun = *app.dryRunFlag
+	if app.OnParse != nil {
+		app.OnParse(cmd, cmd.FlagErrors())
+	}
+
+	// Help topics (i.e. `app help environment`) and command (i.e. `app help foo`)
+	if topic := cmd.helpTopic(args); topic != nil {
+		cmd.printUsageContent(cmd.topicContent(topic))
+		return 0
+	} else if ok, flag, err := cmd.helpCommand(args); err != nil {
+		cmd.printError(err)
+		return app.exitCode(&UsageError{Err: err})
+	} else if ok {
+		cmd.printUsageContent(cmd.commandUsageContent(flag))
+		return 0
+	}
+
+	// Version command (i.e. `app version` or `app version --json`)
+	if ok, asJSON := cmd.versionCommand(args); ok {
+		if asJSON {
+			cmd.PrintVersionJSON()
+		} else {
+			cmd.PrintVersion(true)
+		}
+		return 0
+	}
+
+	// Job commands (i.e. `app jobs`, `app logs 1` or `app kill 1`)
+	if app.Jobs {
+		if ok, err := app.jobCommand(cmd, args); err != nil {
+			cmd.printError(err)
+			return app.exitCode(err)
+		} else if ok {
+			return 0
+		}
+	}
+
+	// Config commands (i.e. `app config init` or `app config show`)
+	if app.Config {
+		if ok, err := app.configCommand(cmd, args); err != nil {
+			cmd.printError(err)
+			return app.exitCode(err)
+		} else if ok {
+			return 0
+		}
+	}
+
+	// Version (i.e. `app --version` or `app --version=json`)
+	if format, err := cmd.versionFormat(); err != nil {
+		cmd.printError(err)
+		cmd.printErrorUsage(cmd.flagSet.ActiveCommand(), false, "")
+		return app.exitCode(&UsageError{Err: err})
+	} else if format != "" {
+		cmd.PrintVersionFormat(format)
+		return 0
+	}
+
+	// Help (flags those implement the Runner interface and default commands run without arguments)
+	// The usage of the present command is printed for the help flags (i.e. `app foo --help`)
+	if cmd.helpFlagged() {
+		cmd.printUsageContent(cmd.commandUsageContent(cmd.flagSet.ActiveCommand()))
+		return 0
+	} else if _, ok := app.Flags.(Runner); len(args) == 0 && !ok && cmd.flagSet.ActiveCommand() == nil {
+		cmd.PrintUsage()
+		return 0
+	}
+
+	// Warnings
+	for _, w := range cmd.Warnings() {
+		cmd.logger.Printf("%s %s\n", cmd.colorize(cmd.message("warning:"), colorYellow), w)
+	}
+
+	// Errors
+	if errs := cmd.FlagErrors(); len(errs) > 0 {
+		for _, err := range errs {
+			cmd.printError(err)
+		}
+		// Unknown commands print the usage of their parent command with the suggestions
+		if name, parent := cmd.flagSet.UnknownCommand(); name != "" {
+			suggestion := ""
+			if names := cmd.flagSet.CommandSuggestions(name, parent); names != nil {
+				suggestion = "\n" + fmt.Sprintf(cmd.message("Did you mean %s?"), strings.Join(names, " "+cmd.message("or")+" ")) + "\n"
+			}
+			cmd.printErrorUsage(parent, true, suggestion)
+			return app.exitCode(&UsageError{Err: errs[0]})
+		}
+		// Commands those require a subcommand print their usage
+		flag, forced := cmd.flagSet.ActiveCommand(), false
+		for _, f := range cmd.flagSet.Flags() {
+			if f.Kind() == "command" && f.Required() && f.Err() != nil && cmd.subcommands(f) != nil {
+				flag, forced = f, true
+				break
+			}
+		}
+		cmd.printErrorUsage(flag, forced, "")
+		return app.exitCode(&UsageError{Err: errs[0]})
+	}
+
+	// Commands those have subcommands but no runner or handler of their own print their usage
+	for _, f := range cmd.flagSet.ChainedCommands() {
+		if cmd.runnerOf(f) != nil || cmd.hasHandler(f) {
+			continue
+		}
+		if names := cmd.subcommands(f); names != nil {
+			err := cmd.errorf("command %s requires a subcommand: %s", f.Command(), strings.Join(names, ", "))
+			cmd.printError(err)
+			cmd.printErrorUsage(f, true, "")
+			return app.exitCode(&UsageError{Err: err})
+		}
+	}
+
+	// Handlers and runners
+	if cmd.dryRun {
+		cmd.logger.Printf("%s\n", cmd.message("no changes will be made"))
+	}
+	ctx, stop := app.signalContext(cmd)
+	defer stop()
+	if cmd.dryRun {
+		ctx = WithDryRun(ctx, true)
+	}
+	if app.configWatchers != nil && cmd.ConfigFiles() != nil {
+		stop := app.startConfigWatcher(ctx, cmd)
+		defer stop()
+	}
+	name := cmd.invocationName()
+	if app.OnCommandStart != nil {
+		if c := app.OnCommandStart(ctx, name); c != nil {
+			ctx = c
+		}
+	}
+	start := time.Now()
+	_, err := cmd.runHandlers()
+	if err == nil {
+		err = cmd.Run(ctx)
+	}
+	if app.OnCommandEnd != nil {
+		app.OnCommandEnd(ctx, name, time.Since(start), err)
+	}
+	if err != nil {
+		cmd.printError(err)
+		return app.exitCode(err)
+	}
+
+	return 0
+}
+
+// signalContext returns a context those is cancelled when one of the signals is received and
+// a function for releasing it. If the grace period is set then the process exits after it.
+func (app *App) signalContext(cmd *Cmd) (context.Context, func()) {
+	parent := app.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	signals := app.Signals
+	if signals == nil {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	} else if len(signals) == 0 {
+		return ctx, cancel
+	}
+
+	// Wait for the signals
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+	go func() {
+		select {
+		case <-ch:
+			cancel()
+			if app.GracePeriod > 0 {
+				select {
+				case <-time.After(app.GracePeriod):
+					cmd.exit(app.exitCode(ctx.Err()))
+				case <-done:
+				}
+			}
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(ch)
+		close(done)
+		cancel()
+	}
+}
+
+// withoutFlagArgs returns the given arguments without the parsed arguments of the given flag
+// (i.e. [foo] for `app --detach foo`). The arguments are matched in the parse order so the values of
+// the other flags and the arguments after the end-of-flags terminator are kept as is.
+func withoutFlagArgs(flagSet *flagset.FlagSet, flag *flagset.Flag, args []string) []string {
+	result := make([]string, 0, len(args))
+	parsed := flagSet.Args()
+	k := 0
+	for _, arg := range parsed {
+		if flag == nil || arg.FlagID() != flag.ID() || arg.Kind() != "arg" || arg.Terminated() {
+			continue
+		}
+		// The raw arguments of the flag (i.e. [--config app.json])
+		var raw []string
+		for i := arg.IndexFrom(); i < arg.IndexTo() && i > 0 && i <= len(parsed); i++ {
+			raw = append(raw, parsed[i-1].Arg())
+		}
+		for i := k; i+len(raw) <= len(args); i++ {
+			if equalArgs(args[i:i+len(raw)], raw) {
+				result = append(result, args[k:i]...)
+				k = i + len(raw)
+				break
+			}
+		}
+	}
+	return append(result, args[k:]...)
+}
+
+// equalArgs returns whether the given arguments are equal or not
+func equalArgs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// options returns the command options of the app (see Run and RunShell methods)
+func (app *App) options() Options {
+	o := Options{
+		Name:          app.Name,
+		Version:       app.Version,
+		Description:   app.Description,
+		Flags:         app.Flags,
+		Logger:        app.Logger,
+		Recover:       app.Recover,
+		Chain:         app.Chain,
+		Width:         app.Width,
+		Color:         app.Color,
+		FlagOrder:     app.FlagOrder,
+		CommandOrder:  app.CommandOrder,
+		HideDefaults:  app.HideDefaults,
+		HideEnvVars:   app.HideEnvVars,
+		HideRequired:  app.HideRequired,
+		Localizer:     app.Localizer,
+		Pager:         app.Pager,
+		UsageOnError:  app.UsageOnError,
+		ErrorWriter:   app.ErrorWriter,
+		Header:        app.Header,
+		Footer:        app.Footer,
+		Banner:        app.Banner,
+		Topics:        app.Topics,
+		VersionFormat: app.VersionFormat,
+		HelpStyle:     app.HelpStyle,
+		ConfigFile:    app.ConfigFile,
+		ConfigFiles:   app.ConfigFiles,
+		ConfigSource:  app.ConfigSource,
+		SecretStore:   app.SecretStore,
+		SecretsDir:    app.SecretsDir,
+		ExpandConfig:  app.ExpandConfig,
+		Precedence:    app.Precedence,
+		EnvPrefix:     app.EnvPrefix,
+		AutoEnv:       app.AutoEnv,
+	}
+	if app.Config {
+		o.ConfigPaths = configSearchPaths(app.Name)
+	}
+	o.builtins = app.builtinFlags()
+	return o
+}
+
+// builtinFlags returns the built-in flags of the enabled features (i.e. `--dry-run`) or nil if there is none.
+// Their values are kept by the flag pointers of the app (i.e. dryRunFlag).
+func (app *App) builtinFlags() *flagset.Builder {
+	app.dryRunFlag, app.detachFlag = new(bool), new(bool)
+	if !app.DryRun && !app.Jobs && !app.Config {
+		return nil
+	}
+	var b flagset.Builder
+	if app.DryRun {
+		app.dryRunFlag = b.Bool("dry-run", "", "Show what would be done without making any changes")
+	}
+	if app.Jobs {
+		app.detachFlag = b.Bool("detach", "", "Run the command in the background")
+	}
+	if app.Config {
+		b.StringSlice("config", "", "Load the config from the given file (can be repeated)", flagset.WithTag("config-file", "true"), flagset.WithTag("placeholder", "PATH"))
+	}
+	return &b
+}
+
+// exitCode returns the exit code for the given error
+func (app *App) exitCode(err error) int {
+	if app.ExitCode != nil {
+		return app.ExitCode(err)
+	}
+	return DefaultExitCode(err)
+}
@@ -0,0 +1,1156 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/devfacet/gocmd"
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type appFlags struct {
+	Help    bool   `short:"h" long:"help" global:"true"`
+	Version bool   `short:"v" long:"version"`
+	Name    string `long:"name" required:"true"`
+	Count   int    `long:"count"`
+	ran     bool
+}
+
+func (f *appFlags) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	f.ran = true
+	if f.Name == "fail" {
+		return errors.New("failed to run")
+	}
+	return nil
+}
+
+type appSignal struct{}
+
+func (f *appSignal) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(5 * time.Second):
+		return errors.New("timeout")
+	}
+}
+
+func TestApp_Run(t *testing.T) {
+	Convey("should run the app and return the exit code", t, func() {
+		var buf bytes.Buffer
+		flags := appFlags{}
+		app := gocmd.App{
+			Name:    "test",
+			Version: "1.0.0",
+			Flags:   &flags,
+			Logger:  log.New(&buf, "", 0),
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--name=foo")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.ran, ShouldBeTrue)
+		So(buf.String(), ShouldEqual, "")
+
+		flags = appFlags{}
+		os.Args = append(os.Args[:1], "--name=fail")
+		So(app.Run(), ShouldEqual, 1)
+		So(flags.ran, ShouldBeTrue)
+		So(buf.String(), ShouldEqual, "failed to run\n")
+
+		buf.Reset()
+		flags = appFlags{}
+		os.Args = append(os.Args[:1], "--count=x", "--foo")
+		So(app.Run(), ShouldEqual, 2)
+		So(flags.ran, ShouldBeFalse)
+		So(buf.String(), ShouldEqual, "argument --name is required\nfailed to parse 'x' as int\nunknown argument: --foo\n")
+
+		buf.Reset()
+		flags = appFlags{}
+		os.Args = append(os.Args[:1], "-v")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.ran, ShouldBeFalse)
+
+		flags = appFlags{}
+		os.Args = append(os.Args[:1], "-h")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.ran, ShouldBeFalse)
+
+		flags = appFlags{}
+		os.Args = os.Args[:1]
+		So(app.Run(), ShouldEqual, 2)
+		So(flags.ran, ShouldBeFalse)
+		So(buf.String(), ShouldEqual, "argument --name is required\n")
+
+		resetArgs()
+	})
+
+	Convey("should print the usage when there is no argument", t, func() {
+		app := gocmd.App{
+			Name:  "test",
+			Flags: &struct{}{},
+		}
+
+		resetArgs()
+		os.Args = os.Args[:1]
+		So(app.Run(), ShouldEqual, 0)
+
+		resetArgs()
+	})
+
+	Convey("should return the custom exit codes", t, func() {
+		var buf bytes.Buffer
+		flags := struct {
+			Deploy runnerDeploy `command:"deploy"`
+		}{}
+		app := gocmd.App{
+			Name:   "test",
+			Flags:  &flags,
+			Logger: log.New(&buf, "", 0),
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "deploy")
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "env is required\n")
+
+		app.ExitCode = func(err error) int {
+			if _, ok := err.(*gocmd.UsageError); ok {
+				return 64
+			}
+			return 70
+		}
+		So(app.Run(), ShouldEqual, 70)
+		os.Args = append(os.Args[:1], "deploy", "--foo")
+		So(app.Run(), ShouldEqual, 64)
+
+		resetArgs()
+	})
+
+	Convey("should run the default command when there is no argument", t, func() {
+		flags := struct {
+			Rollback runnerRollback `command:"rollback" default:"true"`
+		}{}
+		app := gocmd.App{
+			Name:  "test",
+			Flags: &flags,
+		}
+
+		resetArgs()
+		os.Args = os.Args[:1]
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.Rollback.ran, ShouldBeTrue)
+
+		resetArgs()
+	})
+
+	Convey("should run the lines in the interactive mode", t, func() {
+		f, err := ioutil.TempFile("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.Remove(f.Name())
+		_, err = f.WriteString("--name=foo\n--name=fail\n'foo\n\n--count=x --name=bar\nexit\n--name=baz\n")
+		So(err, ShouldBeNil)
+		_, err = f.Seek(0, 0)
+		So(err, ShouldBeNil)
+		stdin := os.Stdin
+		os.Stdin = f
+		defer func() { os.Stdin = stdin }()
+
+		var buf bytes.Buffer
+		flags := appFlags{}
+		app := gocmd.App{
+			Name:   "test",
+			Flags:  &flags,
+			Logger: log.New(&buf, "", 0),
+		}
+
+		resetArgs()
+		So(app.RunShell(), ShouldEqual, 0)
+		So(flags.ran, ShouldBeTrue)
+		So(flags.Name, ShouldEqual, "bar")
+		So(buf.String(), ShouldEqual, "failed to run\nunterminated quote\nfailed to parse 'x' as int\n")
+
+		resetArgs()
+	})
+
+	Convey("should cancel the context of the runners on the signals", t, func() {
+		if runtime.GOOS == "windows" {
+			return // interrupt is not supported
+		}
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Name:        "test",
+			Flags:       &appSignal{},
+			Logger:      log.New(&buf, "", 0),
+			GracePeriod: time.Minute,
+		}
+
+		resetArgs()
+		os.Args = os.Args[:1]
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "context canceled\n")
+
+		resetArgs()
+	})
+
+	Convey("should print the errors in color", t, func() {
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Name:   "test",
+			Flags:  &appFlags{},
+			Logger: log.New(&buf, "", 0),
+			Color:  "always",
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--name=fail")
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "\x1b[31mfailed to run\x1b[0m\n")
+
+		buf.Reset()
+		app.Color = "never"
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "failed to run\n")
+
+		buf.Reset()
+		app.Color = "foo"
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "invalid color mode foo\n")
+
+		resetArgs()
+	})
+
+	Convey("should print the hint by the usage on error mode", t, func() {
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Name:         "test",
+			Flags:        &appFlags{},
+			ErrorWriter:  &buf,
+			UsageOnError: "hint",
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--name=foo", "--foo")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldEqual, "unknown argument: --foo\nRun 'test --help' for usage.\n")
+
+		buf.Reset()
+		app.UsageOnError = "none"
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldEqual, "unknown argument: --foo\n")
+
+		buf.Reset()
+		app.UsageOnError = "foo"
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "invalid usage on error mode foo\n")
+
+		resetArgs()
+	})
+
+	Convey("should fail for the invalid version formats", t, func() {
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Name:         "test",
+			Version:      "1.0.0",
+			Flags:        &appFlags{},
+			ErrorWriter:  &buf,
+			UsageOnError: "none",
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--version=yaml")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldEqual, "invalid version format yaml\n")
+
+		buf.Reset()
+		os.Args = append(os.Args[:1], "--name=foo", "--version=json", "--foo")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldEqual, "unknown argument: --foo\n")
+
+		resetArgs()
+	})
+
+	Convey("should print the usage of the required command to the error writer", t, func() {
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Name: "test",
+			Flags: &struct {
+				Math struct {
+					Sqrt struct {
+					} `command:"sqrt" description:"Calculate square root"`
+					Pow struct {
+					} `command:"pow" description:"Calculate base exponential"`
+				} `command:"math" description:"Math functions" required:"true"`
+			}{},
+			ErrorWriter: &buf,
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "math")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldStartWith, "command math requires a subcommand: sqrt, pow\nUsage: test math COMMAND [options...]\n")
+
+		buf.Reset()
+		os.Args = append(os.Args[:1], "math", "pow")
+		So(app.Run(), ShouldEqual, 0)
+		So(buf.String(), ShouldEqual, "")
+
+		resetArgs()
+	})
+
+	Convey("should fail for the invalid help topics", t, func() {
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Name:   "test",
+			Flags:  &appFlags{},
+			Logger: log.New(&buf, "", 0),
+			Topics: []gocmd.HelpTopic{{Name: "config"}, {Name: "config"}},
+		}
+
+		resetArgs()
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "help topic config is already defined\n")
+
+		buf.Reset()
+		app.Topics = []gocmd.HelpTopic{{Description: "Config"}}
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "help topic name is required\n")
+
+		resetArgs()
+	})
+
+	Convey("should print the translated errors", t, func() {
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Name:      "test",
+			Flags:     &appFlags{},
+			Logger:    log.New(&buf, "", 0),
+			Localizer: flagset.Catalog{"unknown argument: %s%s": "unbekanntes Argument: %s%s"},
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--name=foo", "--foo")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldEqual, "unbekanntes Argument: --foo\n")
+
+		resetArgs()
+	})
+
+	Convey("should call the instrumentation hooks", t, func() {
+		var calls []string
+		var parseErrs []error
+		var endErr error
+		flags := struct {
+			Deploy runnerDeploy `command:"deploy"`
+		}{}
+		app := gocmd.App{
+			Name:   "test",
+			Flags:  &flags,
+			Logger: log.New(ioutil.Discard, "", 0),
+			OnParse: func(cmd *gocmd.Cmd, errs []error) {
+				calls = append(calls, "parse")
+				parseErrs = errs
+			},
+			OnCommandStart: func(ctx context.Context, name string) context.Context {
+				calls = append(calls, "start: "+name)
+				return context.WithValue(ctx, runnerKey{}, "traced")
+			},
+			OnCommandEnd: func(ctx context.Context, name string, d time.Duration, err error) {
+				calls = append(calls, "end: "+name)
+				So(ctx.Value(runnerKey{}), ShouldEqual, "traced")
+				So(d, ShouldBeGreaterThanOrEqualTo, 0)
+				endErr = err
+			},
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "deploy", "--env=prod", "rollback")
+		So(app.Run(), ShouldEqual, 0)
+		So(calls, ShouldResemble, []string{"parse", "start: test deploy rollback", "end: test deploy rollback"})
+		So(parseErrs, ShouldBeNil)
+		So(endErr, ShouldBeNil)
+
+		calls = nil
+		os.Args = append(os.Args[:1], "deploy")
+		So(app.Run(), ShouldEqual, 1)
+		So(calls, ShouldResemble, []string{"parse", "start: test deploy", "end: test deploy"})
+		So(flags.Deploy.ran, ShouldEqual, "traced")
+		So(endErr, ShouldResemble, errors.New("env is required"))
+
+		calls = nil
+		os.Args = append(os.Args[:1], "deploy", "--foo")
+		So(app.Run(), ShouldEqual, 2)
+		So(calls, ShouldResemble, []string{"parse"})
+		So(parseErrs, ShouldHaveLength, 1)
+		So(parseErrs[0], ShouldBeError, "unknown argument: --foo")
+
+		resetArgs()
+	})
+
+	Convey("should fill the flags by the config file", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		config := filepath.Join(dir, "config.json")
+		So(ioutil.WriteFile(config, []byte(`{"name": "foo", "count": 3}`), 0644), ShouldBeNil)
+
+		var buf bytes.Buffer
+		var configFile string
+		flags := appFlags{}
+		app := gocmd.App{
+			Name:       "test",
+			Flags:      &flags,
+			Logger:     log.New(&buf, "", 0),
+			ConfigFile: config,
+			OnParse: func(cmd *gocmd.Cmd, errs []error) {
+				configFile = cmd.ConfigFile()
+			},
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--count=5")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.ran, ShouldBeTrue)
+		So(flags.Name, ShouldEqual, "foo")
+		So(flags.Count, ShouldEqual, 5)
+		So(configFile, ShouldEqual, config)
+		So(buf.String(), ShouldEqual, "")
+
+		resetArgs()
+	})
+
+	Convey("should search the config file and override it by the config flag", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		So(os.MkdirAll(filepath.Join(dir, "test"), 0755), ShouldBeNil)
+		config := filepath.Join(dir, "test", "config.ini")
+		So(ioutil.WriteFile(config, []byte("name = foo\n"), 0644), ShouldBeNil)
+		other := filepath.Join(dir, "other.json")
+		So(ioutil.WriteFile(other, []byte(`{"name": "bar"}`), 0644), ShouldBeNil)
+		xdg := os.Getenv("XDG_CONFIG_HOME")
+		os.Setenv("XDG_CONFIG_HOME", dir)
+		defer os.Setenv("XDG_CONFIG_HOME", xdg)
+
+		var buf bytes.Buffer
+		var configFile string
+		flags := appFlags{}
+		app := gocmd.App{
+			Name:   "test",
+			Flags:  &flags,
+			Logger: log.New(&buf, "", 0),
+			Config: true,
+			OnParse: func(cmd *gocmd.Cmd, errs []error) {
+				configFile = cmd.ConfigFile()
+			},
+		}
+
+		resetArgs()
+		os.Args = os.Args[:1]
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.Name, ShouldEqual, "foo")
+		So(configFile, ShouldEqual, config)
+
+		flags = appFlags{}
+		os.Args = append(os.Args[:1], "--config", other)
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.Name, ShouldEqual, "bar")
+		So(configFile, ShouldEqual, other)
+
+		flags = appFlags{}
+		os.Args = append(os.Args[:1], "--config", other, "--config="+config, "--count=2")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.Name, ShouldEqual, "foo")
+		So(flags.Count, ShouldEqual, 2)
+		So(configFile, ShouldEqual, config)
+
+		flags = appFlags{}
+		os.Args = append(os.Args[:1], "--config="+filepath.Join(dir, "missing.json"))
+		So(app.Run(), ShouldEqual, 2)
+		So(flags.ran, ShouldBeFalse)
+		So(buf.String(), ShouldStartWith, "failed to load config file "+filepath.Join(dir, "missing.json"))
+		So(buf.String(), ShouldEndWith, "argument --name is required\n")
+
+		resetArgs()
+	})
+
+	Convey("should run the app by the builder flags", t, func() {
+		var buf bytes.Buffer
+		var b flagset.Builder
+		name := b.String("name", "", "Name of the user")
+		deploy := b.Command("deploy", "Deploy the app")
+		env := deploy.String("env", "", "Environment", flagset.WithRequired())
+		app := gocmd.App{
+			Name:   "test",
+			Flags:  &b,
+			Logger: log.New(&buf, "", 0),
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--name=foo", "deploy", "--env=prod")
+		So(app.Run(), ShouldEqual, 0)
+		So(*name, ShouldEqual, "foo")
+		So(*env, ShouldEqual, "prod")
+		So(buf.String(), ShouldEqual, "")
+
+		os.Args = append(os.Args[:1], "deploy")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldStartWith, "argument --env is required for deploy command\n")
+
+		resetArgs()
+	})
+
+	Convey("should return the flag definition errors", t, func() {
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Flags: &struct {
+				Foo bool `short:"foo"`
+			}{},
+			Logger: log.New(&buf, "", 0),
+		}
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "short argument foo in Foo field must be one character long\n")
+	})
+}
+
+func ExampleApp_Run_subcommand() {
+	resetArgs()
+	os.Args = []string{"gocmd.test", "math"}
+
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Math struct {
+				Precision int `short:"p" long:"precision" description:"Precision"`
+				Sqrt      struct {
+					Number float64 `short:"n" long:"number" description:"Number"`
+				} `command:"sqrt" description:"Calculate square root"`
+				Pow struct {
+				} `command:"pow" description:"Calculate base exponential"`
+			} `command:"math" description:"Math functions"`
+		}{},
+	}
+	app.Run()
+	// Output:
+	// command math requires a subcommand: sqrt, pow
+	// Usage: basic math [options...] COMMAND [options...]
+	//
+	// Math functions
+	//
+	// Options:
+	//   -p, --precision 	Precision
+	//
+	// Commands:
+	//   sqrt            	Calculate square root
+	//     -n, --number  	Number
+	//   pow             	Calculate base exponential
+
+	resetArgs()
+}
+
+func ExampleApp_Run_unknownCommand() {
+	resetArgs()
+	os.Args = []string{"gocmd.test", "math", "po"}
+
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Math struct {
+				Sqrt struct {
+				} `command:"sqrt" description:"Calculate square root"`
+				Pow struct {
+				} `command:"pow" description:"Calculate base exponential"`
+				Pos struct {
+				} `command:"pos" description:"Calculate position"`
+			} `command:"math" description:"Math functions"`
+		}{},
+	}
+	app.Run()
+	// Output:
+	// unknown argument: po, did you mean pow or pos?
+	// Usage: basic math COMMAND [options...]
+	//
+	// Math functions
+	//
+	// Commands:
+	//   sqrt   	Calculate square root
+	//   pow    	Calculate base exponential
+	//   pos    	Calculate position
+	//
+	// Did you mean pow or pos?
+
+	resetArgs()
+}
+
+func ExampleApp_Run_deprecated() {
+	resetArgs()
+	os.Args = []string{"gocmd.test", "old"}
+
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help bool     `short:"h" long:"help" description:"Display usage"`
+			Old  struct{} `command:"old" description:"Synchronize" deprecated:"renamed to sync" redirect:"sync"`
+			Sync struct{} `command:"sync" description:"Synchronize"`
+		}{},
+	}
+	app.Run()
+
+	os.Args = []string{"gocmd.test", "-h"}
+	app.Run()
+	// Output:
+	// warning: command old is deprecated: renamed to sync
+	// Usage: basic [options...] COMMAND [options...]
+	//
+	// Options:
+	//   -h, --help 	Display usage
+	//
+	// Commands:
+	//   old        	Synchronize (deprecated)
+	//   sync       	Synchronize
+
+	resetArgs()
+}
+
+func ExampleApp_Run_examples() {
+	resetArgs()
+	os.Args = []string{"gocmd.test", "-h"}
+
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help   bool `short:"h" long:"help" description:"Display usage" example:"basic -h"`
+			Deploy struct {
+				Env string `long:"env" description:"Environment" example:"basic deploy --env prod"`
+			} `command:"deploy" description:"Deploy the app" example:"basic deploy\nbasic deploy --dry-run"`
+		}{},
+	}
+	app.Run()
+	// Output:
+	// Usage: basic [options...] COMMAND [options...]
+	//
+	// Options:
+	//   -h, --help  	Display usage
+	//
+	// Commands:
+	//   deploy      	Deploy the app
+	//         --env 	Environment
+	//
+	// Examples:
+	//   basic -h
+	//   basic deploy
+	//   basic deploy --dry-run
+
+	resetArgs()
+}
+
+func ExampleApp_Run_help() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help bool `short:"h" long:"help" description:"Display usage" global:"true"`
+			Math struct {
+				Sqrt struct {
+					Number float64 `short:"n" long:"number" description:"Number"`
+				} `command:"sqrt" description:"Calculate square root"`
+			} `command:"math" description:"Math functions"`
+		}{},
+	}
+
+	os.Args = []string{"gocmd.test", "help", "foo"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "help", "math", "sqrt"}
+	app.Run()
+	// Output:
+	// unknown command: foo
+	// Usage: basic math sqrt [options...]
+	//
+	// Calculate square root
+	//
+	// Options:
+	//   -n, --number 	Number
+
+	resetArgs()
+}
+
+func ExampleApp_Run_helpFlag() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help bool `short:"h" long:"help" description:"Display usage" global:"true"`
+			Math struct {
+				Sqrt struct {
+					Number float64 `short:"n" long:"number" description:"Number"`
+				} `command:"sqrt" description:"Calculate square root"`
+			} `command:"math" description:"Math functions"`
+		}{},
+	}
+
+	os.Args = []string{"gocmd.test", "math", "sqrt", "--help"}
+	app.Run()
+	// Output:
+	// Usage: basic math sqrt [options...]
+	//
+	// Calculate square root
+	//
+	// Options:
+	//   -n, --number 	Number
+
+	resetArgs()
+}
+
+func ExampleApp_Run_composed() {
+	resetArgs()
+	logFlags := struct {
+		Verbose bool `short:"v" long:"verbose" description:"Verbose output"`
+	}{}
+	app := gocmd.App{
+		Name: "basic",
+		Flags: []interface{}{&struct {
+			Help bool `short:"h" long:"help" description:"Display usage"`
+			Math struct {
+				Sqrt struct {
+					Number float64 `short:"n" long:"number" description:"Number"`
+				} `command:"sqrt" description:"Calculate square root"`
+			} `command:"math" description:"Math functions"`
+		}{}, &logFlags},
+	}
+
+	os.Args = []string{"gocmd.test", "-h"}
+	app.Run()
+	// Output:
+	// Usage: basic [options...] COMMAND [options...]
+	//
+	// Options:
+	//   -h, --help       	Display usage
+	//   -v, --verbose    	Verbose output
+	//
+	// Commands:
+	//   math             	Math functions
+	//     sqrt           	Calculate square root
+	//       -n, --number 	Number
+
+	resetArgs()
+}
+
+func ExampleApp_Run_dryRun() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help   bool `short:"h" long:"help" description:"Display usage"`
+			Deploy struct {
+				Env string `long:"env" description:"Environment"`
+			} `command:"deploy" description:"Deploy the app"`
+		}{},
+		DryRun: true,
+	}
+
+	os.Args = []string{"gocmd.test", "-h"}
+	app.Run()
+	// Output:
+	// Usage: basic [options...] COMMAND [options...]
+	//
+	// Options:
+	//   -h, --help    	Display usage
+	//       --dry-run 	Show what would be done without making any changes
+	//
+	// Commands:
+	//   deploy        	Deploy the app
+	//         --env   	Environment
+
+	resetArgs()
+}
+
+func ExampleApp_Run_helpCommandFlag() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help    bool `short:"h" long:"help" description:"Display usage"`
+			Verbose bool `short:"v" description:"Verbose output"`
+			Deploy  struct {
+				Help bool   `short:"h" long:"help" description:"Display usage"`
+				Env  string `long:"env" description:"Environment"`
+				Undo struct {
+				} `command:"undo" description:"Undo the deployment"`
+			} `command:"deploy" description:"Deploy the app" example:"basic deploy --env prod"`
+		}{},
+	}
+
+	os.Args = []string{"gocmd.test", "deploy", "-h"}
+	app.Run()
+	// Output:
+	// Usage: basic deploy [options...] COMMAND [options...]
+	//
+	// Deploy the app
+	//
+	// Options:
+	//   -h, --help 	Display usage
+	//       --env  	Environment
+	//
+	// Commands:
+	//   undo       	Undo the deployment
+	//
+	// Examples:
+	//   basic deploy --env prod
+
+	resetArgs()
+}
+
+func ExampleApp_Run_usageOnError() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Deploy struct {
+				Env string `long:"env" required:"true" description:"Environment"`
+			} `command:"deploy" description:"Deploy the app"`
+		}{},
+		UsageOnError: "usage",
+	}
+
+	os.Args = []string{"gocmd.test", "deploy"}
+	app.Run()
+	// Output:
+	// argument --env is required for deploy command
+	// Usage: basic deploy [options...]
+	//
+	// Deploy the app
+	//
+	// Options:
+	//       --env 	Environment (required)
+
+	resetArgs()
+}
+
+func ExampleApp_Run_header() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help   bool `short:"h" long:"help" description:"Display usage"`
+			Deploy struct {
+			} `command:"deploy" description:"Deploy the app"`
+		}{},
+		Banner: "  _               _\n | |__   __ _ ___(_) ___\n | '_ \\ / _` / __| |/ __|\n",
+		Header: "Basic app v1.0.0",
+		Footer: "Report bugs at https://example.com/issues",
+	}
+
+	os.Args = []string{"gocmd.test", "-h"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "deploy", "-h"}
+	app.Run()
+	// Output:
+	//   _               _
+	//  | |__   __ _ ___(_) ___
+	//  | '_ \ / _` / __| |/ __|
+	//
+	// Basic app v1.0.0
+	//
+	// Usage: basic [options...] COMMAND [options...]
+	//
+	// Options:
+	//   -h, --help 	Display usage
+	//
+	// Commands:
+	//   deploy     	Deploy the app
+	//
+	// Report bugs at https://example.com/issues
+	//
+	// Basic app v1.0.0
+	//
+	// Usage: basic deploy
+	//
+	// Deploy the app
+	//
+	// Report bugs at https://example.com/issues
+
+	resetArgs()
+}
+
+func ExampleApp_Run_helpAll() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help   bool `short:"h" long:"help" description:"Display usage"`
+			Deploy struct {
+				Env   string `long:"env" description:"Environment"`
+				Retry int    `long:"retry" advanced:"true" description:"Number of retries"`
+				Trace bool   `long:"trace" group:"Debugging" description:"Trace the requests"`
+			} `command:"deploy" description:"Deploy the app" hide-groups:"Debugging" example:"basic deploy --env prod"`
+		}{},
+	}
+
+	os.Args = []string{"gocmd.test", "deploy", "-h"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "deploy", "--help-all"}
+	app.Run()
+	// Output:
+	// Usage: basic deploy [options...]
+	//
+	// Deploy the app
+	//
+	// Options:
+	//       --env 	Environment
+	//
+	// Examples:
+	//   basic deploy --env prod
+	//
+	// Usage: basic deploy [options...]
+	//
+	// Deploy the app
+	//
+	// Options:
+	//       --env   	Environment
+	//       --retry 	Number of retries
+	//
+	// Debugging:
+	//       --trace 	Trace the requests
+	//
+	// Examples:
+	//   basic deploy --env prod
+
+	resetArgs()
+}
+
+func ExampleApp_Run_helpTopic() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help   bool `short:"h" long:"help" description:"Display usage"`
+			Deploy struct {
+			} `command:"deploy" description:"Deploy the app"`
+		}{},
+		Width: 40,
+		Topics: []gocmd.HelpTopic{
+			{
+				Name:        "environment",
+				Description: "Environment variables",
+				Body:        "The app reads the following environment variables when they are set:\n\n  BASIC_TOKEN  API token\n",
+			},
+		},
+	}
+
+	os.Args = []string{"gocmd.test", "-h"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "help", "environment"}
+	app.Run()
+	// Output:
+	// Usage: basic [options...] COMMAND [options...]
+	//
+	// Options:
+	//   -h, --help 	Display usage
+	//
+	// Commands:
+	//   deploy     	Deploy the app
+	//
+	// Help topics:
+	//   environment 	Environment variables
+	//
+	// The app reads the following environment
+	// variables when they are set:
+	//
+	//   BASIC_TOKEN  API token
+
+	resetArgs()
+}
+
+func ExampleApp_Run_helpStyle() {
+	resetArgs()
+	app := gocmd.App{
+		Name: "basic",
+		Flags: &struct {
+			Help   bool `short:"h" long:"help" description:"Display usage"`
+			Deploy struct {
+				Port    int    `short:"p" long:"port" description:"Port number of the deployment server"`
+				Env     string `long:"env" placeholder:"ENV" description:"Environment"`
+				Verbose bool   `long:"verbose" description:"Verbose output"`
+			} `command:"deploy" description:"Deploy the app" example:"basic deploy --env prod"`
+		}{},
+		Width: 80,
+		HelpStyle: gocmd.HelpStyle{
+			Indent:    4,
+			ColumnGap: 2,
+			MaxWidth:  50,
+			ShowTypes: true,
+		},
+	}
+
+	os.Args = []string{"gocmd.test", "-h"}
+	app.Run()
+	// Output:
+	// Usage: basic [options...] COMMAND [options...]
+	//
+	// Options:
+	//     -h, --help          Display usage
+	//
+	// Commands:
+	//     deploy              Deploy the app
+	//         -p, --port int  Port number of the
+	//                         deployment server
+	//             --env ENV   Environment
+	//             --verbose   Verbose output
+	//
+	// Examples:
+	//     basic deploy --env prod
+
+	resetArgs()
+}
+
+func ExampleApp_Run_version() {
+	resetArgs()
+	app := gocmd.App{
+		Name:    "basic",
+		Version: "v1.0.0",
+		Flags:   &struct{}{},
+	}
+
+	os.Args = []string{"gocmd.test", "version"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "version", "--json"}
+	app.Run()
+	// Output:
+	// App name    : basic
+	// App version : 1.0.0
+	// Go version  : vTest
+	// {"name":"basic","version":"1.0.0","goVersion":"vTest"}
+
+	resetArgs()
+}
+
+func ExampleApp_Run_versionFormat() {
+	resetArgs()
+	app := gocmd.App{
+		Name:    "basic",
+		Version: "v1.0.0",
+		Flags: &struct {
+			Version bool `short:"v" long:"version" description:"Display version"`
+		}{},
+	}
+
+	os.Args = []string{"gocmd.test", "--version"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "--version=json"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "--version=full"}
+	app.Run()
+	app.VersionFormat = "json"
+	os.Args = []string{"gocmd.test", "-v"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "--version=short"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "-v=short"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "--version", "full"}
+	app.Run()
+	// Output:
+	// 1.0.0
+	// {"name":"basic","version":"1.0.0","goVersion":"vTest"}
+	// App name    : basic
+	// App version : 1.0.0
+	// Go version  : vTest
+	// {"name":"basic","version":"1.0.0","goVersion":"vTest"}
+	// 1.0.0
+	// 1.0.0
+	// App name    : basic
+	// App version : 1.0.0
+	// Go version  : vTest
+
+	resetArgs()
+}
+
+func ExampleApp_Run_configShow() {
+	resetArgs()
+	os.Setenv("BASIC_PORT", "9090")
+	defer os.Unsetenv("BASIC_PORT")
+	app := gocmd.App{
+		Name:   "basic",
+		Config: true,
+		Flags: &struct {
+			Port     int    `long:"port" default:"8080" env:"BASIC_PORT"`
+			Host     string `long:"host" default:"localhost"`
+			Password string `long:"password" secret:"true"`
+			Verbose  bool   `long:"verbose"`
+		}{},
+	}
+
+	os.Args = []string{"gocmd.test", "config", "show", "--password", "s3cr3t"}
+	app.Run()
+	// Output:
+	// KEY     	VALUE    	SOURCE
+	// port    	9090     	env:BASIC_PORT
+	// host    	localhost	default
+	// password	***      	arg
+	// verbose 	false    	-
+
+	resetArgs()
+}
+
+func ExampleApp_Run_configInit() {
+	resetArgs()
+	app := gocmd.App{
+		Name:   "basic",
+		Config: true,
+		Flags: &struct {
+			Port   int `long:"port" default:"8080" env:"BASIC_PORT" description:"Port to listen"`
+			Deploy struct {
+				Env string `long:"env" default:"dev" description:"Target environment"`
+			} `command:"deploy"`
+		}{},
+	}
+
+	os.Args = []string{"gocmd.test", "config", "init"}
+	app.Run()
+	os.Args = []string{"gocmd.test", "config", "init", "--format=ini"}
+	app.Run()
+	// Output:
+	// {
+	//   "_comment": {
+	//     "port": "Port to listen; env: BASIC_PORT"
+	//   },
+	//   "port": 8080,
+	//   "deploy": {
+	//     "_comment": {
+	//       "env": "Target environment"
+	//     },
+	//     "env": "dev"
+	//   }
+	// }
+	// ; Port to listen
+	// ; env: BASIC_PORT
+	// port = 8080
+	//
+	// [deploy]
+	//
+	// ; Target environment
+	// env = dev
+
+	resetArgs()
+}
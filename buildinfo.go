@@ -0,0 +1,28 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"runtime/debug"
+)
+
+// buildInfo returns the VCS revision and the commit time of the build if any
+func buildInfo() (string, string) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	revision, date := "", ""
+	for _, v := range bi.Settings {
+		switch v.Key {
+		case "vcs.revision":
+			revision = v.Value
+		case "vcs.time":
+			date = v.Value
+		}
+	}
+	return revision, date
+}
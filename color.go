@@ -0,0 +1,47 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+)
+
+// colorEnabled returns whether the colors are enabled by the given mode (auto, always or never)
+// The auto mode enables them when the standard output is a terminal and `NO_COLOR` is not set.
+func colorEnabled(mode string) (bool, error) {
+	switch mode {
+	case "", "auto":
+		_, noColor := os.LookupEnv("NO_COLOR")
+		return !noColor && terminalWidth() > 0, nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid color mode %s", mode)
+}
+
+// colorize returns the given value in the given color if the colors are enabled
+func (cmd *Cmd) colorize(val, color string) string {
+	if !cmd.color || val == "" {
+		return val
+	}
+	return color + val + colorReset
+}
+
+// printError prints the given error in red if the colors are enabled
+func (cmd *Cmd) printError(err error) {
+	cmd.logger.Printf("%s\n", cmd.colorize(err.Error(), colorRed))
+}
@@ -0,0 +1,198 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/devfacet/gocmd/flagset"
+	"github.com/devfacet/gocmd/table"
+)
+
+var (
+	// configExts are the extensions of the config files those are searched (see App.Config)
+	configExts = []string{".json", ".ini", ".conf"}
+	// configWatchInterval is the interval of checking the config files for the changes (see App.WatchConfig)
+	configWatchInterval = time.Second
+	// configPollInterval is the interval of reloading the remote config files (i.e. `https://host/app.json`)
+	configPollInterval = time.Minute
+)
+
+// WatchConfig registers the given callback for the config changes and enables watching the loaded
+// config files while the handlers and the runners are running (i.e. for the daemons). The files are
+// checked by their modification times and sizes and the URLs are checked by reloading them every minute
+// (the HTTP config source validates the cached responses by their ETag or Last-Modified header). When they
+// are changed the callbacks are called with the changed key paths (i.e. `deploy.env`) from the watcher
+// goroutine. The config is reloaded into a copy of the flags so the flags those are used by the runners
+// are never changed and the callbacks receive a command those has the reloaded values (i.e. `cmd.FlagValue("Port")`).
+func (app *App) WatchConfig(fn func(cmd *Cmd, keys []string)) {
+	if fn != nil {
+		app.configWatchers = append(app.configWatchers, fn)
+	}
+}
+
+// startConfigWatcher starts watching the config files of the given command by a copy of its flags
+// (see WatchConfig method) and returns the function those stops the watcher and waits for it
+func (app *App) startConfigWatcher(ctx context.Context, cmd *Cmd) func() {
+	flagSet, err := cmd.flagSet.Clone()
+	if err != nil {
+		cmd.printError(err)
+		return func() {}
+	}
+	watched := *cmd
+	watched.flagSet = flagSet
+
+	state := configState(flagSet.ConfigFiles())
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		app.watchConfig(ctx, &watched, state)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// watchConfig checks the loaded config files periodically until the given context is done and
+// reloads them when their state is changed or the poll interval of the URLs is passed (see configState function)
+func (app *App) watchConfig(ctx context.Context, cmd *Cmd, state string) {
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+	polled := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s := configState(cmd.ConfigFiles())
+		if s == state && (!strings.Contains(s, "://") || time.Since(polled) < configPollInterval) {
+			continue
+		}
+		state, polled = s, time.Now()
+
+		// Reload the config files and call the callbacks with the changed keys
+		keys, err := cmd.flagSet.ReloadConfig()
+		if err != nil {
+			cmd.printError(err)
+		}
+		if keys == nil {
+			continue
+		}
+		for _, fn := range app.configWatchers {
+			fn(cmd, keys)
+		}
+	}
+}
+
+// WriteDefaultConfig writes a config file those contains the default values, the descriptions and
+// the env variable names of the flags in the given format: json or ini (see flagset.FlagSet.WriteDefaultConfig)
+func (cmd *Cmd) WriteDefaultConfig(w io.Writer, format string) error {
+	return cmd.flagSet.WriteDefaultConfig(w, format)
+}
+
+// Effective returns the final values of the flags and their sources (see flagset.FlagSet.Effective)
+func (cmd *Cmd) Effective() []flagset.EffectiveValue {
+	return cmd.flagSet.Effective()
+}
+
+// configCommand runs the config commands (i.e. `app config init --format=ini` or `app config show`) and
+// returns whether the arguments are a config command or not. The commands those are defined by the flags
+// take precedence.
+func (app *App) configCommand(cmd *Cmd, args []string) (bool, error) {
+	if len(args) < 2 || args[0] != "config" || (args[1] != "init" && args[1] != "show") {
+		return false, nil
+	}
+	for _, v := range cmd.flagSet.Flags() {
+		if v.Kind() == "command" && v.ParentID() == -1 && v.Command() == "config" {
+			return false, nil
+		}
+	}
+
+	// Print the effective config (i.e. `app config show --port=80`)
+	// The arguments after the command are the flags those are already parsed.
+	if args[1] == "show" {
+		t := table.New(table.Options{})
+		t.AddRow("KEY", "VALUE", "SOURCE")
+		for _, v := range cmd.Effective() {
+			source := v.Source
+			switch {
+			case v.Source == "config":
+				source = v.Location
+			case v.Location != "":
+				source = v.Source + ":" + v.Location
+			case source == "":
+				source = "-"
+			}
+			t.AddRow(v.Key, v.Value, source)
+		}
+		fmt.Print(t.FormattedData())
+		return true, nil
+	}
+
+	// Print the default config (i.e. `app config init > app.json`)
+	format := "json"
+	for k := 2; k < len(args); k++ {
+		if strings.HasPrefix(args[k], "--format=") {
+			format = strings.TrimPrefix(args[k], "--format=")
+		} else if args[k] == "--format" && k+1 < len(args) {
+			format = args[k+1]
+			k++
+		} else {
+			return true, &UsageError{Err: cmd.errorf("unknown argument: %s", args[k])}
+		}
+	}
+	if err := cmd.WriteDefaultConfig(os.Stdout, format); err != nil {
+		return true, &UsageError{Err: err}
+	}
+	return true, nil
+}
+
+// configState returns the modification state of the given files (i.e. for detecting the changes).
+// The URLs have no state so they are listed as they are.
+func configState(paths []string) string {
+	var b bytes.Buffer
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%s:%d:%d\n", path, fi.ModTime().UnixNano(), fi.Size())
+		} else {
+			fmt.Fprintf(&b, "%s:-\n", path)
+		}
+	}
+	return b.String()
+}
+
+// configSearchPaths returns the standard config file paths for the given app name in order
+// (i.e. `$XDG_CONFIG_HOME/app/config.json`, `/etc/app/config.json`)
+func configSearchPaths(name string) []string {
+	if name == "" {
+		return nil
+	}
+	var dirs []string
+	if v := os.Getenv("XDG_CONFIG_HOME"); v != "" {
+		dirs = append(dirs, filepath.Join(v, name))
+	} else if v := os.Getenv("HOME"); v != "" {
+		dirs = append(dirs, filepath.Join(v, ".config", name))
+	}
+	dirs = append(dirs, filepath.Join("/etc", name))
+
+	var result []string
+	for _, dir := range dirs {
+		for _, ext := range configExts {
+			result = append(result, filepath.Join(dir, "config"+ext))
+		}
+	}
+	return result
+}
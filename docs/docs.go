@@ -0,0 +1,245 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+// Package docs provides functions for generating the documentation of the command line applications
+package docs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devfacet/gocmd"
+	"github.com/devfacet/gocmd/flagset"
+)
+
+// GenerateMarkdown generates one markdown page per command (i.e. `app.md`, `app_deploy.md`) of the given app
+// in the given directory. Each page has the synopsis, the options, the subcommands and the examples of the command.
+func GenerateMarkdown(app *gocmd.App, dir string) error {
+	if app == nil {
+		return errors.New("app is required")
+	} else if app.Name == "" {
+		return errors.New("app name is required")
+	}
+
+	// Parse a copy of the flags so the values of the app are not changed
+	var flags []*flagset.Flag
+	if app.Flags != nil {
+		zero, err := flagset.ZeroFlags(app.Flags)
+		if err != nil {
+			return err
+		}
+		flagSet, err := flagset.New(flagset.Options{Flags: zero, Args: []string{app.Name}, EnvPrefix: app.EnvPrefix, AutoEnv: app.AutoEnv})
+		if flagSet == nil {
+			return err
+		}
+		flags = flagSet.Flags()
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	g := generator{name: app.Name, description: app.Description, flags: flags}
+	if err := g.write(dir, nil); err != nil {
+		return err
+	}
+	for _, flag := range flags {
+		if flag.Kind() == "command" {
+			if err := g.write(dir, flag); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// generator represents a markdown generator
+type generator struct {
+	name        string
+	description string
+	flags       []*flagset.Flag
+}
+
+// write writes the page of the given command or the top level one for nil
+func (g *generator) write(dir string, cmd *flagset.Flag) error {
+	return ioutil.WriteFile(filepath.Join(dir, g.fileName(cmd)), []byte(g.page(cmd)), 0644)
+}
+
+// page returns the markdown content of the given command
+func (g *generator) page(cmd *flagset.Flag) string {
+	path := g.path(cmd)
+	parentID := -1
+	description := g.description
+	if cmd != nil {
+		parentID = cmd.ID()
+		description = cmd.Description()
+		if cmd.LongDescription() != "" {
+			description = cmd.LongDescription()
+		}
+	}
+	var options, commands, examples []*flagset.Flag
+	for _, flag := range g.flags {
+		if flag.ParentID() != parentID {
+			continue
+		}
+		if flag.Kind() == "arg" {
+			options = append(options, flag)
+			// Examples of the commands are on their own pages
+			if flag.Examples() != nil {
+				examples = append(examples, flag)
+			}
+		} else if flag.Kind() == "command" {
+			commands = append(commands, flag)
+		}
+	}
+
+	// Title and description
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", strings.Join(path, " "))
+	if cmd != nil && cmd.Deprecated() == "true" {
+		b.WriteString("> Deprecated\n\n")
+	} else if cmd != nil && cmd.Deprecated() != "" {
+		fmt.Fprintf(&b, "> Deprecated: %s\n\n", cmd.Deprecated())
+	}
+	if description != "" {
+		fmt.Fprintf(&b, "%s\n\n", description)
+	}
+
+	// Synopsis
+	synopsis := strings.Join(path, " ")
+	if options != nil {
+		synopsis += " [options...]"
+	}
+	if commands != nil {
+		synopsis += " COMMAND [options...]"
+	}
+	fmt.Fprintf(&b, "## Synopsis\n\n```\n%s\n```\n\n", synopsis)
+
+	// Options
+	if options != nil {
+		b.WriteString("## Options\n\n| Option | Description | Default | Env |\n| --- | --- | --- | --- |\n")
+		for _, flag := range options {
+			def := flag.ValueDefault()
+			if def == "false" {
+				def = ""
+			} else if def != "" && flag.Secret() {
+				def = flagset.SecretPlaceholder
+			}
+			desc := flag.Description()
+			if flag.Required() {
+				desc = strings.TrimSpace(desc + " (required)")
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", code(argName(flag)), escape(desc), code(def), code(flag.Env()))
+		}
+		b.WriteString("\n")
+	}
+
+	// Commands
+	if commands != nil {
+		b.WriteString("## Commands\n\n| Command | Description |\n| --- | --- |\n")
+		for _, flag := range commands {
+			fmt.Fprintf(&b, "| [%s](%s) | %s |\n", flag.Command(), g.fileName(flag), escape(flag.Description()))
+		}
+		b.WriteString("\n")
+	}
+
+	// Examples
+	var lines []string
+	if cmd != nil {
+		lines = append(lines, cmd.Examples()...)
+	}
+	for _, flag := range examples {
+		lines = append(lines, flag.Examples()...)
+	}
+	if lines != nil {
+		fmt.Fprintf(&b, "## Examples\n\n```\n%s\n```\n\n", strings.Join(lines, "\n"))
+	}
+
+	// Parent and related commands
+	if cmd != nil {
+		parent := g.flagByID(cmd.ParentID())
+		fmt.Fprintf(&b, "## See also\n\n- [%s](%s)\n", strings.Join(g.path(parent), " "), g.fileName(parent))
+		for _, v := range cmd.SeeAlso() {
+			if ref := g.commandByPath(v); ref != nil && ref == parent {
+				continue
+			} else if ref != nil {
+				fmt.Fprintf(&b, "- [%s](%s)\n", v, g.fileName(ref))
+			} else {
+				fmt.Fprintf(&b, "- %s\n", v)
+			}
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// path returns the app name and the command names from the top level one to the given command
+func (g *generator) path(cmd *flagset.Flag) []string {
+	var result []string
+	for cmd != nil {
+		result = append([]string{cmd.Command()}, result...)
+		cmd = g.flagByID(cmd.ParentID())
+	}
+	return append([]string{g.name}, result...)
+}
+
+// fileName returns the file name of the page of the given command (i.e. `app_deploy_undo.md`)
+func (g *generator) fileName(cmd *flagset.Flag) string {
+	return strings.Join(g.path(cmd), "_") + ".md"
+}
+
+// flagByID returns the flag by the given id or nil if it's not found
+func (g *generator) flagByID(id int) *flagset.Flag {
+	for _, flag := range g.flags {
+		if flag.ID() == id {
+			return flag
+		}
+	}
+	return nil
+}
+
+// commandByPath returns the command by the given path (i.e. `app deploy undo`) or nil if it's not found
+func (g *generator) commandByPath(path string) *flagset.Flag {
+	for _, flag := range g.flags {
+		if flag.Kind() == "command" && strings.Join(g.path(flag), " ") == strings.Join(strings.Fields(path), " ") {
+			return flag
+		}
+	}
+	return nil
+}
+
+// argName returns the argument names of the given flag (i.e. `-e, --env VALUE`)
+func argName(flag *flagset.Flag) string {
+	var names []string
+	if flag.Short() != "" {
+		names = append(names, "-"+flag.Short())
+	}
+	if flag.Long() != "" {
+		names = append(names, "--"+flag.Long())
+	}
+	result := strings.Join(names, ", ")
+	if flag.Placeholder() != "" {
+		result += " " + flag.Placeholder()
+	}
+	return result
+}
+
+// code returns the given value as inline code for the tables (empty for none)
+func code(val string) string {
+	if val == "" {
+		return ""
+	}
+	return "`" + strings.Replace(val, "|", "\\|", -1) + "`"
+}
+
+// escape escapes the given value for the tables
+func escape(val string) string {
+	return strings.Replace(strings.Replace(val, "|", "\\|", -1), "\n", " ", -1)
+}
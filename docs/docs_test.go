@@ -0,0 +1,83 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package docs_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/devfacet/gocmd"
+	"github.com/devfacet/gocmd/docs"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGenerateMarkdown(t *testing.T) {
+	Convey("should generate the markdown pages", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		flags := struct {
+			Verbose bool   `short:"v" long:"verbose" description:"Display verbose output"`
+			Token   string `long:"token" placeholder:"TOKEN" env:"APP_TOKEN" default:"abc" secret:"true" description:"API token"`
+			Deploy  struct {
+				Env  string `short:"e" long:"env" placeholder:"ENV" default:"dev" required:"true" description:"Target | environment"`
+				Undo struct {
+				} `command:"undo" description:"Undo the last deployment" long-description:"Undo the last deployment of the app." deprecated:"use rollback" see-also:"app deploy, app status, kubectl rollout"`
+			} `command:"deploy" description:"Deploy the app" example:"app deploy -e prod"`
+			Status struct {
+			} `command:"status" description:"Show the status"`
+		}{}
+		app := &gocmd.App{Name: "app", Description: "A test app", Flags: &flags}
+		So(docs.GenerateMarkdown(app, dir), ShouldBeNil)
+
+		files, err := ioutil.ReadDir(dir)
+		So(err, ShouldBeNil)
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		sort.Strings(names)
+		So(names, ShouldResemble, []string{"app.md", "app_deploy.md", "app_deploy_undo.md", "app_status.md"})
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, "app.md"))
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "# app\n\nA test app\n\n"+
+			"## Synopsis\n\n```\napp [options...] COMMAND [options...]\n```\n\n"+
+			"## Options\n\n| Option | Description | Default | Env |\n| --- | --- | --- | --- |\n"+
+			"| `-v, --verbose` | Display verbose output |  |  |\n"+
+			"| `--token TOKEN` | API token | `***` | `APP_TOKEN` |\n\n"+
+			"## Commands\n\n| Command | Description |\n| --- | --- |\n"+
+			"| [deploy](app_deploy.md) | Deploy the app |\n"+
+			"| [status](app_status.md) | Show the status |\n")
+
+		b, err = ioutil.ReadFile(filepath.Join(dir, "app_deploy.md"))
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "# app deploy\n\nDeploy the app\n\n"+
+			"## Synopsis\n\n```\napp deploy [options...] COMMAND [options...]\n```\n\n"+
+			"## Options\n\n| Option | Description | Default | Env |\n| --- | --- | --- | --- |\n"+
+			"| `-e, --env ENV` | Target \\| environment (required) | `dev` |  |\n\n"+
+			"## Commands\n\n| Command | Description |\n| --- | --- |\n"+
+			"| [undo](app_deploy_undo.md) | Undo the last deployment |\n\n"+
+			"## Examples\n\n```\napp deploy -e prod\n```\n\n"+
+			"## See also\n\n- [app](app.md)\n")
+
+		b, err = ioutil.ReadFile(filepath.Join(dir, "app_deploy_undo.md"))
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "# app deploy undo\n\n> Deprecated: use rollback\n\nUndo the last deployment of the app.\n\n"+
+			"## Synopsis\n\n```\napp deploy undo\n```\n\n"+
+			"## See also\n\n- [app deploy](app_deploy.md)\n- [app status](app_status.md)\n- kubectl rollout\n")
+	})
+
+	Convey("should fail to generate the markdown pages", t, func() {
+		So(docs.GenerateMarkdown(nil, ""), ShouldBeError, "app is required")
+		So(docs.GenerateMarkdown(&gocmd.App{}, ""), ShouldBeError, "app name is required")
+		So(docs.GenerateMarkdown(&gocmd.App{Name: "app", Flags: struct{}{}}, ""), ShouldBeError, "flags must be a struct pointer")
+	})
+}
@@ -0,0 +1,55 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"context"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a copy of the given context those carries the given dry-run mode
+func WithDryRun(ctx context.Context, dryRun bool) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, dryRun)
+}
+
+// DryRun returns whether the dry-run mode is active for the given context or not (see App.DryRun).
+// Runners should skip their side effects (i.e. writing files or calling APIs) when it's true.
+func DryRun(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(dryRunKey{}).(bool)
+	return v
+}
+
+// DryRun returns whether the dry-run flag is present or not. It's for the flag handlers those
+// don't receive a context (see App.DryRun).
+func (cmd *Cmd) DryRun() bool {
+	return cmd.dryRun
+}
+
+// dryRunLogger annotates the messages of the given logger when the dry-run mode is active
+type dryRunLogger struct {
+	Logger
+	cmd *Cmd
+}
+
+// Fatalf logs the message with the dry-run annotation and exits
+func (l *dryRunLogger) Fatalf(format string, v ...interface{}) {
+	if l.cmd.dryRun {
+		format = "[dry-run] " + format
+	}
+	l.Logger.Fatalf(format, v...)
+}
+
+// Printf logs the message with the dry-run annotation
+func (l *dryRunLogger) Printf(format string, v ...interface{}) {
+	if l.cmd.dryRun {
+		format = "[dry-run] " + format
+	}
+	l.Logger.Printf(format, v...)
+}
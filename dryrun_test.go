@@ -0,0 +1,113 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/devfacet/gocmd"
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type dryRunApp struct {
+	Deploy dryRunDeploy `command:"deploy"`
+}
+
+type dryRunDeploy struct {
+	Env    string `long:"env"`
+	dryRun bool
+}
+
+func (d *dryRunDeploy) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	d.dryRun = gocmd.DryRun(ctx)
+	if d.Env == "" {
+		return errors.New("env is required")
+	}
+	return nil
+}
+
+func TestDryRun(t *testing.T) {
+	Convey("should return the dry-run mode of the context", t, func() {
+		So(gocmd.DryRun(context.Background()), ShouldBeFalse)
+		So(gocmd.DryRun(gocmd.WithDryRun(context.Background(), true)), ShouldBeTrue)
+		So(gocmd.DryRun(gocmd.WithDryRun(context.Background(), false)), ShouldBeFalse)
+	})
+}
+
+func TestApp_Run_dryRun(t *testing.T) {
+	Convey("should pass the dry-run mode to the runners", t, func() {
+		var buf bytes.Buffer
+		flags := dryRunApp{}
+		app := gocmd.App{
+			Name:    "test",
+			Flags:   &flags,
+			Logger:  log.New(&buf, "", 0),
+			Signals: []os.Signal{},
+			DryRun:  true,
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "deploy", "--dry-run", "--env=prod")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.Deploy.dryRun, ShouldBeTrue)
+		So(flags.Deploy.Env, ShouldEqual, "prod")
+		So(buf.String(), ShouldEqual, "[dry-run] no changes will be made\n")
+
+		buf.Reset()
+		os.Args = append(os.Args[:1], "--dry-run", "deploy")
+		So(app.Run(), ShouldEqual, 1)
+		So(flags.Deploy.dryRun, ShouldBeTrue)
+		So(buf.String(), ShouldEqual, "[dry-run] no changes will be made\n[dry-run] env is required\n")
+
+		buf.Reset()
+		os.Args = append(os.Args[:1], "deploy", "--env=prod")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.Deploy.dryRun, ShouldBeFalse)
+		So(buf.String(), ShouldEqual, "")
+
+		buf.Reset()
+		os.Args = append(os.Args[:1], "deploy", "--dry-run=true", "--env=prod")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.Deploy.dryRun, ShouldBeTrue)
+
+		buf.Reset()
+		os.Args = append(os.Args[:1], "deploy", "--env=prod", "--", "--dry-run")
+		So(app.Run(), ShouldEqual, 0)
+		So(flags.Deploy.dryRun, ShouldBeFalse)
+		So(buf.String(), ShouldEqual, "")
+
+		buf.Reset()
+		app.DryRun = false
+		os.Args = append(os.Args[:1], "deploy", "--dry-run", "--env=prod")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldEqual, "unknown argument: --dry-run\n")
+
+		resetArgs()
+	})
+
+	Convey("should parse the dry-run flag without any flags", t, func() {
+		var buf bytes.Buffer
+		app := gocmd.App{
+			Name:    "test",
+			Logger:  log.New(&buf, "", 0),
+			Signals: []os.Signal{},
+			DryRun:  true,
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--dry-run")
+		So(app.Run(), ShouldEqual, 0)
+		So(buf.String(), ShouldEqual, "[dry-run] no changes will be made\n")
+
+		resetArgs()
+	})
+}
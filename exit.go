@@ -0,0 +1,49 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"errors"
+)
+
+// ExitCoder is the interface that can be implemented by the errors for the custom exit codes
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// UsageError represents a command line usage error (i.e. unknown or invalid arguments)
+type UsageError struct {
+	// Err is the flag error
+	Err error
+}
+
+// Error returns the error message
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the flag error (see errors.Is and errors.As)
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for the usage errors
+func (e *UsageError) ExitCode() int {
+	return 2
+}
+
+// DefaultExitCode returns the exit code for the given error. It returns the code of the first error
+// those implements the ExitCoder interface in the chain of the wrapped errors (see errors.As),
+// 0 for nil and 1 for the rest.
+func DefaultExitCode(err error) int {
+	var ec ExitCoder
+	if err == nil {
+		return 0
+	} else if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return 1
+}
@@ -0,0 +1,48 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/devfacet/gocmd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type exitError struct {
+	code int
+}
+
+func (e exitError) Error() string {
+	return "exit error"
+}
+
+func (e exitError) ExitCode() int {
+	return e.code
+}
+
+func TestDefaultExitCode(t *testing.T) {
+	Convey("should return the exit code of the error", t, func() {
+		So(gocmd.DefaultExitCode(nil), ShouldEqual, 0)
+		So(gocmd.DefaultExitCode(errors.New("failed")), ShouldEqual, 1)
+		So(gocmd.DefaultExitCode(exitError{code: 3}), ShouldEqual, 3)
+		So(gocmd.DefaultExitCode(&gocmd.UsageError{Err: errors.New("unknown argument: --foo")}), ShouldEqual, 2)
+		So(gocmd.DefaultExitCode(fmt.Errorf("deploy failed: %w", exitError{code: 3})), ShouldEqual, 3)
+	})
+}
+
+func TestUsageError(t *testing.T) {
+	Convey("should return the message of the flag error", t, func() {
+		err := &gocmd.UsageError{Err: errors.New("unknown argument: --foo")}
+		So(err.Error(), ShouldEqual, "unknown argument: --foo")
+		So(err.ExitCode(), ShouldEqual, 2)
+
+		So(errors.Is(err, err.Err), ShouldBeTrue)
+		So(errors.Unwrap(err), ShouldEqual, err.Err)
+	})
+}
@@ -11,10 +11,12 @@ type Arg struct {
 	arg        string
 	name       string
 	value      string
+	values     []string // additional values for the multi-value arguments (see nargs tag)
 	dash       string
 	hasEq      bool
 	unnamed    bool
 	unset      bool
+	terminated bool // after the end-of-flags terminator (i.e. `app -- -f`)
 	kind       string
 	flagID     int
 	commandID  int
@@ -26,3 +28,75 @@ type Arg struct {
 	updatedBy  []string // for debug
 	err        error
 }
+
+// ID returns the id of the argument (i.e. the index in the arguments)
+func (a *Arg) ID() int {
+	return a.id
+}
+
+// Arg returns the argument as it is (i.e. `--foo=bar`)
+func (a *Arg) Arg() string {
+	return a.arg
+}
+
+// Name returns the name of the argument without the dashes (i.e. `foo` for `--foo=bar`)
+func (a *Arg) Name() string {
+	return a.name
+}
+
+// Value returns the value of the argument (i.e. `bar` for `--foo=bar` or `--foo bar`)
+func (a *Arg) Value() string {
+	return a.value
+}
+
+// Values returns the additional values of the multi-value argument (see nargs tag)
+func (a *Arg) Values() []string {
+	return a.values
+}
+
+// Dash returns the dashes of the argument (i.e. `--`, `-` or empty string for the unnamed arguments)
+func (a *Arg) Dash() string {
+	return a.dash
+}
+
+// Kind returns the kind of the argument: arg, argval (the value of the previous argument),
+// command or terminator (i.e. `--`)
+func (a *Arg) Kind() string {
+	return a.kind
+}
+
+// Unnamed returns whether the argument is an unnamed (positional) argument or not
+func (a *Arg) Unnamed() bool {
+	return a.unnamed
+}
+
+// Terminated returns whether the argument is after the end-of-flags terminator or not
+func (a *Arg) Terminated() bool {
+	return a.terminated
+}
+
+// FlagID returns the id of the flag of the argument or -1 if there is none
+func (a *Arg) FlagID() int {
+	return a.flagID
+}
+
+// CommandID returns the id of the command those the argument belongs to or -1 if there is none
+func (a *Arg) CommandID() int {
+	return a.commandID
+}
+
+// IndexFrom returns the start index of the argument in the arguments
+func (a *Arg) IndexFrom() int {
+	return a.indexFrom
+}
+
+// IndexTo returns the end index (exclusive) of the argument in the arguments
+// (i.e. it includes the value of `--foo bar`)
+func (a *Arg) IndexTo() int {
+	return a.indexTo
+}
+
+// Err returns the error of the argument
+func (a *Arg) Err() error {
+	return a.err
+}
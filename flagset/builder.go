@@ -0,0 +1,227 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Builder represents the flags those are defined at runtime without struct tags (i.e. the flags
+// those are generated from data). The definitions return typed pointers those are updated by the
+// flag set when the builder is used as the Flags option (or in the slice of the composed flags).
+// The struct of the flags is built by the definitions at the compile time so the definitions
+// after creating a flag set are ignored.
+//
+//	var b flagset.Builder
+//	name := b.String("name", "n", "Name of the user", flagset.WithDefault("x"))
+//	flagSet, err := flagset.New(flagset.Options{Flags: &b})
+type Builder struct {
+	fields []builderField
+}
+
+// builderField represents a flag definition of a builder
+type builderField struct {
+	name    string
+	tag     string
+	value   reflect.Value // typed pointer of the argument flags
+	command *Builder      // flags of the command flags
+}
+
+// FlagOption represents an option of a flag those is defined by a builder (i.e. `WithDefault("x")`)
+type FlagOption struct {
+	key   string
+	value string
+}
+
+// WithTag returns a flag option by the given struct tag key and value (i.e. `WithTag("validate", "email")`)
+func WithTag(key, value string) FlagOption {
+	return FlagOption{key: key, value: value}
+}
+
+// WithDefault returns a flag option for the default value of the flag
+func WithDefault(value string) FlagOption {
+	return WithTag("default", value)
+}
+
+// WithEnv returns a flag option for the env variable name of the flag
+func WithEnv(name string) FlagOption {
+	return WithTag("env", name)
+}
+
+// WithRequired returns a flag option those makes the flag required
+func WithRequired() FlagOption {
+	return WithTag("required", "true")
+}
+
+// Bool defines a bool argument flag and returns its value pointer
+func (b *Builder) Bool(long, short, description string, options ...FlagOption) *bool {
+	p := new(bool)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// Int defines an int argument flag and returns its value pointer
+func (b *Builder) Int(long, short, description string, options ...FlagOption) *int {
+	p := new(int)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// Int64 defines an int64 argument flag and returns its value pointer
+func (b *Builder) Int64(long, short, description string, options ...FlagOption) *int64 {
+	p := new(int64)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// Uint defines an uint argument flag and returns its value pointer
+func (b *Builder) Uint(long, short, description string, options ...FlagOption) *uint {
+	p := new(uint)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// Uint64 defines an uint64 argument flag and returns its value pointer
+func (b *Builder) Uint64(long, short, description string, options ...FlagOption) *uint64 {
+	p := new(uint64)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// Float64 defines a float64 argument flag and returns its value pointer
+func (b *Builder) Float64(long, short, description string, options ...FlagOption) *float64 {
+	p := new(float64)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// String defines a string argument flag and returns its value pointer
+func (b *Builder) String(long, short, description string, options ...FlagOption) *string {
+	p := new(string)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// StringSlice defines a []string argument flag (i.e. `--tag a --tag b`) and returns its value pointer
+func (b *Builder) StringSlice(long, short, description string, options ...FlagOption) *[]string {
+	p := new([]string)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// IntSlice defines a []int argument flag (i.e. `--port 80 --port 443`) and returns its value pointer
+func (b *Builder) IntSlice(long, short, description string, options ...FlagOption) *[]int {
+	p := new([]int)
+	b.arg(long, short, description, p, options)
+	return p
+}
+
+// Command defines a command flag and returns its builder for the command flags
+func (b *Builder) Command(name, description string, options ...FlagOption) *Builder {
+	command := &Builder{}
+	tags := append([]FlagOption{WithTag("command", name), WithTag("description", description)}, options...)
+	b.fields = append(b.fields, builderField{name: name, tag: builderTag(tags), command: command})
+	return command
+}
+
+// arg defines an argument flag by the given value pointer
+func (b *Builder) arg(long, short, description string, value interface{}, options []FlagOption) {
+	tags := append([]FlagOption{WithTag("short", short), WithTag("long", long), WithTag("description", description)}, options...)
+	name := long
+	if name == "" {
+		name = short
+	}
+	b.fields = append(b.fields, builderField{name: name, tag: builderTag(tags), value: reflect.ValueOf(value)})
+}
+
+// structType returns the struct type of the flag definitions
+func (b *Builder) structType() reflect.Type {
+	fields := make([]reflect.StructField, 0, len(b.fields))
+	used := map[string]bool{}
+	for k, v := range b.fields {
+		// Field names must be exported and unique (i.e. `dry-run` is `DryRun`)
+		name := builderFieldName(v.name)
+		for i := k; name == "" || used[name]; i++ {
+			name = fmt.Sprintf("Field%d", i)
+		}
+		used[name] = true
+
+		var typ reflect.Type
+		if v.command != nil {
+			typ = v.command.structType()
+		} else {
+			typ = v.value.Type().Elem()
+		}
+		fields = append(fields, reflect.StructField{Name: name, Type: typ, Tag: reflect.StructTag(v.tag)})
+	}
+	return reflect.StructOf(fields)
+}
+
+// sync copies the values of the given struct (see structType) to the typed pointers
+func (b *Builder) sync(value reflect.Value) {
+	for k := 0; k < value.NumField() && k < len(b.fields); k++ {
+		if b.fields[k].command != nil {
+			b.fields[k].command.sync(value.Field(k))
+		} else {
+			b.fields[k].value.Elem().Set(value.Field(k))
+		}
+	}
+}
+
+// value returns a new struct pointer of the flag definitions (see structType)
+func (b *Builder) value() interface{} {
+	return reflect.New(b.structType()).Interface()
+}
+
+// syncBuilders copies the flag values to the typed pointers of the builders (see Builder)
+func (flagSet *FlagSet) syncBuilders() {
+	values, ok := flagSet.flagsRaw.([]interface{})
+	if !ok {
+		values = []interface{}{flagSet.flagsRaw}
+	}
+	for k, b := range flagSet.builders {
+		if b != nil {
+			b.sync(reflect.ValueOf(values[k]).Elem())
+		}
+	}
+}
+
+// builderTag returns the struct tag by the given flag options (the empty values are skipped)
+func builderTag(options []FlagOption) string {
+	var tags []string
+	for _, v := range options {
+		if v.key != "" && v.value != "" {
+			tags = append(tags, v.key+":"+strconv.Quote(v.value))
+		}
+	}
+	return strings.Join(tags, " ")
+}
+
+// builderFieldName returns the exported field name by the given flag name (i.e. `DryRun` for `dry-run`)
+// or returns an empty string if it can't be converted.
+func builderFieldName(name string) string {
+	var result []rune
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		result = append(result, r)
+	}
+	if len(result) == 0 || !unicode.IsUpper(result[0]) {
+		return ""
+	}
+	return string(result)
+}
@@ -0,0 +1,106 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBuilder(t *testing.T) {
+	Convey("should parse the flags those are defined by a builder", t, func() {
+		os.Setenv("GOCMD_TEST_TIMEOUT", "30")
+		defer os.Unsetenv("GOCMD_TEST_TIMEOUT")
+
+		var b flagset.Builder
+		verbose := b.Bool("verbose", "v", "Verbose output")
+		name := b.String("name", "n", "Name of the user", flagset.WithDefault("x"))
+		timeout := b.Int("timeout", "", "Timeout in seconds", flagset.WithEnv("GOCMD_TEST_TIMEOUT"))
+		ratio := b.Float64("ratio", "", "Ratio")
+		email := b.String("email", "", "Email", flagset.WithTag("validate", "email"))
+		tags := b.StringSlice("tag", "t", "Tags")
+		deploy := b.Command("deploy", "Deploy the app")
+		env := deploy.String("env", "", "Environment", flagset.WithRequired())
+		dryRun := deploy.Bool("dry-run", "", "Dry run")
+
+		flagSet, err := flagset.New(flagset.Options{Flags: &b, Args: []string{"./app", "-v", "--ratio=0.5", "--email=a@b.com", "-t", "a", "-t", "b", "deploy", "--env=prod", "--dry-run"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeEmpty)
+		So(*verbose, ShouldBeTrue)
+		So(*name, ShouldEqual, "x")
+		So(*timeout, ShouldEqual, 30)
+		So(*ratio, ShouldEqual, 0.5)
+		So(*email, ShouldEqual, "a@b.com")
+		So(*tags, ShouldResemble, []string{"a", "b"})
+		So(*env, ShouldEqual, "prod")
+		So(*dryRun, ShouldBeTrue)
+		So(flagSet.FlagByName("dry-run").Name(), ShouldEqual, "DryRun")
+		So(flagSet.FlagByName("Deploy.DryRun").Description(), ShouldEqual, "Dry run")
+		So(flagSet.ActiveCommand().Command(), ShouldEqual, "deploy")
+
+		So(flagSet.Parse([]string{"./app", "--name=y", "--email=foo"}), ShouldResemble, flagSet.Err())
+		So(*verbose, ShouldBeFalse)
+		So(*name, ShouldEqual, "y")
+		So(*tags, ShouldBeNil)
+		So(*dryRun, ShouldBeFalse)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{"argument --email must be a valid email"})
+
+		flagSet.Reset()
+		So(*name, ShouldEqual, "")
+	})
+
+	Convey("should fail to create a flag set by a builder", t, func() {
+		var b flagset.Builder
+		flagSet, err := flagset.New(flagset.Options{Flags: &b})
+		So(err, ShouldBeError, errors.New("flags are required"))
+		So(flagSet, ShouldBeNil)
+
+		b.Bool("version", "v", "Version")
+		b.Bool("verbose", "v", "Verbose")
+		flagSet, err = flagset.New(flagset.Options{Flags: &b})
+		So(err, ShouldBeError, errors.New("short argument v in Verbose field is already defined in Version field"))
+		So(flagSet, ShouldBeNil)
+	})
+
+	Convey("should parse the builder flags those are composed with a struct", t, func() {
+		var b flagset.Builder
+		name := b.String("name", "n", "Name of the user")
+		flags := struct {
+			Verbose bool `short:"v"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: []interface{}{&flags, &b}, Args: []string{"./app", "-v", "-n", "foo"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flags.Verbose, ShouldBeTrue)
+		So(*name, ShouldEqual, "foo")
+		So(flagSet.Target().([]interface{})[0], ShouldEqual, &flags)
+		So(flagSet.Target().([]interface{})[1], ShouldNotEqual, &b)
+
+		zero, err := flagset.ZeroFlags(&b)
+		So(err, ShouldBeNil)
+		So(zero, ShouldNotEqual, &b)
+	})
+
+	Convey("should name the fields those can't be named by the flags", t, func() {
+		var b flagset.Builder
+		first := b.String("dry-run", "", "")
+		second := b.String("dry_run", "", "")
+		third := b.String("", "1", "")
+		flagSet, err := flagset.New(flagset.Options{Flags: &b, Args: []string{"./app", "--dry-run=a", "--dry_run=b", "-1=c"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(*first, ShouldEqual, "a")
+		So(*second, ShouldEqual, "b")
+		So(*third, ShouldEqual, "c")
+		So(flagSet.FlagByLong("dry_run").Name(), ShouldEqual, "Field1")
+		So(flagSet.FlagByShort("1").Name(), ShouldEqual, "Field2")
+	})
+}
@@ -0,0 +1,643 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigFile returns the path of the loaded config file (the last one when there are multiple
+// files) or empty string if there is none
+func (flagSet *FlagSet) ConfigFile() string {
+	if l := len(flagSet.configPaths); l > 0 {
+		return flagSet.configPaths[l-1]
+	}
+	return ""
+}
+
+// ConfigFiles returns the paths of the loaded config files in order
+func (flagSet *FlagSet) ConfigFiles() []string {
+	return flagSet.configPaths
+}
+
+// Config returns the effective config values those are merged from the loaded config files
+func (flagSet *FlagSet) Config() map[string]interface{} {
+	return flagSet.config
+}
+
+// ConfigSource returns the path of the config file those sets the value of the given key path
+// (i.e. `deploy.env`) or empty string if there is no value for the key
+func (flagSet *FlagSet) ConfigSource(key string) string {
+	return flagSet.configSources[key]
+}
+
+// configFileArgs returns the paths of the config files by the config-file flag, the options or
+// the search paths and whether they are given explicitly or not (i.e. the default paths those may not exist)
+func (flagSet *FlagSet) configFileArgs() ([]string, bool) {
+	var paths []string
+	for _, flag := range flagSet.flags {
+		if flag.kind != "arg" || !flag.configFile || flag.err != nil {
+			continue
+		}
+		var values []string
+		switch v := flagSet.fieldValue(flag).Interface().(type) {
+		case string:
+			if v != "" {
+				values = []string{v}
+			}
+		case []string:
+			values = v
+		}
+		if values != nil && flag.valueBy != "default" {
+			return values, true
+		}
+		paths = append(paths, values...) // default paths are searched
+	}
+	if flagSet.configFile != "" || flagSet.configFiles != nil {
+		var result []string
+		if flagSet.configFile != "" {
+			result = append(result, flagSet.configFile)
+		}
+		return append(result, flagSet.configFiles...), true
+	}
+
+	// Search the paths and return the first existing one
+	for _, path := range append(paths, flagSet.configSearch...) {
+		if _, err := os.Stat(path); err == nil {
+			return []string{path}, false
+		}
+	}
+	return nil, false
+}
+
+// loadConfig loads the config files and merges their values in order (see Options.ConfigFile and `config-file` tag)
+func (flagSet *FlagSet) loadConfig() {
+	flagSet.config, flagSet.configSources, flagSet.configPaths, flagSet.configErr = flagSet.readConfig()
+}
+
+// readConfig reads the config files and returns the merged values, their sources and the paths of the files
+func (flagSet *FlagSet) readConfig() (map[string]interface{}, map[string]string, []string, error) {
+	var config map[string]interface{}
+	var sources map[string]string
+	var loaded []string
+	paths, explicit := flagSet.configFileArgs()
+	for _, path := range paths {
+		values, err := flagSet.loadConfigFile(path)
+		if os.IsNotExist(err) && !explicit {
+			continue // default paths are optional
+		} else if err != nil {
+			return nil, nil, nil, flagSet.errorf("failed to load config file %s due to %s", path, err.Error())
+		}
+		if config == nil {
+			config = map[string]interface{}{}
+			sources = map[string]string{}
+		}
+		mergeConfig(config, values, "", path, sources)
+		loaded = append(loaded, path)
+	}
+	return config, sources, loaded, nil
+}
+
+// ReloadConfig reloads the config files and updates the flags those config values are changed
+// (i.e. for the long-running processes). The flags those are present as arguments are kept as is.
+// It returns the changed key paths (i.e. `deploy.env`) and the first error of the updated flags.
+// The previous values are kept when the config files can't be loaded.
+func (flagSet *FlagSet) ReloadConfig() ([]string, error) {
+	config, sources, paths, err := flagSet.readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the changed keys
+	prev, next := map[string]string{}, map[string]string{}
+	flattenConfig(flagSet.config, "", prev)
+	flattenConfig(config, "", next)
+	changed := map[string]bool{}
+	var keys []string
+	for k, v := range next {
+		if pv, ok := prev[k]; !ok || pv != v {
+			changed[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			changed[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	flagSet.config, flagSet.configSources, flagSet.configPaths, flagSet.configErr = config, sources, paths, nil
+
+	// Iterate over the flags and update the changed ones
+	var result error
+	for _, flag := range flagSet.flags {
+		if flag.kind != "arg" || flag.args != nil || flag.configFile {
+			continue
+		}
+		if key := flagSet.configKey(flag); key == nil || !changed[strings.Join(key, ".")] {
+			continue
+		}
+		flagSet.unsetFlag(flag.id)
+		flag.valueBy = ""
+		flag.err = nil
+		flagSet.resolveFlag(flag)
+		if flag.err != nil && result == nil {
+			result = flag.err
+		}
+	}
+	flagSet.syncBuilders()
+
+	return keys, result
+}
+
+// flattenConfig flattens the given config values into the given map by their key paths (i.e. `deploy.env`)
+func flattenConfig(config map[string]interface{}, prefix string, result map[string]string) {
+	for k, v := range config {
+		if section, ok := v.(map[string]interface{}); ok {
+			flattenConfig(section, prefix+k+".", result)
+			continue
+		}
+		result[prefix+k] = fmt.Sprint(v)
+	}
+}
+
+// loadConfigFile reads and decodes the given config file or URL by its extension: .json (or none),
+// .ini or .conf. The URLs are read by the config source (see Options.ConfigSource).
+func (flagSet *FlagSet) loadConfigFile(path string) (map[string]interface{}, error) {
+	ext := filepath.Ext(path)
+	if isConfigURL(path) {
+		if u, err := url.Parse(path); err == nil {
+			ext = filepath.Ext(u.Path)
+		}
+	}
+	var parse func([]byte) (map[string]interface{}, error)
+	switch strings.ToLower(ext) {
+	case ".json", "":
+		parse = parseJSON
+	case ".ini", ".conf":
+		parse = parseINI
+	default:
+		return nil, fmt.Errorf("unsupported config format %s", ext)
+	}
+
+	// Read the content
+	var b []byte
+	var err error
+	if isConfigURL(path) {
+		source := flagSet.configSource
+		if source == nil {
+			if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+				return nil, errors.New("unsupported location")
+			}
+			source = defaultHTTPConfigSource
+		}
+		b, err = source.ReadConfig(path)
+	} else {
+		b, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parse(b)
+}
+
+// mergeConfig merges the given config values into the destination key by key and records
+// the path of the given file as the source of each value (i.e. `deploy.env`). The keys those
+// start with an underscore are skipped as comments (i.e. `_comment`).
+func mergeConfig(dst, src map[string]interface{}, prefix, path string, sources map[string]string) {
+	for k, v := range src {
+		if strings.HasPrefix(k, "_") {
+			continue // comments (i.e. `_comment`, see WriteDefaultConfig)
+		}
+		key := prefix + k
+		if section, ok := v.(map[string]interface{}); ok {
+			d, ok := dst[k].(map[string]interface{})
+			if !ok {
+				d = map[string]interface{}{}
+				dst[k] = d
+				delete(sources, key)
+			}
+			mergeConfig(d, section, key+".", path, sources)
+			continue
+		}
+		dst[k] = v
+		sources[key] = path
+		for sk := range sources {
+			if strings.HasPrefix(sk, key+".") {
+				delete(sources, sk) // overridden section
+			}
+		}
+	}
+}
+
+// parseJSON parses the given JSON config content (numbers are kept as is for the flag types)
+func parseJSON(b []byte) (map[string]interface{}, error) {
+	var config map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// parseINI parses the given INI config content. The sections are the command paths
+// (i.e. `[deploy]`, `[deploy.rollback]`) and the keys before the first section belong to
+// the top level. The repeated keys are collected as lists (i.e. for the slice flags).
+func parseINI(b []byte) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	section := config
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue // empty line or comment
+		}
+
+		// Section
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("invalid section at line %d", n)
+			}
+			section = config
+			for _, name := range strings.Split(line[1:len(line)-1], ".") {
+				if name = strings.TrimSpace(name); name == "" {
+					return nil, fmt.Errorf("invalid section at line %d", n)
+				}
+				v, ok := section[name].(map[string]interface{})
+				if !ok && section[name] != nil {
+					return nil, fmt.Errorf("invalid section at line %d", n) // conflicts with a key
+				} else if !ok {
+					v = map[string]interface{}{}
+					section[name] = v
+				}
+				section = v
+			}
+			continue
+		}
+
+		// Key and value
+		i := strings.IndexAny(line, "=:")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid key at line %d", n)
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if l := len(value); l > 1 && (value[0] == '"' || value[0] == '\'') && value[l-1] == value[0] {
+			value = value[1 : l-1] // quoted value
+		}
+		switch v := section[key].(type) {
+		case nil:
+			section[key] = value
+		case string:
+			section[key] = []interface{}{v, value}
+		case []interface{}:
+			section[key] = append(v, value)
+		default:
+			return nil, fmt.Errorf("invalid key at line %d", n)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// configKey returns the config key path of the given flag (i.e. `deploy.env`)
+// The config tags are the paths from the top level (i.e. `server.port`), otherwise the values
+// of the command flags are nested under the command names.
+func (flagSet *FlagSet) configKey(flag *Flag) []string {
+	if flag.config != "" {
+		return strings.Split(flag.config, ".")
+	} else if flag.long == "" {
+		return nil // only long arguments have a key by default
+	}
+	key := []string{flag.long}
+	for pid := flag.parentID; pid > -1; {
+		parent := flagSet.flagByID(pid)
+		if parent == nil {
+			break
+		}
+		key = append([]string{parent.command}, key...)
+		pid = parent.parentID
+	}
+	return key
+}
+
+// configValue returns the config values of the given flag and its key
+// It returns false when the config has no value for the flag and nil values when the value is invalid.
+func (flagSet *FlagSet) configValue(flag *Flag) ([]string, string, bool) {
+	key := flagSet.configKey(flag)
+	if flagSet.config == nil || key == nil {
+		return nil, "", false
+	}
+	section := flagSet.config
+	for _, name := range key[:len(key)-1] {
+		v, ok := section[name].(map[string]interface{})
+		if !ok {
+			return nil, "", false
+		}
+		section = v
+	}
+	v, ok := section[key[len(key)-1]]
+	if !ok || v == nil {
+		return nil, "", false
+	}
+	values, _ := configStrings(v)
+	if flagSet.expandConfig {
+		for i := range values {
+			values[i] = os.ExpandEnv(values[i])
+		}
+	}
+	return values, strings.Join(key, "."), true
+}
+
+// configStrings returns the string values of the given config value
+// It returns false when the value is not a scalar or a list of scalars.
+func configStrings(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case json.Number:
+		return []string{v.String()}, true
+	case bool:
+		return []string{strconv.FormatBool(v)}, true
+	case []interface{}:
+		result := []string{}
+		for _, vv := range v {
+			s, ok := configStrings(vv)
+			if !ok || len(s) != 1 {
+				return nil, false
+			}
+			result = append(result, s[0])
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// configNode represents a section of the default config those keeps the declaration order
+type configNode struct {
+	keys   []string
+	values map[string]interface{} // *configNode or *Flag
+}
+
+// add adds the given flag by the given key path
+func (node *configNode) add(key []string, flag *Flag) bool {
+	if len(key) == 1 {
+		if _, ok := node.values[key[0]]; ok {
+			return false // already defined
+		}
+		node.keys = append(node.keys, key[0])
+		node.values[key[0]] = flag
+		return true
+	}
+	child, ok := node.values[key[0]].(*configNode)
+	if !ok {
+		if _, ok := node.values[key[0]]; ok {
+			return false // conflicts with a value
+		}
+		child = &configNode{values: map[string]interface{}{}}
+		node.keys = append(node.keys, key[0])
+		node.values[key[0]] = child
+	}
+	return child.add(key[1:], flag)
+}
+
+// EffectiveValue represents the final value of a flag and the source those sets it
+type EffectiveValue struct {
+	// Flag is the flag of the value
+	Flag *Flag
+	// Key is the config key path of the flag (i.e. `deploy.env`) or the flag name if it has no key
+	Key string
+	// Value is the final value. The values of the secret flags are redacted (see SecretPlaceholder)
+	Value string
+	// Source is the source of the value: arg, env, secrets, keyring, config, default or empty if it's not set
+	Source string
+	// Location is the env variable name, the secret file path, the keyring secret (i.e. `app/token`) or the config file path
+	// those sets the value
+	Location string
+}
+
+// Effective returns the final values of the flags and their sources in the declaration order
+// (i.e. for dumping the effective configuration). The config-file flags are skipped.
+func (flagSet *FlagSet) Effective() []EffectiveValue {
+	var result []EffectiveValue
+	for _, flag := range flagSet.flags {
+		if flag.kind != "arg" || flag.configFile {
+			continue
+		}
+		ev := EffectiveValue{Flag: flag, Key: strings.Join(flagSet.configKey(flag), "."), Source: flag.valueBy}
+		if ev.Key == "" {
+			ev.Key = flag.name
+		}
+		ev.Value = formatValue(flagSet.fieldValue(flag))
+		if flag.secret && ev.Value != "" {
+			ev.Value = SecretPlaceholder
+		}
+		switch flag.valueBy {
+		case "env":
+			ev.Location = flag.env
+		case "secrets":
+			ev.Location, _ = flagSet.secretFile(flag)
+		case "keyring":
+			ev.Location = flag.keyring
+		case "config":
+			ev.Location = flagSet.ConfigSource(ev.Key)
+		}
+		result = append(result, ev)
+	}
+	return result
+}
+
+// formatValue returns the string of the given flag field value (i.e. `a,b` for the slices and the arrays)
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// WriteDefaultConfig writes a config file those contains the default values of the flags in the
+// given format: json or ini. The INI files have the descriptions and the env variable names
+// as comments and the flags those have no default value are commented out (JSON has null values).
+// JSON has no comments so each object has a `_comment` key those maps the keys to their comments.
+// The values of the secret flags are never written.
+func (flagSet *FlagSet) WriteDefaultConfig(w io.Writer, format string) error {
+	if format != "json" && format != "ini" {
+		return fmt.Errorf("invalid config format %s", format)
+	}
+
+	// Build the config tree
+	root := &configNode{values: map[string]interface{}{}}
+	for _, flag := range flagSet.flags {
+		if flag.kind != "arg" || flag.configFile {
+			continue
+		}
+		if key := flagSet.configKey(flag); key != nil {
+			root.add(key, flag)
+		}
+	}
+
+	var b bytes.Buffer
+	if format == "json" {
+		writeJSONConfig(&b, root, "")
+		b.WriteString("\n")
+	} else {
+		writeINIConfig(&b, root, "")
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// defaultValues returns the default values of the given flag (i.e. the elements of the slices)
+func defaultValues(flag *Flag) []string {
+	if flag.secret || flag.valueDefault == "" {
+		return nil
+	}
+	if strings.HasPrefix(flag.valueType, "[]") && flag.delimiter != "" {
+		return splitDelimited(flag.valueDefault, flag.delimiter, flag.keepEmpty)
+	}
+	return []string{flag.valueDefault}
+}
+
+// writeJSONConfig writes the given config node as a JSON object by the given indentation
+func writeJSONConfig(b *bytes.Buffer, node *configNode, indent string) {
+	b.WriteString("{\n")
+
+	// Comments of the values (i.e. `"_comment": {"port": "Port number; env: APP_PORT"}`)
+	var comments []string
+	for _, key := range node.keys {
+		if flag, ok := node.values[key].(*Flag); ok {
+			if comment := configComment(flag); comment != "" {
+				name, _ := json.Marshal(key)
+				value, _ := json.Marshal(comment)
+				comments = append(comments, fmt.Sprintf("%s    %s: %s", indent, name, value))
+			}
+		}
+	}
+	if comments != nil {
+		fmt.Fprintf(b, "%s  \"_comment\": {\n%s\n%s  },\n", indent, strings.Join(comments, ",\n"), indent)
+	}
+
+	for k, key := range node.keys {
+		name, _ := json.Marshal(key)
+		fmt.Fprintf(b, "%s  %s: ", indent, name)
+		switch v := node.values[key].(type) {
+		case *configNode:
+			writeJSONConfig(b, v, indent+"  ")
+		case *Flag:
+			values := defaultValues(v)
+			valueType := strings.TrimPrefix(v.valueType, "[]")
+			var items []string
+			for _, vv := range values {
+				items = append(items, jsonConfigValue(vv, valueType))
+			}
+			if strings.HasPrefix(v.valueType, "[]") {
+				b.WriteString("[" + strings.Join(items, ", ") + "]")
+			} else if items != nil {
+				b.WriteString(items[0])
+			} else {
+				b.WriteString("null")
+			}
+		}
+		if k < len(node.keys)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + "}")
+}
+
+// configComment returns the comment of the given flag by its description and env variable name
+func configComment(flag *Flag) string {
+	var result []string
+	if flag.description != "" {
+		result = append(result, flag.description)
+	}
+	if flag.env != "" {
+		result = append(result, "env: "+flag.env)
+	}
+	return strings.Join(result, "; ")
+}
+
+// jsonConfigValue returns the JSON value of the given default value by the given value type
+// The numbers those the value type can't parse or JSON can't represent (i.e. `Inf` or `1e3` for
+// the integers) are quoted as they are.
+func jsonConfigValue(value, valueType string) string {
+	var err error
+	switch valueType {
+	case "bool":
+		if v, err := parseBool(value); err == nil {
+			return strconv.FormatBool(v)
+		}
+		err = strconv.ErrSyntax
+	case "float64":
+		_, err = strconv.ParseFloat(value, 64)
+	case "int", "int64":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "uint", "uint64":
+		_, err = strconv.ParseUint(value, 10, 64)
+	default:
+		err = strconv.ErrSyntax
+	}
+	if err == nil && json.Valid([]byte(value)) {
+		return value
+	}
+	v, _ := json.Marshal(value)
+	return string(v)
+}
+
+// writeINIConfig writes the values of the given config node and its sections by the given section name
+func writeINIConfig(b *bytes.Buffer, node *configNode, section string) {
+	var sections []string
+	for _, key := range node.keys {
+		flag, ok := node.values[key].(*Flag)
+		if !ok {
+			sections = append(sections, key)
+			continue
+		}
+		if flag.description != "" {
+			fmt.Fprintf(b, "; %s\n", flag.description)
+		}
+		if flag.env != "" {
+			fmt.Fprintf(b, "; env: %s\n", flag.env)
+		}
+		values := defaultValues(flag)
+		if values == nil {
+			fmt.Fprintf(b, "; %s =\n", key)
+		}
+		for _, v := range values {
+			fmt.Fprintf(b, "%s = %s\n", key, v)
+		}
+		b.WriteString("\n")
+	}
+	for _, key := range sections {
+		name := key
+		if section != "" {
+			name = section + "." + key
+		}
+		fmt.Fprintf(b, "[%s]\n\n", name)
+		writeINIConfig(b, node.values[key].(*configNode), name)
+	}
+}
@@ -0,0 +1,572 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFlagSet_ConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(config, []byte(`{
+  "verbose": true,
+  "log-level": "warn",
+  "port": 8080,
+  "tags": ["a", "b"],
+  "region": "eu-west-1",
+  "deploy": {"env": "prod", "timeout": 1.5}
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("should load the config values by the long names and the config tags", t, func() {
+		os.Setenv("GOCMD_TEST_REGION", "us-west-2")
+		defer os.Unsetenv("GOCMD_TEST_REGION")
+
+		flags := struct {
+			Verbose  bool     `short:"v" long:"verbose"`
+			LogLevel string   `long:"level" config:"log-level" default:"info"`
+			Port     int      `long:"port" default:"80"`
+			Tags     []string `long:"tag" config:"tags"`
+			Region   string   `long:"region" env:"GOCMD_TEST_REGION"`
+			Missing  string   `long:"missing" default:"foo"`
+			Deploy   struct {
+				Env     string  `long:"env" required:"true"`
+				Timeout float64 `long:"timeout"`
+			} `command:"deploy"`
+		}{}
+		args := []string{"./app", "--port", "9090", "deploy"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, ConfigFile: config})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ConfigFile(), ShouldEqual, config)
+		So(flags.Verbose, ShouldBeTrue)
+		So(flags.LogLevel, ShouldEqual, "warn")
+		So(flags.Port, ShouldEqual, 9090)
+		So(flags.Tags, ShouldResemble, []string{"a", "b"})
+		So(flags.Region, ShouldEqual, "us-west-2")
+		So(flags.Missing, ShouldEqual, "foo")
+		So(flags.Deploy.Env, ShouldEqual, "prod")
+		So(flags.Deploy.Timeout, ShouldEqual, 1.5)
+		So(flagSet.FlagByName("LogLevel").ValueBy(), ShouldEqual, "config")
+		So(flagSet.FlagByName("Port").ValueBy(), ShouldEqual, "arg")
+		So(flagSet.FlagByName("Region").ValueBy(), ShouldEqual, "env")
+		So(flagSet.FlagByName("Missing").ValueBy(), ShouldEqual, "default")
+	})
+
+	Convey("should load the config values by the config key paths", t, func() {
+		nested := filepath.Join(dir, "nested.json")
+		So(ioutil.WriteFile(nested, []byte(`{"server": {"http": {"port": 8443}}, "database": {"url": "postgres://db"}}`), 0644), ShouldBeNil)
+
+		flags := struct {
+			Port   int `long:"port" config:"server.http.port"`
+			Deploy struct {
+				Database string `long:"db" config:"database.url"`
+			} `command:"deploy"`
+		}{}
+		args := []string{"./app", "deploy"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, ConfigFile: nested})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Port, ShouldEqual, 8443)
+		So(flags.Deploy.Database, ShouldEqual, "postgres://db")
+	})
+
+	Convey("should expand the env variables in the config values", t, func() {
+		os.Setenv("GOCMD_TEST_DB_HOST", "db.internal")
+		os.Setenv("GOCMD_TEST_DB_PORT", "5432")
+		defer os.Unsetenv("GOCMD_TEST_DB_HOST")
+		defer os.Unsetenv("GOCMD_TEST_DB_PORT")
+		expand := filepath.Join(dir, "expand.json")
+		So(ioutil.WriteFile(expand, []byte(`{"db": "postgres://${GOCMD_TEST_DB_HOST}/app", "port": "${GOCMD_TEST_DB_PORT}", "hosts": ["$GOCMD_TEST_DB_HOST", "localhost"]}`), 0644), ShouldBeNil)
+
+		flags := struct {
+			Database string   `long:"db"`
+			Port     int      `long:"port"`
+			Hosts    []string `long:"hosts"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigFile: expand, ExpandConfig: true})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Database, ShouldEqual, "postgres://db.internal/app")
+		So(flags.Port, ShouldEqual, 5432)
+		So(flags.Hosts, ShouldResemble, []string{"db.internal", "localhost"})
+
+		flags.Database = ""
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "--port=1"}, ConfigFile: expand})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Database, ShouldEqual, "postgres://${GOCMD_TEST_DB_HOST}/app")
+	})
+
+	Convey("should prefer the config values over the env variables by the precedence", t, func() {
+		os.Setenv("GOCMD_TEST_REGION", "us-west-2")
+		defer os.Unsetenv("GOCMD_TEST_REGION")
+
+		flags := struct {
+			Region string `long:"region" env:"GOCMD_TEST_REGION"`
+		}{}
+		precedence := []string{"arg", "config", "env", "default"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigFile: config, Precedence: precedence})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Region, ShouldEqual, "eu-west-1")
+		So(flagSet.FlagByName("Region").ValueBy(), ShouldEqual, "config")
+	})
+
+	Convey("should load the config file by the config-file flag", t, func() {
+		flags := struct {
+			Config  string `long:"config" config-file:"true" default:"missing.json"`
+			Verbose bool   `long:"verbose"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ConfigFile(), ShouldEqual, "")
+		So(flags.Verbose, ShouldBeFalse)
+
+		So(flagSet.Parse([]string{"./app", "--config", config}), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ConfigFile(), ShouldEqual, config)
+		So(flags.Verbose, ShouldBeTrue)
+
+		So(flagSet.Parse([]string{"./app", "--config", filepath.Join(dir, "missing.json")}), ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldHaveLength, 1)
+		So(flagSet.Errors()[0].Error(), ShouldStartWith, "failed to load config file "+filepath.Join(dir, "missing.json")+" due to")
+		So(flags.Verbose, ShouldBeFalse)
+	})
+
+	Convey("should load the first existing config file by the search paths", t, func() {
+		flags := struct {
+			Verbose bool `long:"verbose"`
+		}{}
+		paths := []string{filepath.Join(dir, "missing.json"), config, filepath.Join(dir, "invalid.json")}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigPaths: paths})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ConfigFile(), ShouldEqual, config)
+		So(flags.Verbose, ShouldBeTrue)
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigPaths: paths[:1]})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ConfigFile(), ShouldEqual, "")
+	})
+
+	Convey("should merge the config files in order", t, func() {
+		base := filepath.Join(dir, "base.json")
+		So(ioutil.WriteFile(base, []byte(`{"verbose": true, "port": 80, "deploy": {"env": "dev", "region": "us"}}`), 0644), ShouldBeNil)
+		local := filepath.Join(dir, "local.ini")
+		So(ioutil.WriteFile(local, []byte("port = 8080\n[deploy]\nenv = prod\n"), 0644), ShouldBeNil)
+
+		flags := struct {
+			Config  []string `long:"config" config-file:"true"`
+			Verbose bool     `long:"verbose"`
+			Port    int      `long:"port"`
+			Deploy  struct {
+				Env    string `long:"env"`
+				Region string `long:"region"`
+			} `command:"deploy"`
+		}{}
+		args := []string{"./app", "deploy"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, ConfigFile: base, ConfigFiles: []string{local}})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ConfigFiles(), ShouldResemble, []string{base, local})
+		So(flagSet.ConfigFile(), ShouldEqual, local)
+		So(flags.Verbose, ShouldBeTrue)
+		So(flags.Port, ShouldEqual, 8080)
+		So(flags.Deploy.Env, ShouldEqual, "prod")
+		So(flags.Deploy.Region, ShouldEqual, "us")
+		So(flagSet.Config()["port"], ShouldEqual, "8080")
+		So(flagSet.ConfigSource("verbose"), ShouldEqual, base)
+		So(flagSet.ConfigSource("port"), ShouldEqual, local)
+		So(flagSet.ConfigSource("deploy.env"), ShouldEqual, local)
+		So(flagSet.ConfigSource("deploy.region"), ShouldEqual, base)
+		So(flagSet.ConfigSource("missing"), ShouldEqual, "")
+
+		So(flagSet.Parse([]string{"./app", "--config", local, "--config", base, "deploy"}), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ConfigFiles(), ShouldResemble, []string{local, base})
+		So(flags.Port, ShouldEqual, 80)
+		So(flags.Deploy.Env, ShouldEqual, "dev")
+		So(flagSet.ConfigSource("port"), ShouldEqual, base)
+	})
+
+	Convey("should reload the config files and update the changed values", t, func() {
+		reload := filepath.Join(dir, "reload.json")
+		So(ioutil.WriteFile(reload, []byte(`{"port": 80, "host": "localhost", "deploy": {"env": "dev"}}`), 0644), ShouldBeNil)
+
+		flags := struct {
+			Port   int    `long:"port"`
+			Host   string `long:"host"`
+			Debug  bool   `long:"debug" default:"false"`
+			Deploy struct {
+				Env string `long:"env" default:"test"`
+			} `command:"deploy"`
+		}{}
+		args := []string{"./app", "--host=example.com"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, ConfigFile: reload})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Port, ShouldEqual, 80)
+		So(flags.Deploy.Env, ShouldEqual, "dev")
+
+		So(ioutil.WriteFile(reload, []byte(`{"port": 8080, "host": "127.0.0.1", "debug": true}`), 0644), ShouldBeNil)
+		keys, err := flagSet.ReloadConfig()
+		So(err, ShouldBeNil)
+		So(keys, ShouldResemble, []string{"debug", "deploy.env", "host", "port"})
+		So(flags.Port, ShouldEqual, 8080)
+		So(flags.Host, ShouldEqual, "example.com")
+		So(flags.Debug, ShouldBeTrue)
+		So(flags.Deploy.Env, ShouldEqual, "test")
+		So(flagSet.FlagByName("Deploy.Env").ValueBy(), ShouldEqual, "default")
+
+		keys, err = flagSet.ReloadConfig()
+		So(err, ShouldBeNil)
+		So(keys, ShouldBeNil)
+
+		So(ioutil.WriteFile(reload, []byte(`{"port": "abc"}`), 0644), ShouldBeNil)
+		keys, err = flagSet.ReloadConfig()
+		So(err, ShouldBeError, errors.New("config key port in "+reload+" failed to parse 'abc' as int"))
+		So(keys, ShouldResemble, []string{"debug", "host", "port"})
+		So(flags.Debug, ShouldBeFalse)
+
+		So(ioutil.WriteFile(reload, []byte(`{"port": `), 0644), ShouldBeNil)
+		keys, err = flagSet.ReloadConfig()
+		So(err, ShouldBeError, errors.New("failed to load config file "+reload+" due to unexpected EOF"))
+		So(keys, ShouldBeNil)
+		So(flagSet.Config(), ShouldResemble, map[string]interface{}{"port": "abc"})
+	})
+
+	Convey("should fail to load the invalid config values", t, func() {
+		flags01 := struct {
+			Port int `long:"port"`
+		}{}
+		invalid := filepath.Join(dir, "invalid.json")
+		So(ioutil.WriteFile(invalid, []byte(`{"port": "abc"}`), 0644), ShouldBeNil)
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: []string{"./app"}, ConfigFile: invalid})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("config key port in " + invalid + " failed to parse 'abc' as int")})
+
+		So(ioutil.WriteFile(invalid, []byte(`{"port": [1, 2]}`), 0644), ShouldBeNil)
+		So(flagSet.Parse([]string{"./app"}), ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("config key port in " + invalid + " has an invalid value")})
+
+		flags02 := struct {
+			Deploy struct {
+				Env string `long:"env" validate:"nonempty"`
+			} `command:"deploy"`
+		}{}
+		So(ioutil.WriteFile(invalid, []byte(`{"deploy": {"env": ""}}`), 0644), ShouldBeNil)
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: []string{"./app", "deploy"}, ConfigFile: invalid})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("config key deploy.env in " + invalid + " must not be empty")})
+
+		flags03 := struct {
+			Port  int      `long:"port" validate:"port"`
+			Email string   `long:"email" config:"admin.email" validate:"email"`
+			Hosts []string `long:"host" validate:"ip"`
+		}{}
+		base := filepath.Join(dir, "base.json")
+		So(ioutil.WriteFile(base, []byte(`{"port": 8080, "admin": {"email": "foo"}, "host": ["127.0.0.1"]}`), 0644), ShouldBeNil)
+		So(ioutil.WriteFile(invalid, []byte(`{"port": 70000, "host": ["127.0.0.1", "localhost"]}`), 0644), ShouldBeNil)
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: []string{"./app"}, ConfigFiles: []string{base, invalid}})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{
+			errors.New("config key port in " + invalid + " must be a valid port number"),
+			errors.New("config key admin.email in " + base + " must be a valid email"),
+			errors.New("config key host in " + invalid + " must be a valid ip address"),
+		})
+		So(flagSet.Parse([]string{"./app", "--port=80", "--email=admin@example.com", "--host=::1"}), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+
+		So(ioutil.WriteFile(invalid, []byte(`{"deploy": `), 0644), ShouldBeNil)
+		So(flagSet.Parse([]string{"./app"}), ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("failed to load config file " + invalid + " due to unexpected EOF")})
+	})
+}
+
+func TestFlagSet_ConfigFile_ini(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	Convey("should load the config values by the INI sections", t, func() {
+		config := filepath.Join(dir, "app.conf")
+		So(ioutil.WriteFile(config, []byte(`; legacy config
+verbose = true
+tag = a
+tag = b
+
+[deploy]
+env = "prod"
+
+# nested command
+[deploy.rollback]
+steps: 2
+`), 0644), ShouldBeNil)
+
+		flags := struct {
+			Verbose bool     `long:"verbose"`
+			Tags    []string `long:"tag"`
+			Deploy  struct {
+				Env      string `long:"env"`
+				Rollback struct {
+					Steps int `long:"steps"`
+				} `command:"rollback"`
+			} `command:"deploy"`
+		}{}
+		args := []string{"./app", "deploy", "rollback"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, ConfigFile: config})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Verbose, ShouldBeTrue)
+		So(flags.Tags, ShouldResemble, []string{"a", "b"})
+		So(flags.Deploy.Env, ShouldEqual, "prod")
+		So(flags.Deploy.Rollback.Steps, ShouldEqual, 2)
+		So(flagSet.FlagByName("Deploy.Rollback.Steps").ValueBy(), ShouldEqual, "config")
+	})
+
+	Convey("should fail to load the invalid INI files", t, func() {
+		flags := struct {
+			Verbose bool `long:"verbose"`
+		}{}
+		config := filepath.Join(dir, "app.ini")
+		So(ioutil.WriteFile(config, []byte("verbose = true\n[deploy\n"), 0644), ShouldBeNil)
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigFile: config})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("failed to load config file " + config + " due to invalid section at line 2")})
+
+		So(ioutil.WriteFile(config, []byte("verbose\n"), 0644), ShouldBeNil)
+		So(flagSet.Parse([]string{"./app"}), ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("failed to load config file " + config + " due to invalid key at line 1")})
+
+		So(ioutil.WriteFile(config, []byte("verbose = true\n[verbose]\n"), 0644), ShouldBeNil)
+		So(flagSet.Parse([]string{"./app"}), ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("failed to load config file " + config + " due to invalid section at line 2")})
+	})
+
+	Convey("should fail to load the config files those have an unsupported format", t, func() {
+		flags := struct {
+			Verbose bool `long:"verbose"`
+		}{}
+		config := filepath.Join(dir, "app.yaml")
+		So(ioutil.WriteFile(config, []byte("verbose: true\n"), 0644), ShouldBeNil)
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigFile: config})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("failed to load config file " + config + " due to unsupported config format .yaml")})
+	})
+}
+
+func TestFlagSet_Effective(t *testing.T) {
+	Convey("should return the effective values and their sources", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		config := filepath.Join(dir, "config.json")
+		So(ioutil.WriteFile(config, []byte(`{"deploy": {"env": "prod"}, "token": "s3cr3t"}`), 0644), ShouldBeNil)
+		os.Setenv("GOCMD_TEST_REGION", "eu-west-1")
+		defer os.Unsetenv("GOCMD_TEST_REGION")
+
+		flags := struct {
+			Port   int      `long:"port" default:"80"`
+			Region string   `long:"region" env:"GOCMD_TEST_REGION"`
+			Tags   []string `long:"tag"`
+			Token  string   `long:"token" secret:"true"`
+			Debug  bool     `short:"d"`
+			Deploy struct {
+				Env string `long:"env"`
+			} `command:"deploy"`
+		}{}
+		args := []string{"./app", "--tag=a", "--tag=b", "deploy"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, ConfigFile: config})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		result := flagSet.Effective()
+		So(result, ShouldHaveLength, 6)
+		So(result[0].Flag, ShouldEqual, flagSet.FlagByName("Port"))
+		for i := range result {
+			result[i].Flag = nil
+		}
+		So(result, ShouldResemble, []flagset.EffectiveValue{
+			{Key: "port", Value: "80", Source: "default"},
+			{Key: "region", Value: "eu-west-1", Source: "env", Location: "GOCMD_TEST_REGION"},
+			{Key: "tag", Value: "a,b", Source: "arg"},
+			{Key: "token", Value: flagset.SecretPlaceholder, Source: "config", Location: config},
+			{Key: "Debug", Value: "false"},
+			{Key: "deploy.env", Value: "prod", Source: "config", Location: config},
+		})
+	})
+}
+
+func TestFlagSet_WriteDefaultConfig(t *testing.T) {
+	flags := struct {
+		Config  string   `long:"config" config-file:"true"`
+		Verbose bool     `short:"v" long:"verbose" default:"false" description:"Display verbose output"`
+		Port    int      `long:"port" default:"8080" env:"APP_PORT" config:"server.port"`
+		Tags    []string `long:"tag" default:"a,b" delimiter:","`
+		Token   string   `long:"token" default:"abc" secret:"true"`
+		Short   bool     `short:"s"`
+		Deploy  struct {
+			Env string `long:"env" description:"Target environment"`
+		} `command:"deploy"`
+	}{}
+
+	Convey("should write the default config", t, func() {
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+
+		var buf bytes.Buffer
+		So(flagSet.WriteDefaultConfig(&buf, "json"), ShouldBeNil)
+		So(buf.String(), ShouldEqual, `{
+  "_comment": {
+    "verbose": "Display verbose output"
+  },
+  "verbose": false,
+  "server": {
+    "_comment": {
+      "port": "env: APP_PORT"
+    },
+    "port": 8080
+  },
+  "tag": ["a", "b"],
+  "token": null,
+  "deploy": {
+    "_comment": {
+      "env": "Target environment"
+    },
+    "env": null
+  }
+}
+`)
+
+		buf.Reset()
+		So(flagSet.WriteDefaultConfig(&buf, "ini"), ShouldBeNil)
+		So(buf.String(), ShouldEqual, `; Display verbose output
+verbose = false
+
+tag = a
+tag = b
+
+; token =
+
+[server]
+
+; env: APP_PORT
+port = 8080
+
+[deploy]
+
+; Target environment
+; env =
+
+`)
+	})
+
+	Convey("should load the default config", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		for _, format := range []string{"json", "ini"} {
+			flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+			So(err, ShouldBeNil)
+			var buf bytes.Buffer
+			So(flagSet.WriteDefaultConfig(&buf, format), ShouldBeNil)
+			path := filepath.Join(dir, "config."+format)
+			So(ioutil.WriteFile(path, buf.Bytes(), 0644), ShouldBeNil)
+
+			So(flagSet.Parse([]string{"./app", "--config", path}), ShouldBeNil)
+			So(flagSet.Errors(), ShouldBeNil)
+			So(flags.Port, ShouldEqual, 8080)
+			So(flags.Tags, ShouldResemble, []string{"a", "b"})
+			So(flagSet.FlagByName("Port").ValueBy(), ShouldEqual, "config")
+			So(flagSet.FlagByName("Token").ValueBy(), ShouldEqual, "default")
+		}
+	})
+
+	Convey("should write the numbers those JSON can't represent as strings", t, func() {
+		numbers := struct {
+			Ratio float64 `long:"ratio" default:"Inf"`
+			Scale float64 `long:"scale" default:"1.5e3"`
+			Count int     `long:"count" default:"1e3"`
+			Size  uint    `long:"size" default:"007"`
+			Limit int64   `long:"limit" default:"-5"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &numbers, Args: []string{"./app"}, LenientNumbers: true})
+		So(err, ShouldBeNil)
+
+		var buf bytes.Buffer
+		So(flagSet.WriteDefaultConfig(&buf, "json"), ShouldBeNil)
+		So(json.Valid(buf.Bytes()), ShouldBeTrue)
+		So(buf.String(), ShouldEqual, `{
+  "ratio": "Inf",
+  "scale": 1.5e3,
+  "count": "1e3",
+  "size": "007",
+  "limit": -5
+}
+`)
+
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "config.json")
+		So(ioutil.WriteFile(path, buf.Bytes(), 0644), ShouldBeNil)
+		flagSet, err = flagset.New(flagset.Options{Flags: &numbers, Args: []string{"./app"}, LenientNumbers: true, ConfigFile: path})
+		So(err, ShouldBeNil)
+		So(flagSet.FlagByName("Count").ValueBy(), ShouldEqual, "config")
+		So(numbers.Count, ShouldEqual, 1000)
+		So(numbers.Size, ShouldEqual, 7)
+		So(math.IsInf(numbers.Ratio, 1), ShouldBeTrue)
+	})
+
+	Convey("should skip the comments of the config files", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "config.json")
+		So(ioutil.WriteFile(path, []byte(`{"_comment": {"port": "Port"}, "port": 80}`), 0644), ShouldBeNil)
+
+		flags := struct {
+			Port int `long:"port"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigFile: path})
+		So(err, ShouldBeNil)
+		So(flags.Port, ShouldEqual, 80)
+
+		So(ioutil.WriteFile(path, []byte(`{"_comment": {"port": "Port number"}, "port": 80}`), 0644), ShouldBeNil)
+		keys, err := flagSet.ReloadConfig()
+		So(err, ShouldBeNil)
+		So(keys, ShouldBeNil)
+	})
+
+	Convey("should fail to write the default config", t, func() {
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet.WriteDefaultConfig(ioutil.Discard, "yaml"), ShouldBeError, errors.New("invalid config format yaml"))
+	})
+}
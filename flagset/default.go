@@ -0,0 +1,97 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	defaultFuncsMu sync.RWMutex
+	defaultFuncs   = map[string]DefaultFunc{}
+)
+
+// DefaultFunc represents a function that returns a default flag value
+// It can be used by the default-from tag (i.e. `default-from:"func:Name"`)
+type DefaultFunc func() (string, error)
+
+// RegisterDefaultFunc registers a default value function by the given name
+func RegisterDefaultFunc(name string, fn DefaultFunc) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("invalid default function name")
+	} else if fn == nil {
+		return fmt.Errorf("invalid default function for %s", name)
+	}
+	defaultFuncsMu.Lock()
+	defaultFuncs[name] = fn
+	defaultFuncsMu.Unlock()
+	return nil
+}
+
+// defaultFunc returns the default value function by the given name
+func defaultFunc(name string) (DefaultFunc, bool) {
+	defaultFuncsMu.RLock()
+	defer defaultFuncsMu.RUnlock()
+	fn, ok := defaultFuncs[name]
+	return fn, ok
+}
+
+// parseDefaultFrom parses the given default-from tag value and returns the source and the name
+// (i.e. env and HOME for `env:HOME`)
+func parseDefaultFrom(value string) (string, string, error) {
+	s := strings.SplitN(value, ":", 2)
+	if len(s) != 2 || strings.TrimSpace(s[1]) == "" {
+		return "", "", fmt.Errorf("invalid default-from value %s", value)
+	}
+	source, name := strings.TrimSpace(s[0]), strings.TrimSpace(s[1])
+	switch source {
+	case "env", "file":
+	case "func":
+		if _, ok := defaultFunc(name); !ok {
+			return "", "", fmt.Errorf("unknown default function %s", name)
+		}
+	default:
+		return "", "", fmt.Errorf("invalid default-from source %s", source)
+	}
+	return source, name, nil
+}
+
+// resolveDefaultFrom returns the default value by the given default-from tag value
+// It returns false when the value doesn't exist (i.e. missing env variable or file)
+func resolveDefaultFrom(value string) (string, bool, error) {
+	source, name, err := parseDefaultFrom(value)
+	if err != nil {
+		return "", false, err
+	}
+	switch source {
+	case "env":
+		v, ok := os.LookupEnv(name)
+		return v, ok && v != "", nil
+	case "file":
+		b, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) {
+			return "", false, nil
+		} else if err != nil {
+			return "", false, fmt.Errorf("failed to read default value due to %s", err.Error())
+		}
+		v := strings.TrimSpace(string(b))
+		return v, v != "", nil
+	case "func":
+		fn, _ := defaultFunc(name)
+		v, err := fn()
+		if err != nil {
+			return "", false, fmt.Errorf("failed to get default value due to %s", err.Error())
+		}
+		return v, v != "", nil
+	}
+	return "", false, nil
+}
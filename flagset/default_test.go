@@ -0,0 +1,142 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegisterDefaultFunc(t *testing.T) {
+	Convey("should fail to register a default function", t, func() {
+		err := flagset.RegisterDefaultFunc("", func() (string, error) { return "", nil })
+		So(err, ShouldBeError, errors.New("invalid default function name"))
+
+		err = flagset.RegisterDefaultFunc("test", nil)
+		So(err, ShouldBeError, errors.New("invalid default function for test"))
+	})
+
+	Convey("should register a default function", t, func() {
+		err := flagset.RegisterDefaultFunc("DefaultRegion", func() (string, error) { return "us-east-1", nil })
+		So(err, ShouldBeNil)
+		err = flagset.RegisterDefaultFunc("DefaultFailure", func() (string, error) { return "", errors.New("no region") })
+		So(err, ShouldBeNil)
+
+		flags01 := struct {
+			Region string `long:"region" default-from:"func:DefaultRegion"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Region, ShouldEqual, "us-east-1")
+		So(flagSet.FlagByName("Region").ValueBy(), ShouldEqual, "default")
+
+		flags02 := struct {
+			Region string `long:"region" default-from:"func:DefaultFailure"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: []string{"./app"}})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("failed to get default value due to no region")})
+		So(flags02.Region, ShouldEqual, "")
+	})
+
+	Convey("should register the default functions while parsing", t, func() {
+		fn := func() (string, error) { return "eu-west-1", nil }
+		err := flagset.RegisterDefaultFunc("DefaultConcurrent", fn)
+		So(err, ShouldBeNil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				flagset.RegisterDefaultFunc("DefaultConcurrent", fn)
+			}()
+			go func() {
+				defer wg.Done()
+				flagset.New(flagset.Options{Flags: &struct {
+					Region string `long:"region" default-from:"func:DefaultConcurrent"`
+				}{}, Args: []string{"./app"}})
+			}()
+		}
+		wg.Wait()
+
+		flags := struct {
+			Region string `long:"region" default-from:"func:DefaultConcurrent"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flags.Region, ShouldEqual, "eu-west-1")
+	})
+}
+
+func TestDefaultFrom(t *testing.T) {
+	Convey("should fail to create a flag set with invalid default sources", t, func() {
+		flags01 := struct {
+			Region string `long:"region" default-from:"env"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01})
+		So(err, ShouldBeError, errors.New("invalid default-from value env in Region field"))
+		So(flagSet, ShouldBeNil)
+
+		flags02 := struct {
+			Region string `long:"region" default-from:"http:example.com"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02})
+		So(err, ShouldBeError, errors.New("invalid default-from source http in Region field"))
+		So(flagSet, ShouldBeNil)
+
+		flags03 := struct {
+			Region string `long:"region" default-from:"func:Missing"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03})
+		So(err, ShouldBeError, errors.New("unknown default function Missing in Region field"))
+		So(flagSet, ShouldBeNil)
+	})
+
+	Convey("should return the default values by the given sources", t, func() {
+		os.Setenv("GOCMD_TEST_REGION", "eu-west-1")
+		defer os.Unsetenv("GOCMD_TEST_REGION")
+		wd, err := os.Getwd()
+		So(err, ShouldBeNil)
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		So(ioutil.WriteFile(filepath.Join(dir, "region"), []byte("ap-south-1\n"), 0644), ShouldBeNil)
+		So(os.Chdir(dir), ShouldBeNil)
+		defer os.Chdir(wd)
+
+		flags := struct {
+			Env      string `long:"env" default-from:"env:GOCMD_TEST_REGION" default:"foo"`
+			EnvMiss  string `long:"env-miss" default-from:"env:GOCMD_TEST_MISSING" default:"foo"`
+			File     string `long:"file" default-from:"file:region"`
+			FileMiss string `long:"file-miss" default-from:"file:missing" default:"bar"`
+			Arg      string `long:"arg" default-from:"env:GOCMD_TEST_REGION"`
+		}{}
+		args := []string{"./app", "--arg=us-west-2"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Env, ShouldEqual, "eu-west-1")
+		So(flags.EnvMiss, ShouldEqual, "foo")
+		So(flags.File, ShouldEqual, "ap-south-1")
+		So(flags.FileMiss, ShouldEqual, "bar")
+		So(flags.Arg, ShouldEqual, "us-west-2")
+		So(flagSet.FlagByName("Env").ValueBy(), ShouldEqual, "default")
+		So(flagSet.FlagByName("Arg").ValueBy(), ShouldEqual, "arg")
+	})
+}
@@ -0,0 +1,132 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrRequired is the sentinel error of the required arguments and commands those are not present
+	// (i.e. `errors.Is(err, flagset.ErrRequired)`, see RequiredError)
+	ErrRequired = errors.New("required")
+	// ErrParse is the sentinel error of the values those can't be parsed by the flag types (see ParseError)
+	ErrParse = errors.New("parse error")
+	// ErrUnknownFlag is the sentinel error of the unknown arguments (see UnknownFlagError)
+	ErrUnknownFlag = errors.New("unknown flag")
+)
+
+// MultiError represents the errors those are collected together (i.e. the flag definition errors)
+type MultiError []error
+
+// Error returns the error messages line by line
+func (e MultiError) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors (see errors.Is and errors.As)
+func (e MultiError) Unwrap() []error {
+	return e
+}
+
+// RequiredError represents an error for a required argument or command those is not present
+type RequiredError struct {
+	// Flag is the required flag
+	Flag *Flag
+	// Command is the command those requires the argument or empty string for the top level
+	Command string
+	msg     string
+}
+
+// Error returns the error message
+func (e *RequiredError) Error() string {
+	return e.msg
+}
+
+// Is returns whether the given target is ErrRequired or not (see errors.Is)
+func (e *RequiredError) Is(target error) bool {
+	return target == ErrRequired
+}
+
+// ParseError represents an error for a value those can't be parsed by the flag type
+type ParseError struct {
+	// Flag is the flag of the value
+	Flag *Flag
+	// Value is the value as it is. Secret values are redacted (see SecretPlaceholder)
+	Value string
+	// Type is the type those the value is parsed as (i.e. `int` for `[]int` flags)
+	Type string
+	msg  string
+}
+
+// Error returns the error message
+func (e *ParseError) Error() string {
+	return e.msg
+}
+
+// Is returns whether the given target is ErrParse or not (see errors.Is)
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
+
+// UnknownFlagError represents an error for an unknown argument
+type UnknownFlagError struct {
+	// Name is the argument name with the dashes (i.e. `--foo`)
+	Name string
+	// Suggestion is the closest argument or command name or empty string if there is none
+	Suggestion string
+	msg        string
+}
+
+// Error returns the error message
+func (e *UnknownFlagError) Error() string {
+	return e.msg
+}
+
+// Is returns whether the given target is ErrUnknownFlag or not (see errors.Is)
+func (e *UnknownFlagError) Is(target error) bool {
+	return target == ErrUnknownFlag
+}
+
+// requiredError returns a required error for the given flag and command
+func (flagSet *FlagSet) requiredError(flag *Flag, command string) error {
+	e := RequiredError{Flag: flag, Command: command}
+	switch {
+	case flag.kind == "command":
+		e.msg = fmt.Sprintf(flagSet.message("command %s is required"), flag.command)
+	case command != "":
+		e.msg = fmt.Sprintf(flagSet.message("argument %s is required for %s command"), flag.FormattedArg(), command)
+	default:
+		e.msg = fmt.Sprintf(flagSet.message("argument %s is required"), flag.FormattedArg())
+	}
+	return &e
+}
+
+// parseError returns a parse error for the given flag, value and type
+func (flagSet *FlagSet) parseError(flag *Flag, value, valueType string) error {
+	return &ParseError{
+		Flag:  flag,
+		Value: value,
+		Type:  valueType,
+		msg:   fmt.Sprintf(flagSet.message("failed to parse '%s' as "+valueType), value), // i.e. `failed to parse '%s' as int`
+	}
+}
+
+// unknownFlagError returns an unknown flag error for the given argument dash, name and suggestion
+func (flagSet *FlagSet) unknownFlagError(dash, name, suggestion string) error {
+	e := UnknownFlagError{Name: dash + name, Suggestion: suggestion}
+	e.msg = fmt.Sprintf(flagSet.message("unknown argument: %s%s"), dash, name)
+	if suggestion != "" {
+		e.msg = fmt.Sprintf(flagSet.message("%s, did you mean %s?"), e.msg, suggestion)
+	}
+	return &e
+}
@@ -0,0 +1,138 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMultiError(t *testing.T) {
+	Convey("should return the flag definition errors together", t, func() {
+		flags := struct {
+			Foo bool     `short:"ff"`
+			Bar struct{} `command:"bar" from-file:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(flagSet, ShouldBeNil)
+		So(err, ShouldBeError, "short argument ff in Foo field must be one character long\nfrom-file tag in Bar field requires an argument")
+
+		var me flagset.MultiError
+		So(errors.As(err, &me), ShouldBeTrue)
+		So(me.Unwrap(), ShouldHaveLength, 2)
+		So(me.Unwrap()[1], ShouldBeError, "from-file tag in Bar field requires an argument")
+	})
+
+	Convey("should return the parse errors together", t, func() {
+		flags := struct {
+			Port int    `long:"port"`
+			Name string `long:"name" required:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "--port=abc", "--verbose"}})
+		So(flagSet, ShouldNotBeNil)
+		So(err, ShouldResemble, flagSet.Err())
+		So(err, ShouldBeError, "argument --name is required\nfailed to parse 'abc' as int\nunknown argument: --verbose")
+		So(errors.Is(err, flagset.ErrParse), ShouldBeTrue)
+		So(errors.Is(err, flagset.ErrRequired), ShouldBeTrue)
+		So(errors.Is(err, flagset.ErrUnknownFlag), ShouldBeTrue)
+		var pe *flagset.ParseError
+		So(errors.As(err, &pe), ShouldBeTrue)
+		So(pe.Flag, ShouldEqual, flagSet.FlagByName("Port"))
+
+		err = flagSet.Parse([]string{"./app", "--name=foo", "--port=x"})
+		So(err, ShouldBeError, "failed to parse 'x' as int")
+		So(errors.As(err, &pe), ShouldBeTrue)
+		So(flagSet.Parse([]string{"./app", "--name=foo"}), ShouldBeNil)
+	})
+}
+
+func TestRequiredError(t *testing.T) {
+	Convey("should return the required errors", t, func() {
+		flags := struct {
+			Name       string `long:"name" required:"true"`
+			CommandFoo struct {
+				Bar string `short:"b" required:"true"`
+			} `command:"foo"`
+			CommandQux struct{} `command:"qux" required:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "foo"}})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		errs := flagSet.Errors()
+		So(errs, ShouldHaveLength, 3)
+		for _, err := range errs {
+			So(errors.Is(err, flagset.ErrRequired), ShouldBeTrue)
+			So(errors.Is(err, flagset.ErrParse), ShouldBeFalse)
+		}
+
+		var re *flagset.RequiredError
+		So(errors.As(errs[0], &re), ShouldBeTrue)
+		So(re.Flag, ShouldEqual, flagSet.FlagByName("Name"))
+		So(re.Command, ShouldEqual, "")
+		So(re.Error(), ShouldEqual, "argument --name is required")
+		So(errors.As(errs[1], &re), ShouldBeTrue)
+		So(re.Flag, ShouldEqual, flagSet.FlagByName("CommandFoo.Bar"))
+		So(re.Command, ShouldEqual, "foo")
+		So(errors.As(errs[2], &re), ShouldBeTrue)
+		So(re.Flag, ShouldEqual, flagSet.FlagByName("CommandQux"))
+		So(re.Error(), ShouldEqual, "command qux is required")
+	})
+}
+
+func TestParseError(t *testing.T) {
+	Convey("should return the parse errors", t, func() {
+		flags := struct {
+			Port     int    `long:"port"`
+			Ratios   []uint `long:"ratio"`
+			Password int    `long:"password" secret:"true"`
+		}{}
+		args := []string{"./app", "--port=abc", "--ratio=1", "--ratio=-1", "--password=s3cr3t"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		errs := flagSet.Errors()
+		So(errs, ShouldHaveLength, 3)
+
+		var pe *flagset.ParseError
+		So(errors.Is(errs[0], flagset.ErrParse), ShouldBeTrue)
+		So(errors.As(errs[0], &pe), ShouldBeTrue)
+		So(pe.Flag, ShouldEqual, flagSet.FlagByName("Port"))
+		So(pe.Value, ShouldEqual, "abc")
+		So(pe.Type, ShouldEqual, "int")
+		So(pe.Error(), ShouldEqual, "failed to parse 'abc' as int")
+		So(errors.As(errs[1], &pe), ShouldBeTrue)
+		So(pe.Value, ShouldEqual, "-1")
+		So(pe.Type, ShouldEqual, "uint")
+		So(errors.As(errs[2], &pe), ShouldBeTrue)
+		So(pe.Value, ShouldEqual, flagset.SecretPlaceholder)
+	})
+}
+
+func TestUnknownFlagError(t *testing.T) {
+	Convey("should return the unknown flag errors", t, func() {
+		flags := struct {
+			Verbose bool `long:"verbose"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "--verbos", "-x"}})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		errs := flagSet.Errors()
+		So(errs, ShouldHaveLength, 2)
+
+		var ue *flagset.UnknownFlagError
+		So(errors.Is(errs[0], flagset.ErrUnknownFlag), ShouldBeTrue)
+		So(errors.As(errs[0], &ue), ShouldBeTrue)
+		So(ue.Name, ShouldEqual, "--verbos")
+		So(ue.Suggestion, ShouldEqual, "--verbose")
+		So(ue.Error(), ShouldEqual, "unknown argument: --verbos, did you mean --verbose?")
+		So(errors.As(errs[1], &ue), ShouldBeTrue)
+		So(ue.Name, ShouldEqual, "-x")
+		So(ue.Suggestion, ShouldEqual, "")
+	})
+}
@@ -7,6 +7,9 @@ package flagset
 
 import "fmt"
 
+// SecretPlaceholder is shown instead of the values of the secret flags
+const SecretPlaceholder = "***"
+
 var (
 	supportedFlagTypes = []string{
 		"bool",
@@ -51,13 +54,44 @@ type Flag struct {
 	long            string
 	command         string
 	description     string
-	required        bool // flag must be present
-	nonempty        bool // if the flag is present then it must have a value
-	allowUnknownArg bool // allow unknown arguments to be present
+	group           string
+	placeholder     string // value name for usage (i.e. FILE for `--config FILE`)
+	required        bool   // flag must be present
+	nonempty        bool   // if the flag is present then it must have a value
+	allowUnknownArg bool   // allow unknown arguments to be present
 	global          bool
+	once            bool     // flag must not be repeated
+	secret          bool     // value must not appear in usage, errors, etc.
+	validate        []string // names of the validators for the flag values
+	requires        []string // names of the companion flags those must be present with the flag
+	examples        []string // invocation examples for usage (i.e. `app deploy --env prod`)
+	showGroups      []string // only groups those are shown in the usage of the command
+	hideGroups      []string // groups those are hidden in the usage of the command
+	longDescription string   // detailed description of the command for its own usage
+	seeAlso         []string // related commands or references of the command (i.e. `app rollback`)
+	advanced        bool     // argument is shown only in the verbose usage (i.e. `--help-all`)
 	delimiter       string
+	keepEmpty       bool   // keep the empty elements of the delimited values
+	nargs           string // number of values per occurrence (i.e. `2` or `+`)
+	arrayLen        int    // length of the fixed-size array field (i.e. 2 for `[2]int`)
+	repeat          string // policy for the repeated arguments (last, first, error or append)
+	greedy          bool   // consume the following values until the next argument (i.e. `nargs:"+"`)
+	ordered         bool   // stop parsing at the first unnamed argument of the command
+	defaultCommand  bool   // command is used when no other command of its level is present
+	deprecated      string // deprecation message of the command (i.e. `renamed to sync`)
+	redirect        string // replacement command of the deprecated command
+	lenientNumbers  bool   // accept the separators and the scientific notation (i.e. `1_000`, `1e6`)
 	env             string
+	envPrefix       string // prefix for the env variable names of the nested flags
 	valueDefault    string
+	defaultFrom     string // source of the default value (i.e. `env:HOME`, `file:/path`, `func:Name`)
+	defaultValue    string // default value those is resolved by the last parse (see Default)
+	defaultResolved bool
+	expand          bool   // expand the env variables in the values (i.e. `${HOME}/data`)
+	fromFile        bool   // read the values from the files (i.e. `file:/run/secrets/token`)
+	keyring         string // service and account of the secret in the keychain (i.e. `app/token`)
+	config          string // dot-path of the flag value in the config file (i.e. `server.port`)
+	configFile      bool   // value is the path of the config file (i.e. `--config FILE`)
 	valueType       string
 	valueBy         string
 	value           interface{}
@@ -112,6 +146,16 @@ func (f *Flag) Description() string {
 	return f.description
 }
 
+// Group returns the group name of the flag
+func (f *Flag) Group() string {
+	return f.group
+}
+
+// Placeholder returns the value name of the flag for usage
+func (f *Flag) Placeholder() string {
+	return f.placeholder
+}
+
 // Required returns whether the flag is required or not
 func (f *Flag) Required() bool {
 	return f.required
@@ -127,11 +171,116 @@ func (f *Flag) Global() bool {
 	return f.global
 }
 
+// Once returns whether the flag can be present only once or not
+func (f *Flag) Once() bool {
+	return f.once
+}
+
+// Secret returns whether the flag value is secret or not
+func (f *Flag) Secret() bool {
+	return f.secret
+}
+
+// Validate returns the names of the validators of the flag
+func (f *Flag) Validate() []string {
+	return f.validate
+}
+
+// Requires returns the names of the companion flags those must be present with the flag
+func (f *Flag) Requires() []string {
+	return f.requires
+}
+
+// KeepEmpty returns whether the empty elements of the delimited values are kept or not
+func (f *Flag) KeepEmpty() bool {
+	return f.keepEmpty
+}
+
+// Nargs returns the number of values those the flag consumes per occurrence
+func (f *Flag) Nargs() string {
+	return f.nargs
+}
+
+// Expand returns whether the env variables in the values are expanded or not
+func (f *Flag) Expand() bool {
+	return f.expand
+}
+
+// LenientNumbers returns whether the separators and the scientific notation are accepted or not
+func (f *Flag) LenientNumbers() bool {
+	return f.lenientNumbers
+}
+
+// Ordered returns whether the parsing stops at the first unnamed argument of the command or not
+func (f *Flag) Ordered() bool {
+	return f.ordered
+}
+
+// DefaultCommand returns whether the flag is the default command of its level or not
+func (f *Flag) DefaultCommand() bool {
+	return f.defaultCommand
+}
+
+// Deprecated returns the deprecation message of the command
+func (f *Flag) Deprecated() string {
+	return f.deprecated
+}
+
+// Redirect returns the replacement command of the deprecated command
+func (f *Flag) Redirect() string {
+	return f.redirect
+}
+
+// Examples returns the invocation examples of the flag
+func (f *Flag) Examples() []string {
+	return f.examples
+}
+
+// ShowGroups returns the groups those are shown in the usage of the command (all for none)
+func (f *Flag) ShowGroups() []string {
+	return f.showGroups
+}
+
+// HideGroups returns the groups those are hidden in the usage of the command
+func (f *Flag) HideGroups() []string {
+	return f.hideGroups
+}
+
+// LongDescription returns the detailed description of the command
+func (f *Flag) LongDescription() string {
+	return f.longDescription
+}
+
+// SeeAlso returns the related commands or references of the command
+func (f *Flag) SeeAlso() []string {
+	return f.seeAlso
+}
+
+// Advanced returns whether the argument is shown only in the verbose usage or not
+func (f *Flag) Advanced() bool {
+	return f.advanced
+}
+
+// Greedy returns whether the flag consumes the following values until the next argument or not
+func (f *Flag) Greedy() bool {
+	return f.greedy
+}
+
+// Repeat returns the policy of the flag for the repeated arguments
+func (f *Flag) Repeat() string {
+	return f.repeat
+}
+
 // Env returns the environment variable name of the flag
 func (f *Flag) Env() string {
 	return f.env
 }
 
+// EnvPrefix returns the environment variable name prefix of the flag
+func (f *Flag) EnvPrefix() string {
+	return f.envPrefix
+}
+
 // Delimiter returns the delimiter value of the flag
 func (f *Flag) Delimiter() string {
 	return f.delimiter
@@ -142,16 +291,65 @@ func (f *Flag) ValueDefault() string {
 	return f.valueDefault
 }
 
-// ValueType returns the value type of the flag
+// Default returns the default value those the flag falls back to. The value of the default-from
+// source is returned when it's resolved by the last parse (i.e. the env variable of `default-from:"env:HOME"`
+// when the flag has no argument, env or config value), otherwise the value of the default tag is returned
+// (see ValueDefault). The sources are not read again. The default values of the secret flags are redacted
+// (see SecretPlaceholder).
+func (f *Flag) Default() string {
+	v := f.valueDefault
+	if f.defaultResolved {
+		v = f.defaultValue
+	}
+	if f.secret && v != "" {
+		return SecretPlaceholder
+	}
+	return v
+}
+
+// DefaultFrom returns the source of the default value of the flag
+func (f *Flag) DefaultFrom() string {
+	return f.defaultFrom
+}
+
+// FromFile returns whether the flag reads its values from the files or not (i.e. `file:/run/secrets/token`)
+func (f *Flag) FromFile() bool {
+	return f.fromFile
+}
+
+// Keyring returns the service and the account of the secret in the keychain (i.e. `app/token`)
+func (f *Flag) Keyring() string {
+	return f.keyring
+}
+
+// Config returns the config key path of the flag
+func (f *Flag) Config() string {
+	return f.config
+}
+
+// ConfigFile returns whether the value of the flag is the path of the config file or not
+func (f *Flag) ConfigFile() bool {
+	return f.configFile
+}
+
+// ValueType returns the value type of the flag. The fixed-size array fields have the slice type
+// (i.e. `[]int` for `[2]int`, see Nargs)
 func (f *Flag) ValueType() string {
 	return f.valueType
 }
 
-// ValueBy returns the value by of the flag
+// ValueBy returns the value by of the flag: arg, env, secrets, keyring, config, default or
+// empty string if the value is not set
 func (f *Flag) ValueBy() string {
 	return f.valueBy
 }
 
+// Changed returns whether the value of the flag is provided explicitly (i.e. by an argument,
+// an env variable or a config file) or not. The default values are not considered as changed.
+func (f *Flag) Changed() bool {
+	return f.valueBy != "" && f.valueBy != "default"
+}
+
 // Value returns the value of the flag
 func (f *Flag) Value() interface{} {
 	return f.value
@@ -162,7 +360,8 @@ func (f *Flag) Kind() string {
 	return f.kind
 }
 
-// FieldIndex returns the parent flag id of the flag
+// FieldIndex returns the struct field index of the flag
+// When the flags are composed, the first index is the index of the struct.
 func (f *Flag) FieldIndex() []int {
 	return f.fieldIndex
 }
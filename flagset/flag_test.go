@@ -7,6 +7,7 @@ package flagset_test
 
 import (
 	"errors"
+	"os"
 	"testing"
 
 	"github.com/devfacet/gocmd/flagset"
@@ -19,7 +20,7 @@ func TestFlag_ID(t *testing.T) {
 			Test string `short:"f"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -33,7 +34,7 @@ func TestFlag_Name(t *testing.T) {
 			Test string `short:"f"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -47,7 +48,7 @@ func TestFlag_Short(t *testing.T) {
 			Test string `short:"f"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -61,7 +62,7 @@ func TestFlag_Long(t *testing.T) {
 			Test string `long:"foo"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -75,7 +76,7 @@ func TestFlag_FormattedArg(t *testing.T) {
 			Test string `short:"f"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -90,7 +91,7 @@ func TestFlag_Command(t *testing.T) {
 			} `command:"bar"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -104,7 +105,7 @@ func TestFlag_Description(t *testing.T) {
 			Test string `short:"f" description:"baz"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -112,13 +113,41 @@ func TestFlag_Description(t *testing.T) {
 	})
 }
 
+func TestFlag_Group(t *testing.T) {
+	Convey("should return the group of the flag", t, func() {
+		flags := struct {
+			Test string `short:"f" group:"Networking"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Group(), ShouldEqual, "Networking")
+	})
+}
+
+func TestFlag_Placeholder(t *testing.T) {
+	Convey("should return the placeholder of the flag", t, func() {
+		flags := struct {
+			Test string `short:"f" placeholder:"FILE"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Placeholder(), ShouldEqual, "FILE")
+	})
+}
+
 func TestFlag_Required(t *testing.T) {
 	Convey("should return the required value of the flag", t, func() {
 		flags := struct {
 			Test string `short:"f" required:"true"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -137,7 +166,7 @@ func TestFlag_Nonempty(t *testing.T) {
 			Test string `short:"t" required:"true"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Foo")
 		So(flag, ShouldNotBeNil)
@@ -166,7 +195,7 @@ func TestFlag_Global(t *testing.T) {
 			Test string `short:"f" global:"true"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -174,13 +203,269 @@ func TestFlag_Global(t *testing.T) {
 	})
 }
 
+func TestFlag_Once(t *testing.T) {
+	Convey("should return the once value of the flag", t, func() {
+		flags := struct {
+			Test string `short:"f" once:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Once(), ShouldEqual, true)
+	})
+}
+
+func TestFlag_Secret(t *testing.T) {
+	Convey("should return the secret value of the flag", t, func() {
+		flags := struct {
+			Test string `short:"f" secret:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Secret(), ShouldEqual, true)
+	})
+}
+
+func TestFlag_Validate(t *testing.T) {
+	Convey("should return the validators of the flag", t, func() {
+		flags := struct {
+			Test string `short:"f" validate:"nonempty, url"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Validate(), ShouldResemble, []string{"nonempty", "url"})
+	})
+}
+
+func TestFlag_Requires(t *testing.T) {
+	Convey("should return the requires value of the flag", t, func() {
+		flags := struct {
+			Foo  string `long:"foo"`
+			Bar  string `long:"bar"`
+			Test string `short:"f" requires:"Foo,Bar"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Requires(), ShouldResemble, []string{"Foo", "Bar"})
+	})
+}
+
+func TestFlag_Expand(t *testing.T) {
+	Convey("should return the expand value of the flag", t, func() {
+		flags := struct {
+			Test string `short:"f" expand:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Expand(), ShouldEqual, true)
+	})
+}
+
+func TestFlag_LenientNumbers(t *testing.T) {
+	Convey("should return the lenient numbers value of the flag", t, func() {
+		flags := struct {
+			Test int `short:"f" lenient-numbers:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.LenientNumbers(), ShouldEqual, true)
+	})
+}
+
+func TestFlag_Ordered(t *testing.T) {
+	Convey("should return the ordered value of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" ordered:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Ordered(), ShouldEqual, true)
+	})
+}
+
+func TestFlag_DefaultCommand(t *testing.T) {
+	Convey("should return the default command value of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" default:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.DefaultCommand(), ShouldEqual, true)
+		So(flag.ValueDefault(), ShouldEqual, "")
+	})
+}
+
+func TestFlag_Deprecated(t *testing.T) {
+	Convey("should return the deprecated value of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" deprecated:"renamed to sync"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Deprecated(), ShouldEqual, "renamed to sync")
+	})
+}
+
+func TestFlag_Redirect(t *testing.T) {
+	Convey("should return the redirect value of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" deprecated:"true" redirect:"sync"`
+			Sync struct{} `command:"sync"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Redirect(), ShouldEqual, "sync")
+	})
+}
+
+func TestFlag_Examples(t *testing.T) {
+	Convey("should return the examples of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" example:"app test\n app test -v "`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Examples(), ShouldResemble, []string{"app test", "app test -v"})
+	})
+}
+
+func TestFlag_ShowGroups(t *testing.T) {
+	Convey("should return the shown groups of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" show-groups:"Networking, Debugging"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.ShowGroups(), ShouldResemble, []string{"Networking", "Debugging"})
+	})
+}
+
+func TestFlag_HideGroups(t *testing.T) {
+	Convey("should return the hidden groups of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" hide-groups:"Debugging"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.HideGroups(), ShouldResemble, []string{"Debugging"})
+	})
+}
+
+func TestFlag_LongDescription(t *testing.T) {
+	Convey("should return the long description of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" long-description:" Run the tests.\nThe results are cached. "`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.LongDescription(), ShouldEqual, "Run the tests.\nThe results are cached.")
+	})
+}
+
+func TestFlag_SeeAlso(t *testing.T) {
+	Convey("should return the related commands of the flag", t, func() {
+		flags := struct {
+			Test struct{} `command:"test" see-also:"app build, app lint"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.SeeAlso(), ShouldResemble, []string{"app build", "app lint"})
+	})
+}
+
+func TestFlag_Advanced(t *testing.T) {
+	Convey("should return the advanced value of the flag", t, func() {
+		flags := struct {
+			Test bool `long:"test" advanced:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Advanced(), ShouldBeTrue)
+	})
+}
+
+func TestFlag_Greedy(t *testing.T) {
+	Convey("should return the greedy value of the flag", t, func() {
+		flags := struct {
+			Test []string `short:"f" greedy:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Greedy(), ShouldEqual, true)
+	})
+}
+
+func TestFlag_Repeat(t *testing.T) {
+	Convey("should return the repeat policy of the flag", t, func() {
+		flags := struct {
+			Test string `short:"f" repeat:"first"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Repeat(), ShouldEqual, "first")
+	})
+}
+
 func TestFlag_Env(t *testing.T) {
 	Convey("should return the env value of the flag", t, func() {
 		flags := struct {
 			Test string `short:"f" env:"GOPATH"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -188,13 +473,29 @@ func TestFlag_Env(t *testing.T) {
 	})
 }
 
+func TestFlag_EnvPrefix(t *testing.T) {
+	Convey("should return the env prefix value of the flag", t, func() {
+		flags := struct {
+			Test struct {
+				Foo string `long:"foo" env:"FOO"`
+			} `command:"test" env-prefix:"TEST_"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.EnvPrefix(), ShouldEqual, "TEST_")
+	})
+}
+
 func TestFlag_Delimiter(t *testing.T) {
 	Convey("should return the delimiter value of the flag", t, func() {
 		flags := struct {
 			Test []string `short:"f" delimiter:","`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -202,13 +503,111 @@ func TestFlag_Delimiter(t *testing.T) {
 	})
 }
 
+func TestFlag_KeepEmpty(t *testing.T) {
+	Convey("should return the keep empty value of the flag", t, func() {
+		flags := struct {
+			Test []string `short:"f" delimiter:"," keep-empty:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.KeepEmpty(), ShouldEqual, true)
+	})
+}
+
+func TestFlag_Nargs(t *testing.T) {
+	Convey("should return the nargs value of the flag", t, func() {
+		flags := struct {
+			Test []int `short:"f" nargs:"2"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Nargs(), ShouldEqual, "2")
+	})
+}
+
+func TestFlag_DefaultFrom(t *testing.T) {
+	Convey("should return the default source of the flag", t, func() {
+		flags := struct {
+			Test string `short:"f" default-from:"env:HOME"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.DefaultFrom(), ShouldEqual, "env:HOME")
+	})
+}
+
+func TestFlag_FromFile(t *testing.T) {
+	Convey("should return whether the flag reads its values from the files or not", t, func() {
+		flags := struct {
+			Test string `long:"token" from-file:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.FromFile(), ShouldBeTrue)
+	})
+}
+
+func TestFlag_Keyring(t *testing.T) {
+	Convey("should return the service and the account of the secret in the keychain", t, func() {
+		flags := struct {
+			Test string `long:"token" keyring:"app/token"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Keyring(), ShouldEqual, "app/token")
+	})
+}
+
+func TestFlag_Config(t *testing.T) {
+	Convey("should return the config key path of the flag", t, func() {
+		flags := struct {
+			Test string `long:"level" config:"log.level"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.Config(), ShouldEqual, "log.level")
+	})
+}
+
+func TestFlag_ConfigFile(t *testing.T) {
+	Convey("should return whether the flag is the config file or not", t, func() {
+		flags := struct {
+			Test string `long:"config" config-file:"true"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flag := flagSet.FlagByName("Test")
+		So(flag, ShouldNotBeNil)
+		So(flag.ConfigFile(), ShouldBeTrue)
+	})
+}
+
 func TestFlag_ValueDefault(t *testing.T) {
 	Convey("should return the default value of the flag", t, func() {
 		flags := struct {
 			Test string `short:"f" default:"qux"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -216,13 +615,50 @@ func TestFlag_ValueDefault(t *testing.T) {
 	})
 }
 
+func TestFlag_Default(t *testing.T) {
+	Convey("should return the default value those the flag falls back to", t, func() {
+		os.Setenv("GOCMD_TEST_DEFAULT", "bar")
+		defer os.Unsetenv("GOCMD_TEST_DEFAULT")
+
+		flags := struct {
+			Test    string `short:"f" default:"qux"`
+			From    string `long:"from" default:"qux" default-from:"env:GOCMD_TEST_DEFAULT"`
+			Missing string `long:"missing" default:"qux" default-from:"env:GOCMD_TEST_MISSING"`
+			None    string `long:"none"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.FlagByName("Test").Default(), ShouldEqual, "qux")
+		So(flagSet.FlagByName("From").Default(), ShouldEqual, "bar")
+		So(flagSet.FlagByName("From").ValueDefault(), ShouldEqual, "qux")
+		So(flagSet.FlagByName("Missing").Default(), ShouldEqual, "qux")
+		So(flagSet.FlagByName("None").Default(), ShouldEqual, "")
+
+		// The sources are not read again
+		os.Setenv("GOCMD_TEST_DEFAULT", "baz")
+		So(flagSet.FlagByName("From").Default(), ShouldEqual, "bar")
+		So(flagSet.Parse([]string{"./app"}), ShouldBeNil)
+		So(flagSet.FlagByName("From").Default(), ShouldEqual, "baz")
+
+		secrets := struct {
+			Token string `long:"token" default:"qux" secret:"true"`
+			Key   string `long:"key" secret:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &secrets, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet.FlagByName("Token").Default(), ShouldEqual, flagset.SecretPlaceholder)
+		So(flagSet.FlagByName("Key").Default(), ShouldEqual, "")
+	})
+}
+
 func TestFlag_ValueType(t *testing.T) {
 	Convey("should return the value type of the flag", t, func() {
 		flags := struct {
 			Test string `short:"f"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -230,13 +666,34 @@ func TestFlag_ValueType(t *testing.T) {
 	})
 }
 
+func TestFlag_Changed(t *testing.T) {
+	Convey("should return whether the value of the flag is provided explicitly or not", t, func() {
+		os.Setenv("GOCMD_TEST_CHANGED", "bar")
+		defer os.Unsetenv("GOCMD_TEST_CHANGED")
+
+		flags := struct {
+			Arg     string `short:"a"`
+			Env     string `long:"env" env:"GOCMD_TEST_CHANGED"`
+			Default string `long:"default" default:"foo"`
+			None    string `long:"none"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "-a=foo"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.FlagByName("Arg").Changed(), ShouldBeTrue)
+		So(flagSet.FlagByName("Env").Changed(), ShouldBeTrue)
+		So(flagSet.FlagByName("Default").Changed(), ShouldBeFalse)
+		So(flagSet.FlagByName("None").Changed(), ShouldBeFalse)
+	})
+}
+
 func TestFlag_ValueBy(t *testing.T) {
 	Convey("should return the value by of the flag", t, func() {
 		flags01 := struct {
 			Test string `short:"f"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
@@ -331,7 +788,7 @@ func TestFlag_FieldIndex(t *testing.T) {
 			} `command:"bar"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Foo")
 		So(flag, ShouldNotBeNil)
@@ -360,7 +817,7 @@ func TestFlag_ParentIndex(t *testing.T) {
 			} `command:"bar"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Bar.Baz")
 		So(flag, ShouldNotBeNil)
@@ -387,7 +844,7 @@ func TestFlag_ParentID(t *testing.T) {
 			} `command:"foo"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("CommandFoo.Test")
 		So(flag, ShouldNotBeNil)
@@ -415,7 +872,7 @@ func TestFlag_CommandID(t *testing.T) {
 			} `command:"foo"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("CommandFoo")
 		So(flag, ShouldNotBeNil)
@@ -435,7 +892,7 @@ func TestFlag_Err(t *testing.T) {
 			Test string `short:"f" required:"true"`
 		}{}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flag := flagSet.FlagByName("Test")
 		So(flag, ShouldNotBeNil)
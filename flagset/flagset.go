@@ -9,58 +9,262 @@ package flagset
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
-// Options represents the options that can be set when creating a new flag set
+var (
+	// DefaultPrecedence is the default order of the value sources (see Options.Precedence)
+	DefaultPrecedence = []string{"arg", "env", "secrets", "keyring", "config", "default"}
+)
+
+// Options represents the options that can be set when creating a new flag set (see Option)
 type Options struct {
 	// Flags represent the user defined command line arguments and commands.
 	// When it's a struct type, each field represent an argument or command.
+	// When it's a slice of struct pointers (i.e. `[]interface{}{&logFlags, &tlsFlags}`), the fields of
+	// the structs are merged into one namespace so the duplicate arguments and commands are reported.
+	// When it's a builder (or a builder in the slice), the flags are defined without struct tags (see Builder).
 	Flags interface{}
 	// Args hold command line arguments. Default is os.Args
 	Args []string
+	// CaseInsensitive matches the long arguments regardless of the case (i.e. `--Verbose` for `--verbose`)
+	CaseInsensitive bool
+	// Ordered stops parsing at the first unnamed argument (POSIX-style) so the rest is kept as is.
+	// Otherwise arguments may appear after the unnamed arguments (GNU-style, default).
+	Ordered bool
+	// Partial collects the unknown arguments instead of failing (see Remaining method)
+	Partial bool
+	// Repeat is the default policy for the repeated arguments (last, first, error or append).
+	// Default is last for the non-slice and append for the slice arguments.
+	Repeat string
+	// LenientNumbers accepts the separators (i.e. `1_000_000`, `1,000`) and the scientific
+	// notation (i.e. `1e6`) for the numeric arguments (see `lenient-numbers` tag)
+	LenientNumbers bool
+	// Chain allows the sibling commands in one invocation, each with its own arguments
+	// (i.e. `app build -v test publish`, see ChainedCommands method). Otherwise they are mutually exclusive.
+	Chain bool
+	// Localizer translates the parse errors and the warnings (see Localizer interface). Default is English
+	Localizer Localizer
+	// ConfigFile is the path of the JSON or INI (`.ini`, `.conf`) config file those values are used when
+	// the arguments and the env variables are not present. The values are matched by the long names
+	// and the command values are nested under the command names (i.e. `{"deploy": {"env": "prod"}}`
+	// or `[deploy]` section). The `config` tags are the paths from the top level (i.e. `config:"server.port"`).
+	// The argument of the flag with `config-file` tag overrides it (i.e. `--config FILE`).
+	ConfigFile string
+	// ConfigFiles are the paths of the config files those are loaded after ConfigFile in order.
+	// The later files override the values of the earlier ones key by key (see Config method)
+	ConfigFiles []string
+	// ConfigSource reads the config files those have a URL (i.e. `https://config.internal/app.json`).
+	// Default is HTTPConfigSource for the HTTP(S) URLs
+	ConfigSource ConfigSource
+	// ExpandConfig expands the env variables in the config values (i.e. `${DB_HOST}:5432`) before
+	// the type conversion so the same config file can be used for the different environments
+	ExpandConfig bool
+	// ConfigPaths are the paths those are searched in order for the config file when there is no
+	// explicit one (i.e. `/etc/app/config.json`). The first existing file is used (see ConfigFile method)
+	ConfigPaths []string
+	// Precedence is the order of the value sources: arg, env, secrets, keyring, config and default. The sources those
+	// are not listed are not used (i.e. `[]string{"env", "arg", "default"}` prefers the env variables
+	// over the arguments for the container deployments). Default is DefaultPrecedence
+	Precedence []string
+	// EnvPrefix is the prefix of all the env variable names (i.e. `MYAPP_` for `env:"PORT"`).
+	// The names those already start with the prefix are kept as is
+	EnvPrefix string
+	// SecretsDir is the directory of the secret files (i.e. `/run/secrets` or a Kubernetes volume).
+	// The file those is named by the env variable name or the long name of a flag provides its value
+	SecretsDir string
+	// SecretStore is the store of the secrets for the flags those have a keyring tag (i.e. `keyring:"app/token"`).
+	// Default is Keyring (the platform keychain)
+	SecretStore SecretStore
+	// AutoEnv derives the env variable names of the arguments those have no env tag from their long
+	// names (i.e. `LOG_LEVEL` for `--log-level`) with the env prefixes of the commands and EnvPrefix
+	AutoEnv bool
+}
+
+// New returns a flag set by the given options and parses the arguments (see Compile and Parse methods)
+// The flag definition errors are returned together without a flag set (see MultiError). The parse errors
+// are returned together with the flag set (see Err method) so its usage and errors can be printed.
+func New(opts ...Option) (*FlagSet, error) {
+	o := applyOptions(opts)
+	flagSet, err := compile(o)
+	if err != nil {
+		return nil, err
+	}
+	return flagSet, flagSet.Parse(o.Args)
+}
+
+// Compile returns a flag set by the given options without parsing the arguments (Args option is ignored)
+// The flags are analyzed and validated once so the flag set can parse the different arguments by the
+// Parse method without the reflection work of the struct tags.
+func Compile(opts ...Option) (*FlagSet, error) {
+	return compile(applyOptions(opts))
 }
 
-// New returns a flag set by the given options
-func New(o Options) (*FlagSet, error) {
+// compile returns a flag set by the given options (see Compile)
+func compile(o options) (*FlagSet, error) {
 	// Check the options
+	if o.strict {
+		o.Partial, o.CaseInsensitive, o.LenientNumbers = false, false, false
+	}
 	if o.Flags == nil {
 		return nil, fmt.Errorf("flags are required")
-	} else if !strings.HasPrefix(fmt.Sprintf("%T", o.Flags), "*struct") {
-		if o.Flags == nil || reflect.ValueOf(o.Flags).Kind() != reflect.Ptr || reflect.Indirect(reflect.ValueOf(o.Flags)).Kind() != reflect.Struct {
-			return nil, fmt.Errorf("flags must be a struct pointer")
+	} else if b, ok := o.Flags.(*Builder); ok {
+		if b == nil || len(b.fields) == 0 {
+			return nil, fmt.Errorf("flags are required")
+		}
+	} else if flags, ok := o.Flags.([]interface{}); ok {
+		if len(flags) == 0 {
+			return nil, fmt.Errorf("flags are required")
+		}
+		for _, v := range flags {
+			if !isStructPointer(v) {
+				return nil, fmt.Errorf("flags must be a struct pointer")
+			}
 		}
+	} else if !isStructPointer(o.Flags) {
+		return nil, fmt.Errorf("flags must be a struct pointer")
 	}
-	if o.Args == nil {
-		o.Args = os.Args // default
+	if o.Repeat != "" && !isRepeatPolicy(o.Repeat) {
+		return nil, fmt.Errorf("invalid repeat policy %s", o.Repeat)
+	}
+	if o.Precedence != nil {
+		sources := map[string]bool{}
+		for _, v := range o.Precedence {
+			if !isValueSource(v) {
+				return nil, fmt.Errorf("invalid value source %s", v)
+			} else if sources[v] {
+				return nil, fmt.Errorf("value source %s is duplicated", v)
+			}
+			sources[v] = true
+		}
+		if !sources["arg"] {
+			return nil, errors.New("value source arg is required")
+		}
 	}
 
 	// Init vars
 	flagSet := FlagSet{
-		flagsRaw: o.Flags,
-		argsRaw:  make([]string, len(o.Args)),
+		flagsRaw:        o.Flags,
+		caseInsensitive: o.CaseInsensitive,
+		ordered:         o.Ordered,
+		partial:         o.Partial,
+		repeat:          o.Repeat,
+		lenientNumbers:  o.LenientNumbers,
+		chain:           o.Chain,
+		localizer:       o.Localizer,
+		configFile:      o.ConfigFile,
+		configFiles:     o.ConfigFiles,
+		configSource:    o.ConfigSource,
+		expandConfig:    o.ExpandConfig,
+		secretStore:     o.SecretStore,
+		secretsDir:      o.SecretsDir,
+		configSearch:    o.ConfigPaths,
+		precedence:      o.Precedence,
+		output:          o.output,
+	}
+
+	// Builders are replaced by the struct pointers those are generated by their definitions (see Builder)
+	if b, ok := o.Flags.(*Builder); ok {
+		flagSet.builders = []*Builder{b}
+		flagSet.flagsRaw = b.value()
+	} else if flags, ok := o.Flags.([]interface{}); ok {
+		values := make([]interface{}, len(flags))
+		for k, v := range flags {
+			if b, ok := v.(*Builder); ok {
+				if flagSet.builders == nil {
+					flagSet.builders = make([]*Builder, len(flags))
+				}
+				flagSet.builders[k], values[k] = b, b.value()
+				continue
+			}
+			values[k] = v
+		}
+		flagSet.flagsRaw = values
 	}
-	copy(flagSet.argsRaw, o.Args) // make a copy
 
 	// Parse flags
 	if flagSet.flagsRaw != nil {
 		var errs []error
 		flagSet.flags, errs = structToFlags(flagSet.flagsRaw)
 		if errs != nil {
-			return nil, errs[0] // return the first error
+			return nil, MultiError(errs)
+		}
+		if o.AutoEnv {
+			for _, flag := range flagSet.flags {
+				if flag.kind == "arg" && flag.env == "" && flag.long != "" {
+					flag.env = flagSet.envPrefix(flag) + envName(flag.long)
+				}
+			}
+		}
+		if o.EnvPrefix != "" {
+			for _, flag := range flagSet.flags {
+				if flag.env != "" && !strings.HasPrefix(flag.env, o.EnvPrefix) {
+					flag.env = o.EnvPrefix + flag.env
+				}
+			}
+		}
+	}
+
+	return &flagSet, nil
+}
+
+// Parse parses the given arguments and applies the values to the flags. Default is os.Args
+// It can be called multiple times since it resets the values and the errors of the previous parse.
+// The parse errors are returned together (see Err method) and they are also kept by the Errors method.
+func (flagSet *FlagSet) Parse(args []string) error {
+	if flagSet.flagsRaw == nil {
+		return errors.New("flags are required")
+	}
+	if args == nil {
+		args = os.Args // default
+	}
+
+	// Reset the previous state
+	flagSet.Reset()
+	flagSet.argsOrig = make([]string, len(args))
+	copy(flagSet.argsOrig, args) // make a copy
+	flagSet.argsRaw = flagSet.argsOrig
+	flagSet.argsRaw, flagSet.argsIndex = flagSet.splitShortArgs(flagSet.argsRaw)
+	for {
+		flagSet.terminator = flagSet.terminatorIndex(flagSet.argsRaw)
+		flagSet.commandsParsed = false
+		flagSet.parseCommands()
+		if !flagSet.redirectCommand() && !flagSet.insertDefaultCommand() {
+			break
+		}
+	}
+	for _, cmd := range flagSet.commands {
+		if flag := flagSet.flagByID(cmd.flagID); cmd.argID != -1 && flag != nil && flag.deprecated != "" {
+			flagSet.warnings = append(flagSet.warnings, flagSet.deprecationWarning(flag))
 		}
 	}
-	flagSet.parseCommands()
 	flagSet.parseArgs()
 	flagSet.parseSettings()
 
 	// Iterate over the flags and apply values to the fields
 	for _, flag := range flagSet.flags {
+		// Positional fields capture the unnamed arguments
+		if flag.kind == "pos" {
+			flagSet.unsetFlag(flag.id)
+			for _, arg := range flag.args {
+				flag.valueBy = "arg"
+				if err := flagSet.setFlag(flag.id, arg.arg); err != nil {
+					arg.err = err
+				}
+			}
+			continue
+		}
+
 		// Only argument fields can have values
 		if flag.kind != "arg" {
 			continue
@@ -68,7 +272,7 @@ func New(o Options) (*FlagSet, error) {
 
 		// Check global
 		if flag.kind == "arg" && flag.global && flag.parentID > -1 {
-			flag.err = fmt.Errorf("argument %s can't be global", flag.FormattedArg())
+			flag.err = flagSet.errorf("argument %s can't be global", flag.FormattedArg())
 			continue
 		}
 
@@ -77,12 +281,27 @@ func New(o Options) (*FlagSet, error) {
 			flagSet.unsetFlag(flag.id)
 		}
 
-		// Iterate over the args (last argument wins)
-		for _, arg := range flag.args {
+		// Check the repeat policy (i.e. only the first argument for `repeat:"first"`)
+		policy := flagSet.repeatPolicy(flag)
+		first, last := -1, -1
+		for k, arg := range flag.args {
+			if arg.kind == "arg" {
+				if first == -1 {
+					first = k
+				}
+				last = k
+			}
+		}
+
+		// Iterate over the args (last argument wins unless the policy says otherwise)
+		for k, arg := range flag.args {
 			// Only arguments (skip commands and argument values)
 			if arg.kind != "arg" {
 				continue
 			}
+			if (policy == "first" && k != first) || (policy == "last" && strings.HasPrefix(flag.valueType, "[]") && k != last) {
+				continue
+			}
 			flag.valueBy = "arg" // prevent default and env values to override it
 
 			// Handle truthy bool arguments (i.e. `-b --bool`. But not `-b=`)
@@ -94,82 +313,55 @@ func New(o Options) (*FlagSet, error) {
 			if arg.value == "" {
 				if ((flag.valueType == "bool" || flag.valueType == "[]bool") && arg.unset) || ((flag.valueType == "string" || flag.valueType == "[]string") && !arg.unset) {
 					// For example: `--bool=`, `--string`
-					arg.err = fmt.Errorf("argument %s%s needs a value", arg.dash, arg.name)
+					arg.err = flagSet.errorf("argument %s%s needs a value", arg.dash, arg.name)
 				} else if flag.valueType != "bool" && flag.valueType != "[]bool" && flag.valueType != "string" && flag.valueType != "[]string" {
 					// For example: `--int`
-					arg.err = fmt.Errorf("argument %s%s needs a value", arg.dash, arg.name)
+					arg.err = flagSet.errorf("argument %s%s needs a value", arg.dash, arg.name)
 				}
 			}
 
+			// Check the number of values (i.e. `--range 10 20` for `nargs:"2"`)
+			if n, err := strconv.Atoi(flag.nargs); err == nil && arg.err == nil && 1+len(arg.values) != n {
+				arg.err = flagSet.errorf("argument %s%s needs %d values", arg.dash, arg.name, n)
+			}
+
 			if arg.err != nil {
 				continue // do not continue if the argument has an error
 			}
 
 			// Update the flag value
-			if flag.delimiter != "" && strings.HasPrefix(flag.valueType, "[]") {
-				values := strings.Split(arg.value, flag.delimiter)
-				for _, v := range values {
-					// Ignore empty ones
-					v = strings.TrimSpace(v)
-					if v == "" {
-						continue
+			for _, value := range append([]string{arg.value}, arg.values...) {
+				if flag.delimiter != "" && strings.HasPrefix(flag.valueType, "[]") {
+					values := splitDelimited(value, flag.delimiter, flag.keepEmpty)
+					for _, v := range values {
+						if err := flagSet.setFlag(flag.id, v); err != nil {
+							arg.err = err
+						} else if err := flagSet.validateFlag(flag, v); err != nil {
+							arg.err = flagSet.errorf("argument %s%s %s", arg.dash, arg.name, err)
+						}
 					}
-					if err := flagSet.setFlag(flag.id, v); err != nil {
+				} else {
+					if err := flagSet.setFlag(flag.id, value); err != nil {
 						arg.err = err
+					} else if err := flagSet.validateFlag(flag, value); err != nil {
+						arg.err = flagSet.errorf("argument %s%s %s", arg.dash, arg.name, err)
 					}
 				}
-			} else {
-				if err := flagSet.setFlag(flag.id, arg.value); err != nil {
-					arg.err = err
-				}
 			}
 		}
 	}
 
 	// Iterate over the flags and update their values
+	// The config-file flags are resolved first since the config values depend on them.
 	for _, flag := range flagSet.flags {
-		if flag.kind != "arg" {
-			continue // only arguments
-		}
-
-		// Check the flag error
-		if flag.err != nil {
-			flagSet.unsetFlag(flag.id)
-			continue
-		}
-
-		if flag.valueBy == "arg" {
-			// Check the argument errors
-			for _, arg := range flag.args {
-				// If there is an argument error then
-				if arg.err != nil {
-					flagSet.unsetFlag(flag.id)
-				}
-			}
-			continue // skip the rest since argument overrides env and default values
-		}
-
-		if flag.env != "" {
-			if ev, ok := os.LookupEnv(flag.env); ok {
-				flag.valueBy = "env"
-				if err := flagSet.setFlag(flag.id, ev); err != nil {
-					flag.err = err
-				}
-				continue
-			}
-		}
-
-		if flag.valueDefault != "" {
-			flag.valueBy = "default"
-			if err := flagSet.setFlag(flag.id, flag.valueDefault); err != nil {
-				flag.err = err
-			}
-			continue
+		if flag.kind == "arg" && flag.configFile {
+			flagSet.resolveFlag(flag)
 		}
-
-		if flag.value == nil {
-			// Flag's value field is interface and it should not be nil
-			flagSet.unsetFlag(flag.id)
+	}
+	flagSet.loadConfig()
+	for _, flag := range flagSet.flags {
+		if flag.kind == "arg" && !flag.configFile {
+			flagSet.resolveFlag(flag)
 		}
 	}
 
@@ -181,11 +373,33 @@ func New(o Options) (*FlagSet, error) {
 		}
 
 		if flag.kind == "command" {
+			// If the parent command is not present then skip it
+			if flag.parentIndex != nil {
+				if parentFlag := flagSet.flagByIndex(flag.parentIndex); parentFlag != nil && parentFlag.args == nil {
+					continue
+				}
+			}
 			if flag.required && flag.args == nil { // command is not present
-				flag.err = fmt.Errorf("command %s is required", flag.command)
-			} else if flag.nonempty && len(flag.args) == 1 { // command is present
+				flag.err = flagSet.requiredError(flag, "")
+			} else if flag.required && flag.nonempty && flag.args != nil {
+				// Required commands those have subcommands need one of them
+				var names []string
+				found := false
+				for _, v := range flagSet.flags {
+					if v.kind == "command" && v.parentID == flag.id {
+						names = append(names, v.command)
+						if v.args != nil {
+							found = true
+						}
+					}
+				}
+				if !found && names != nil {
+					flag.err = flagSet.errorf("command %s requires a subcommand: %s", flag.command, strings.Join(names, ", "))
+				}
+			}
+			if flag.err == nil && flag.nonempty && len(flag.args) == 1 { // command is present
 				if len(flagSet.argsByCommandID(flag.commandID)) == 0 { // command itself has no any argument
-					flag.err = fmt.Errorf("command %s needs an argument", flag.command)
+					flag.err = flagSet.errorf("command %s needs an argument", flag.command)
 				}
 			}
 			continue
@@ -213,51 +427,369 @@ func New(o Options) (*FlagSet, error) {
 					}
 				}
 				if found {
-					flag.err = fmt.Errorf("argument %s needs a value", flag.FormattedArg())
+					flag.err = flagSet.errorf("argument %s needs a value", flag.FormattedArg())
 					continue
 				}
 			}
 
 			// Check requirement when the flag is not present
 			if flag.required && flag.args == nil {
-				// Skip error when the value is set by default value or env variables
-				if flag.valueBy == "default" || flag.valueBy == "env" {
+				// Skip error when the value is set by default value, env variables, secret files, keyring or config
+				if flag.valueBy == "default" || flag.valueBy == "env" || flag.valueBy == "secrets" || flag.valueBy == "keyring" || flag.valueBy == "config" {
 					continue
 				}
 				// Otherwise it's an error
-				e := fmt.Sprintf("argument %s is required", flag.FormattedArg())
-				if command != "" {
-					e = fmt.Sprintf("%s for %s command", e, command)
-				}
-				flag.err = errors.New(e)
+				flag.err = flagSet.requiredError(flag, command)
 				continue
 			}
 		}
 	}
 
+	// Iterate over the flags and check the repeated arguments
+	for _, flag := range flagSet.flags {
+		if flag.kind == "arg" && (flag.once || flagSet.repeatPolicy(flag) == "error") && len(flag.args) > 1 && flag.err == nil {
+			flag.err = flagSet.errorf("argument %s can't be repeated", flag.FormattedArg())
+		}
+	}
+
+	// Iterate over the flags and check the companion flags
+	for _, flag := range flagSet.flags {
+		// Only present arguments those require other flags
+		if flag.kind != "arg" || flag.args == nil || flag.requires == nil || flag.err != nil {
+			continue
+		}
+
+		for _, name := range flag.requires {
+			companion := flagSet.flagBySibling(flag, name)
+			if companion == nil {
+				continue // checked by checkFlags
+			}
+			// Companion flags set by env variables, secret files, keyring or config are considered as present
+			if companion.args == nil && companion.valueBy != "env" && companion.valueBy != "secrets" && companion.valueBy != "keyring" && companion.valueBy != "config" {
+				flag.err = flagSet.errorf("argument %s requires %s", flag.FormattedArg(), companion.FormattedArg())
+				break
+			}
+		}
+	}
+
 	// Iterate over the arguments and find the unknown arguments
 	for k, arg := range flagSet.args {
-		if k > 0 && arg.kind == "arg" && arg.flagID == -1 {
+		if k > 0 && arg.kind == "arg" && arg.flagID == -1 && !arg.terminated && !flagSet.partial {
 			if s := flagSet.settingByID(arg.settingsID); s == nil || !s.allowUnknownArg {
-				arg.err = fmt.Errorf("unknown argument: %s%s", arg.dash, arg.name)
+				arg.err = flagSet.unknownFlagError(arg.dash, arg.name, flagSet.suggestion(arg))
 			}
 		}
 	}
+	flagSet.syncBuilders()
 
-	return &flagSet, nil
+	// Write the warnings (see WithOutput)
+	if flagSet.output != nil {
+		for _, v := range flagSet.warnings {
+			fmt.Fprintln(flagSet.output, v)
+		}
+	}
+	return flagSet.Err()
+}
+
+// resolveFlag updates the value of the given flag by the value sources in order of precedence
+// (see Options.Precedence)
+func (flagSet *FlagSet) resolveFlag(flag *Flag) {
+	// Check the flag error
+	if flag.err != nil {
+		flagSet.unsetFlag(flag.id)
+		return
+	}
+
+	// Check the argument errors
+	if flag.valueBy == "arg" {
+		for _, arg := range flag.args {
+			// If there is an argument error then
+			if arg.err != nil {
+				flagSet.unsetFlag(flag.id)
+				return
+			}
+		}
+	}
+
+	// Iterate over the sources and stop at the first one those has a value
+	precedence := flagSet.precedence
+	if precedence == nil {
+		precedence = DefaultPrecedence
+	}
+	for _, source := range precedence {
+		switch source {
+		case "arg":
+			if flag.valueBy == "arg" {
+				return // skip the rest since argument overrides the next sources
+			}
+		case "env":
+			if flag.env == "" {
+				continue
+			}
+			if ev, ok := os.LookupEnv(flag.env); ok {
+				flagSet.setValueBy(flag, "env")
+				if err := flagSet.setFlag(flag.id, ev); err != nil {
+					flag.err = err
+				} else if err := flagSet.validateFlag(flag, ev); err != nil {
+					flag.err = flagSet.errorf("env variable %s %s", flag.env, err)
+				}
+				return
+			}
+		case "secrets":
+			path, ok := flagSet.secretFile(flag)
+			if !ok {
+				continue
+			}
+			flagSet.setValueBy(flag, "secrets")
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				flag.err = flagSet.errorf("failed to read the value of %s due to %s", flag.FormattedArg(), err.Error())
+				return
+			}
+			v := strings.TrimSpace(string(b))
+			if err := flagSet.setFlag(flag.id, v); err != nil {
+				flag.err = flagSet.errorf("secret file %s %s", path, err)
+			} else if err := flagSet.validateFlag(flag, v); err != nil {
+				flag.err = flagSet.errorf("secret file %s %s", path, err)
+			}
+			return
+		case "keyring":
+			if flag.keyring == "" {
+				continue
+			}
+			service, account, _ := parseKeyring(flag.keyring)
+			store := flagSet.secretStore
+			if store == nil {
+				store = Keyring{}
+			}
+			v, ok, err := store.Secret(service, account)
+			if err != nil {
+				flagSet.setValueBy(flag, "keyring")
+				flag.err = flagSet.errorf("failed to read keyring secret %s due to %s", flag.keyring, err.Error())
+				return
+			} else if !ok {
+				continue
+			}
+			flagSet.setValueBy(flag, "keyring")
+			if err := flagSet.setFlag(flag.id, v); err != nil {
+				flag.err = err
+			} else if err := flagSet.validateFlag(flag, v); err != nil {
+				flag.err = flagSet.errorf("keyring secret %s %s", flag.keyring, err)
+			}
+			return
+		case "config":
+			values, key, ok := flagSet.configValue(flag)
+			if !ok {
+				continue
+			}
+			flagSet.setValueBy(flag, "config")
+			// The errors name the config key and the file instead of the flag
+			path := flagSet.ConfigSource(key)
+			if values == nil || (len(values) != 1 && !strings.HasPrefix(flag.valueType, "[]")) {
+				flag.err = flagSet.errorf("config key %s in %s has an invalid value", key, path)
+				return
+			}
+			for _, v := range values {
+				if err := flagSet.setFlag(flag.id, v); err != nil {
+					flag.err = flagSet.errorf("config key %s in %s %s", key, path, err)
+					return
+				} else if err := flagSet.validateFlag(flag, v); err != nil {
+					flag.err = flagSet.errorf("config key %s in %s %s", key, path, err)
+					return
+				}
+			}
+			return
+		case "default":
+			v, ok := flag.valueDefault, flag.valueDefault != ""
+			if flag.defaultFrom != "" {
+				dv, dok, err := resolveDefaultFrom(flag.defaultFrom)
+				if err != nil {
+					flag.err = err
+					return
+				} else if dok {
+					v, ok = dv, true
+				}
+				// Otherwise fallback to the default value
+			}
+			flag.defaultValue, flag.defaultResolved = v, true
+			if !ok {
+				continue
+			}
+			flagSet.setValueBy(flag, "default")
+			if err := flagSet.setFlag(flag.id, v); err != nil {
+				flag.err = err
+			} else if err := flagSet.validateFlag(flag, v); err != nil {
+				flag.err = flagSet.errorf("default value of %s %s", flag.FormattedArg(), err)
+			}
+			return
+		}
+	}
+
+	if flag.value == nil {
+		// Flag's value field is interface and it should not be nil
+		flagSet.unsetFlag(flag.id)
+	}
+}
+
+// setValueBy updates the value source of the given flag. The argument values are cleared
+// when they are overridden by the given source (see Options.Precedence).
+func (flagSet *FlagSet) setValueBy(flag *Flag, source string) {
+	if flag.valueBy == "arg" {
+		flagSet.unsetFlag(flag.id)
+	}
+	flag.valueBy = source
+}
+
+// envPrefix returns the env prefixes of the parent commands of the given flag (see `env-prefix` tag)
+func (flagSet *FlagSet) envPrefix(flag *Flag) string {
+	result := ""
+	for pid := flag.parentID; pid > -1; {
+		parent := flagSet.flagByID(pid)
+		if parent == nil {
+			break
+		}
+		result = parent.envPrefix + result
+		pid = parent.parentID
+	}
+	return result
+}
+
+// Reset clears the parsed arguments, the errors, the warnings, the loaded config and the value sources,
+// and restores the flag values to their zero values so the flag set and its struct can be parsed again
+// (i.e. in a REPL). The default values are applied by the next parse.
+func (flagSet *FlagSet) Reset() {
+	flagSet.args = nil
+	flagSet.argsOrig = nil
+	flagSet.argsRaw = nil
+	flagSet.argsIndex = nil
+	flagSet.argsParsed = false
+	flagSet.commands = nil
+	flagSet.commandsParsed = false
+	flagSet.settingsParsed = false
+	flagSet.terminator = 0
+	flagSet.warnings = nil
+	flagSet.config = nil
+	flagSet.configSources = nil
+	flagSet.configPaths = nil
+	flagSet.configErr = nil
+	for _, flag := range flagSet.flags {
+		flag.args = nil
+		flag.err = nil
+		flag.valueBy = ""
+		flag.updatedBy = nil
+		flag.defaultValue, flag.defaultResolved = "", false
+		if flag.kind != "command" {
+			flag.commandID = -1
+		}
+		if flag.kind == "arg" || flag.kind == "pos" {
+			flagSet.unsetFlag(flag.id)
+			flag.value = nil
+		}
+	}
+	flagSet.syncBuilders()
+}
+
+// Clone returns a new flag set those has the same options and the flag definitions but its own zero copy
+// of the flags (see ZeroFlags) and parses the arguments of the last parse into it. The typed pointers of
+// the builders are not updated by the clone so it can be used by another goroutine (i.e. for reloading
+// the config while the flags are in use, see ReloadConfig).
+func (flagSet *FlagSet) Clone() (*FlagSet, error) {
+	target, err := ZeroFlags(flagSet.Target())
+	if err != nil {
+		return nil, err
+	}
+	flags, errs := structToFlags(target)
+	if errs != nil {
+		return nil, MultiError(errs)
+	}
+	for _, flag := range flags {
+		if f := flagSet.flagByID(flag.id); f != nil {
+			flag.env = f.env // by the options (i.e. EnvPrefix)
+		}
+	}
+
+	clone := *flagSet
+	clone.flags, clone.flagsRaw, clone.builders = flags, target, nil
+	clone.settings, clone.settingsParsed = nil, false
+	clone.output = nil // the warnings are already written
+	args := flagSet.argsOrig
+	if args == nil {
+		args = []string{}
+	}
+	clone.Parse(args) // the parse errors are the same as the flag set's (see Err method)
+	return &clone, nil
+}
+
+// ParseString splits the given command line into arguments (see SplitArgs) and parses them
+func (flagSet *FlagSet) ParseString(s string) error {
+	args, err := SplitArgs(s)
+	if err != nil {
+		return err
+	}
+	if args == nil {
+		args = []string{} // not os.Args
+	}
+	return flagSet.Parse(args)
 }
 
 // FlagSet represents a flag set
 type FlagSet struct {
 	flags          []*Flag
 	flagsRaw       interface{}
+	builders       []*Builder // the builders of the flags by their positions (see Builder)
+	output         io.Writer  // writer of the warnings (see WithOutput)
 	args           []*Arg
 	argsRaw        []string
+	argsOrig       []string // before splitting the combined short arguments
+	argsIndex      []int    // original indexes of the raw arguments
 	argsParsed     bool
 	commands       []*Command
 	commandsParsed bool
 	settings       []*Setting
 	settingsParsed bool
+	// caseInsensitive matches the long arguments regardless of the case
+	caseInsensitive bool
+	// ordered stops parsing at the first unnamed argument
+	ordered bool
+	// partial collects the unknown arguments instead of failing
+	partial bool
+	// repeat is the default policy for the repeated arguments
+	repeat string
+	// lenientNumbers accepts the separators and the scientific notation for the numeric arguments
+	lenientNumbers bool
+	// chain allows the sibling commands in one invocation
+	chain bool
+	// terminator is the index of the end-of-flags terminator or the first unnamed argument
+	// in ordered mode (0 for none)
+	terminator int
+	// warnings are the messages for the deprecated commands those are present
+	warnings []string
+	// localizer translates the parse errors and the warnings
+	localizer Localizer
+	// configFile is the path of the config file by the options
+	configFile string
+	// configFiles are the paths of the config files by the options
+	configFiles []string
+	// configSearch are the search paths of the config file
+	configSearch []string
+	// configSource reads the config files those have a URL
+	configSource ConfigSource
+	// expandConfig expands the env variables in the config values
+	expandConfig bool
+	// secretStore is the store of the secrets for the keyring tags
+	secretStore SecretStore
+	// secretsDir is the directory of the secret files
+	secretsDir string
+	// precedence is the order of the value sources
+	precedence []string
+	// configPaths are the paths of the loaded config files
+	configPaths []string
+	// config holds the merged values of the loaded config files
+	config map[string]interface{}
+	// configSources are the paths of the config files by the key paths of the values
+	configSources map[string]string
+	// configErr is the error of the config file
+	configErr error
 }
 
 // parseSettings parses the flags and update the settings
@@ -350,61 +882,498 @@ func (flagSet *FlagSet) argsByCommandID(id int) []*Arg {
 		return nil
 	}
 
-	// Iterate over the arguments and prepare result
-	var result []*Arg
-	found := false
-	for _, v := range flagSet.args {
-		// Check whether the argument is the given command
-		if v.kind == "command" && v.commandID == id {
-			found = true
-			continue // skip
+	// Iterate over the arguments and prepare result
+	var result []*Arg
+	found := false
+	for _, v := range flagSet.args {
+		// Check whether the argument is the given command
+		if v.kind == "command" && v.commandID == id {
+			found = true
+			continue // skip
+		}
+		// If it's found then
+		if found {
+			// If the argument is global then
+			if f := flagSet.flagByID(v.flagID); f != nil && f.global {
+				continue // skip
+			}
+			// Otherwise add it into result
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// flagByID returns a flag by the given id or returns nil if it doesn't exist
+func (flagSet *FlagSet) flagByID(id int) *Flag {
+	if id < 0 {
+		return nil
+	}
+	for _, v := range flagSet.flags {
+		if v.id == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// flagByIndex returns a flag by the given field index or returns nil if it doesn't exist
+func (flagSet *FlagSet) flagByIndex(index []int) *Flag {
+	if index == nil {
+		return nil
+	}
+	for _, v := range flagSet.flags {
+		if fmt.Sprint(v.fieldIndex) == fmt.Sprint(index) { // faster then reflect.DeepEqual
+			return v
+		}
+	}
+	return nil
+}
+
+// argMatches returns whether the given argument name matches the short or long argument of the flag
+// Short arguments are always case sensitive (i.e. `-v` and `-V` are different arguments)
+func (flagSet *FlagSet) argMatches(flag *Flag, name string) bool {
+	if flag == nil || name == "" {
+		return false
+	}
+	if flag.short == name || flag.long == name {
+		return true
+	}
+	return flagSet.caseInsensitive && flag.long != "" && strings.EqualFold(flag.long, name)
+}
+
+// argFlag returns the argument flag those matches the given argument in its command scope
+// or in the parent scopes (i.e. `app deploy --verbose`) or returns nil if it doesn't exist
+func (flagSet *FlagSet) argFlag(arg *Arg) *Flag {
+	if arg == nil || arg.name == "" {
+		return nil
+	}
+
+	// Check the command of the argument and its parent commands up to the top level
+	parentIDs := []int{}
+	for c := flagSet.commandByID(arg.commandID); c != nil; c = flagSet.commandByID(c.parentID) {
+		parentIDs = append(parentIDs, c.flagID)
+	}
+	parentIDs = append(parentIDs, -1)
+
+	// Iterate over the scopes from the innermost one
+	for _, parentID := range parentIDs {
+		for _, v := range flagSet.flags {
+			if v.kind == "arg" && v.parentID == parentID && flagSet.argMatches(v, arg.name) {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// repeatPolicy returns the policy of the given flag for the repeated arguments
+func (flagSet *FlagSet) repeatPolicy(flag *Flag) string {
+	if flag == nil {
+		return ""
+	}
+	slice := strings.HasPrefix(flag.valueType, "[]") && flag.arrayLen == 0 // arrays are replaced as a whole
+	policy := flag.repeat
+	if policy == "" {
+		policy = flagSet.repeat
+	}
+	if policy == "" || (policy == "append" && !slice) {
+		if slice {
+			return "append"
+		}
+		return "last"
+	}
+	return policy
+}
+
+// suggestion returns the closest argument or command name for the given unknown argument
+// (i.e. `--verbose` for `--verbos`) or returns an empty string if there is no close one
+func (flagSet *FlagSet) suggestion(arg *Arg) string {
+	if arg == nil || arg.name == "" {
+		return ""
+	}
+
+	// Check the command of the argument
+	parentID := -1
+	if c := flagSet.commandByID(arg.commandID); c != nil {
+		parentID = c.flagID
+	}
+
+	// Unnamed arguments are checked against the commands (i.e. `bar or baz` for `ba`)
+	if arg.unnamed {
+		return strings.Join(flagSet.commandSuggestions(arg.name, parentID), " or ")
+	}
+
+	// Iterate over the flags in the same scope and find the closest one
+	result, min := "", -1
+	name := arg.name
+	if i := strings.Index(name, "="); i > -1 {
+		name = name[:i] // for example `--verbos=true`
+	}
+	for _, v := range flagSet.flags {
+		if v.kind != "arg" || v.long == "" || (v.parentID != parentID && (v.parentID != -1 || !v.global)) {
+			continue
+		}
+		d := levenshtein(name, v.long)
+		if d <= 2 && d < len(name) && (min == -1 || d < min) {
+			min = d
+			result = "--" + v.long
+		}
+	}
+	return result
+}
+
+// commandSuggestions returns the closest command names for the given name in the given parent scope
+func (flagSet *FlagSet) commandSuggestions(name string, parentID int) []string {
+	var result []string
+	min := -1
+	for _, v := range flagSet.flags {
+		if v.kind != "command" || v.parentID != parentID {
+			continue
+		}
+		d := levenshtein(name, v.command)
+		if d > 2 || d >= len(name) || (min != -1 && d > min) {
+			continue
+		} else if d < min || min == -1 {
+			result = nil
+			min = d
+		}
+		result = append(result, v.command)
+	}
+	return result
+}
+
+// flagBySibling returns a sibling argument (same parent) of the given flag by the given name
+// or returns nil if it doesn't exist
+func (flagSet *FlagSet) flagBySibling(flag *Flag, name string) *Flag {
+	if flag == nil || name == "" {
+		return nil
+	}
+	for _, v := range flagSet.flags {
+		if v.parentID == flag.parentID && v.name == name && v.kind == "arg" {
+			return v
+		}
+	}
+	return nil
+}
+
+// flagByShort returns an argument flag of the given command or its parent commands by the given
+// short argument or returns nil if it doesn't exist (nil command is the top level)
+func (flagSet *FlagSet) flagByShort(short string, command *Flag) *Flag {
+	if short == "" {
+		return nil
+	}
+	parentID := -1
+	if command != nil {
+		parentID = command.id
+	}
+	for {
+		for _, v := range flagSet.flags {
+			if v.kind == "arg" && v.short == short && v.parentID == parentID {
+				return v
+			}
+		}
+		parent := flagSet.flagByID(parentID)
+		if parent == nil {
+			return nil
+		}
+		parentID = parent.parentID
+	}
+}
+
+// childCommand returns a child command of the given command by the given name or returns nil
+// if it doesn't exist (nil command is the top level)
+func (flagSet *FlagSet) childCommand(command *Flag, name string) *Flag {
+	parentID := -1
+	if command != nil {
+		parentID = command.id
+	}
+	for _, v := range flagSet.flags {
+		if v.kind == "command" && v.command == name && v.parentID == parentID {
+			return v
+		}
+	}
+	return nil
+}
+
+// splitShortArgs returns the given raw arguments by splitting the combined short arguments
+// (i.e. `-abc` to `-a -b -c`) and the attached values (i.e. `-ofile` to `-o=file`).
+// All the combined arguments except the last one must be bool and the arguments those
+// match an argument as a whole are kept as is. It also returns the original indexes of the arguments.
+func (flagSet *FlagSet) splitShortArgs(args []string) ([]string, []int) {
+	result := make([]string, 0, len(args))
+	index := make([]int, 0, len(args))
+	terminator := flagSet.terminatorIndex(args)
+	var command *Flag // flag of the last command
+	for k, arg := range args {
+		if k > 0 && k == terminator {
+			result = append(result, args[k:]...) // keep the rest after the terminator
+			for i := k; i < len(args); i++ {
+				index = append(index, i)
+			}
+			break
+		}
+		if k > 0 && !strings.HasPrefix(arg, "-") {
+			if v := flagSet.childCommand(command, arg); v != nil {
+				command = v
+			}
+		}
+		var split []string
+		if k > 0 {
+			split = flagSet.splitShortArg(arg, command)
+		}
+		if split == nil {
+			result = append(result, arg)
+			index = append(index, k)
+			continue
+		}
+		result = append(result, split...)
+		for range split {
+			index = append(index, k)
+		}
+	}
+	return result, index
+}
+
+// splitShortArg returns the given combined short argument by splitting it (i.e. [-a -b -f=file] for `-abffile`)
+// or returns nil if it's not a combined short argument of the given command or its parent commands
+func (flagSet *FlagSet) splitShortArg(arg string, command *Flag) []string {
+	if len(arg) < 3 || arg[0] != '-' || arg[1] == '-' {
+		return nil
+	}
+
+	// Check the name (i.e. `-abf=file`)
+	name := arg[1:]
+	if i := strings.Index(name, "="); i > -1 {
+		name = name[:i]
+	}
+	if utf8.RuneCountInString(name) < 2 {
+		return nil
+	}
+	for _, v := range flagSet.flags {
+		if v.kind == "arg" && flagSet.argMatches(v, name) {
+			return nil
+		}
+	}
+
+	// Iterate over the characters and check the flags
+	var result []string
+	rest := arg[1:]
+	for rest != "" {
+		if rest[0] == '=' {
+			result[len(result)-1] += rest // value of the last argument
+			break
+		}
+		c, size := utf8.DecodeRuneInString(rest)
+		rest = rest[size:]
+		f := flagSet.flagByShort(string(c), command)
+		if f == nil {
+			return nil
+		}
+		result = append(result, "-"+string(c))
+		if f.valueType != "bool" && f.valueType != "[]bool" {
+			// The rest is the value (i.e. `-n5`, `-abffile`)
+			if rest != "" && rest[0] != '=' {
+				rest = "=" + rest
+			}
+			result[len(result)-1] += rest
+			break
+		}
+	}
+	return result
+}
+
+// redirectCommand replaces the first present deprecated command those has a redirect with its replacement
+// in the raw arguments (i.e. `app old` becomes `app sync`). It returns whether it's replaced or not.
+// Commands must be parsed before and after calling it.
+func (flagSet *FlagSet) redirectCommand() bool {
+	for _, cmd := range flagSet.commands {
+		flag := flagSet.flagByID(cmd.flagID)
+		if cmd.argID == -1 || flag == nil || flag.redirect == "" {
+			continue
+		}
+		flagSet.argsRaw[cmd.argID] = flag.redirect
+		flagSet.warnings = append(flagSet.warnings, flagSet.deprecationWarning(flag))
+		return true
+	}
+	return false
+}
+
+// insertDefaultCommand inserts the default command into the raw arguments when none of the commands
+// of its level is present (i.e. `app -f` becomes `app serve -f`). It returns whether it's inserted or not.
+// Commands must be parsed before and after calling it.
+func (flagSet *FlagSet) insertDefaultCommand() bool {
+	for _, flag := range flagSet.flags {
+		if !flag.defaultCommand {
+			continue
+		}
+
+		// Check the parent command (top level commands are inserted after the program name)
+		index := 1
+		if flag.parentID != -1 {
+			parentFlag := flagSet.flagByID(flag.parentID)
+			if parentFlag == nil {
+				continue
+			}
+			parent := flagSet.commandByID(parentFlag.commandID)
+			if parent == nil || parent.argID == -1 {
+				continue // parent command is not present
+			}
+			index = parent.argID + 1
+		}
+
+		// Check the commands of the same level
+		found := false
+		for _, v := range flagSet.flags {
+			if v.kind == "command" && v.parentID == flag.parentID {
+				if c := flagSet.commandByID(v.commandID); c != nil && c.argID != -1 {
+					found = true
+					break
+				}
+			}
+		}
+		if found || index > len(flagSet.argsRaw) {
+			continue
+		}
+
+		// Insert the command
+		args := make([]string, 0, len(flagSet.argsRaw)+1)
+		args = append(args, flagSet.argsRaw[:index]...)
+		args = append(args, flag.command)
+		flagSet.argsRaw = append(args, flagSet.argsRaw[index:]...)
+		argsIndex := make([]int, 0, len(flagSet.argsIndex)+1)
+		argsIndex = append(argsIndex, flagSet.argsIndex[:index]...)
+		argsIndex = append(argsIndex, flagSet.argsIndex[index-1])
+		flagSet.argsIndex = append(argsIndex, flagSet.argsIndex[index:]...)
+		return true
+	}
+	return false
+}
+
+// terminatorIndex returns the index of the end-of-flags terminator (`--`) in the given raw arguments.
+// In ordered mode (see Options.Ordered and `ordered` command tag) it returns the index of the first unnamed argument (i.e. `bar` for `app -f foo bar -b`)
+// unless the terminator comes first. It returns 0 if there is none.
+func (flagSet *FlagSet) terminatorIndex(args []string) int {
+	var flag *Flag    // flag of the last argument
+	var command *Flag // flag of the last command
+	values := 0       // number of the following values of the last argument
+	for k, arg := range args {
+		if k == 0 {
+			continue
+		} else if arg == "--" {
+			return k
+		}
+
+		// Skip the argument values (i.e. `foo` for `-f foo`)
+		if values != 0 && isArgValue(flag, arg) {
+			values--
+			continue
+		}
+		values = 0
+
+		if strings.HasPrefix(arg, "-") {
+			flag, values = flagSet.valueCount(arg, command)
+			continue
+		}
+
+		// Skip the child commands of the last command
+		if v := flagSet.childCommand(command, arg); v != nil {
+			command = v
+			continue
 		}
-		// If it's found then
-		if found {
-			// If the argument is global then
-			if f := flagSet.flagByID(v.flagID); f != nil && f.global {
-				continue // skip
-			}
-			// Otherwise add it into result
-			result = append(result, v)
+		if flagSet.ordered || (command != nil && command.ordered) {
+			return k
 		}
 	}
-	return result
+	return 0
 }
 
-// flagByID returns a flag by the given id or returns nil if it doesn't exist
-func (flagSet *FlagSet) flagByID(id int) *Flag {
-	if id < 0 {
-		return nil
+// valueCount returns the flag and the number of the following raw arguments those the given raw
+// argument takes as values or -1 for as many as possible (i.e. `nargs:"+"`). Bool arguments take
+// no values in ordered mode (i.e. `-b=false` must be used instead of `-b false`). Combined short
+// arguments of the given command take the values of their last argument (i.e. `-abf file`).
+func (flagSet *FlagSet) valueCount(arg string, command *Flag) (*Flag, int) {
+	name := strings.TrimLeft(arg, "-")
+	hasEq := strings.Contains(name, "=")
+	if hasEq {
+		name = name[:strings.Index(name, "=")]
 	}
+
+	// Find the flag
+	var flag *Flag
 	for _, v := range flagSet.flags {
-		if v.id == id {
-			return v
+		if v.kind == "arg" && flagSet.argMatches(v, name) {
+			flag = v
+			break
+		}
+	}
+	if flag == nil {
+		if split := flagSet.splitShortArg(arg, command); split != nil {
+			return flagSet.valueCount(split[len(split)-1], command)
 		}
 	}
-	return nil
-}
 
-// flagByIndex returns a flag by the given field index or returns nil if it doesn't exist
-func (flagSet *FlagSet) flagByIndex(index []int) *Flag {
-	if index == nil {
-		return nil
+	result := 1
+	if hasEq || (flag != nil && (flag.valueType == "bool" || flag.valueType == "[]bool")) {
+		result = 0
 	}
-	for _, v := range flagSet.flags {
-		if fmt.Sprint(v.fieldIndex) == fmt.Sprint(index) { // faster then reflect.DeepEqual
-			return v
+	if flag != nil && flag.nargs != "" {
+		if n, err := strconv.Atoi(flag.nargs); err == nil {
+			result += n - 1
+		} else {
+			result = -1
 		}
 	}
-	return nil
+	return flag, result
 }
 
 // FlagByName returns a flag by the given name or returns nil if it doesn't exist
-// Nested flags are separated by dot (i.e. Foo.Bar)
+// Nested flags are separated by dot (i.e. Foo.Bar). When there is no field by the name,
+// the flag is looked up by the long and the short argument names (see FlagByLong and FlagByShort)
 func (flagSet *FlagSet) FlagByName(name string) *Flag {
 	if name == "" {
 		return nil
 	}
+	if flag := flagSet.flagByFieldName(name); flag != nil {
+		return flag
+	} else if flag := flagSet.FlagByLong(name); flag != nil {
+		return flag
+	}
+	return flagSet.FlagByShort(name)
+}
+
+// FlagByShort returns an argument flag by the given short argument name (i.e. `v` or `-v`) or returns
+// nil if it doesn't exist. The top level flags take precedence over the command flags.
+func (flagSet *FlagSet) FlagByShort(short string) *Flag {
+	return flagSet.flagByArgName(strings.TrimPrefix(short, "-"), func(flag *Flag) string { return flag.short })
+}
+
+// FlagByLong returns an argument flag by the given long argument name (i.e. `verbose` or `--verbose`) or
+// returns nil if it doesn't exist. The top level flags take precedence over the command flags.
+func (flagSet *FlagSet) FlagByLong(long string) *Flag {
+	return flagSet.flagByArgName(strings.TrimPrefix(long, "--"), func(flag *Flag) string { return flag.long })
+}
+
+// flagByArgName returns the argument flag those argument name (by the given function) matches the given name
+func (flagSet *FlagSet) flagByArgName(name string, argName func(*Flag) string) *Flag {
+	if name == "" {
+		return nil
+	}
+	var result *Flag
+	for _, flag := range flagSet.flags {
+		if flag.kind != "arg" || argName(flag) != name {
+			continue
+		} else if flag.parentID == -1 {
+			return flag
+		} else if result == nil {
+			result = flag
+		}
+	}
+	return result
+}
 
+// flagByFieldName returns a flag by the given field name or returns nil if it doesn't exist
+func (flagSet *FlagSet) flagByFieldName(name string) *Flag {
 	// Init vars
 	var result *Flag
 	names := strings.Split(name, ".")
@@ -451,7 +1420,7 @@ func (flagSet *FlagSet) FlagByArg(arg, command string) *Flag {
 
 	// Iterate over the flags
 	for _, v := range flagSet.flags {
-		if v.kind == "arg" && v.parentID == parentID && (v.short == arg || v.long == arg) {
+		if v.kind == "arg" && v.parentID == parentID && flagSet.argMatches(v, arg) {
 			result = v
 			break
 		}
@@ -481,6 +1450,9 @@ func (flagSet *FlagSet) FlagArgs(name string) []string {
 	for _, v := range flag.args {
 		if flag.kind == "arg" {
 			result = append(result, v.value)
+			result = append(result, v.values...)
+		} else if flag.kind == "pos" {
+			result = append(result, v.arg)
 		} else if flag.kind == "command" {
 			// Note that argument values ("argval") are coupled with their parent arguments hence
 			// they are not added into the flag arguments (see parseArgs method).
@@ -501,21 +1473,303 @@ func (flagSet *FlagSet) FlagArgs(name string) []string {
 					arg = v.name
 				}
 				result = append(result, arg)
+				result = append(result, v.values...)
+			}
+		}
+	}
+
+	return result
+}
+
+// ActiveCommand returns the deepest command flag those present in the arguments
+// (i.e. Foo.Bar for `app foo bar`) or returns nil if there is no command
+func (flagSet *FlagSet) ActiveCommand() *Flag {
+	var result *Flag
+	for _, flag := range flagSet.flags {
+		if flag.kind == "command" && flag.args != nil && (result == nil || len(flag.fieldIndex) >= len(result.fieldIndex)) {
+			result = flag
+		}
+	}
+	return result
+}
+
+// ChainedCommands returns the present commands those have no present subcommand in the order
+// of the arguments (i.e. [build, test] for `app build -v test`). See Options.Chain
+func (flagSet *FlagSet) ChainedCommands() []*Flag {
+	var cmds []*Command
+	for _, cmd := range flagSet.commands {
+		if cmd.argID == -1 {
+			continue
+		}
+		leaf := true
+		for _, c := range flagSet.commands {
+			if c.argID != -1 && c.parentID == cmd.id {
+				leaf = false
+				break
+			}
+		}
+		if leaf {
+			cmds = append(cmds, cmd)
+		}
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].argID < cmds[j].argID })
+
+	var result []*Flag
+	for _, cmd := range cmds {
+		if flag := flagSet.flagByID(cmd.flagID); flag != nil {
+			result = append(result, flag)
+		}
+	}
+	return result
+}
+
+// OriginalArgs returns the parsed arguments as they are without the program name (i.e. [-fx bar] for `app -fx bar`)
+func (flagSet *FlagSet) OriginalArgs() []string {
+	if len(flagSet.argsOrig) < 2 {
+		return nil
+	}
+	result := make([]string, len(flagSet.argsOrig)-1)
+	copy(result, flagSet.argsOrig[1:])
+	return result
+}
+
+// RedactedArgs returns the original arguments without the program name those the values of the secret flags
+// are replaced by SecretPlaceholder (i.e. [--token=*** -v] for `app --token=foo -v` or [-t *** -v] for `app -t foo -v`)
+func (flagSet *FlagSet) RedactedArgs() []string {
+	result := flagSet.OriginalArgs()
+	for _, arg := range flagSet.args {
+		if arg.terminated || arg.id <= 0 || arg.id >= len(flagSet.argsIndex) {
+			continue
+		}
+		i := flagSet.argsIndex[arg.id] - 1 // index in the result
+		if i < 0 || i >= len(result) {
+			continue
+		}
+		if arg.kind == "argval" && arg.parentID > 0 && arg.parentID < len(flagSet.args) {
+			if flag := flagSet.flagByID(flagSet.args[arg.parentID].flagID); flag != nil && flag.secret {
+				result[i] = SecretPlaceholder
+			}
+		} else if arg.kind == "arg" && arg.hasEq && !arg.unset {
+			if flag := flagSet.flagByID(arg.flagID); flag != nil && flag.secret {
+				// The value is the suffix of the original argument (i.e. `--token=foo` or `-vtfoo`)
+				value := arg.arg[strings.Index(arg.arg, "=")+1:]
+				if strings.HasSuffix(result[i], value) {
+					result[i] = result[i][:len(result[i])-len(value)] + SecretPlaceholder
+				} else {
+					result[i] = SecretPlaceholder
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Args returns the parsed arguments without the program name (see Arg type)
+func (flagSet *FlagSet) Args() []*Arg {
+	var result []*Arg
+	for k, arg := range flagSet.args {
+		if k > 0 {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+// Unnamed returns the unnamed (positional) arguments those are not a command or a value of
+// another argument (i.e. [bar baz] for `app -f foo bar -- baz` when foo is the value of -f)
+func (flagSet *FlagSet) Unnamed() []*Arg {
+	var result []*Arg
+	for k, arg := range flagSet.args {
+		if k > 0 && arg.kind == "arg" && arg.unnamed {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+// PassthroughArgs returns the arguments after the end-of-flags terminator
+// (i.e. [-f bar] for `app foo -- -f bar`)
+func (flagSet *FlagSet) PassthroughArgs() []string {
+	var result []string
+	for _, arg := range flagSet.args {
+		if arg.terminated {
+			result = append(result, arg.arg)
+		}
+	}
+	return result
+}
+
+// RawArgs returns the original arguments of the given command as they are (i.e. [-abc 'd e'] for `app foo -abc 'd e'`)
+// Top level arguments are returned for the empty name. Nested commands are separated by dot (i.e. Foo.Bar)
+func (flagSet *FlagSet) RawArgs(name string) []string {
+	from, to := 1, len(flagSet.argsRaw)
+	if name != "" {
+		flag := flagSet.FlagByName(name)
+		if flag == nil || flag.kind != "command" {
+			return nil
+		}
+		cmd := flagSet.commandByID(flag.commandID)
+		if cmd == nil || cmd.argID == -1 {
+			return nil
+		}
+		from, to = cmd.indexFrom+1, cmd.indexTo
+	} else {
+		// Until the first command
+		for _, cmd := range flagSet.commands {
+			if cmd.argID != -1 && cmd.indexFrom < to {
+				to = cmd.indexFrom
+			}
+		}
+	}
+	if from >= to || to > len(flagSet.argsIndex) {
+		return nil
+	}
+
+	// Original arguments
+	from, to = flagSet.argsIndex[from], flagSet.argsIndex[to-1]+1
+	result := make([]string, to-from)
+	copy(result, flagSet.argsOrig[from:to])
+	return result
+}
+
+// Remaining returns the unknown arguments and their values as they are in partial mode
+// (i.e. [--foo bar baz] for `app --foo bar baz -v`)
+func (flagSet *FlagSet) Remaining() []string {
+	if !flagSet.partial {
+		return nil
+	}
+	var result []string
+	for k, arg := range flagSet.args {
+		if k > 0 && arg.kind == "arg" && arg.flagID == -1 && !arg.terminated {
+			result = append(result, arg.arg)
+			for _, v := range flagSet.args {
+				if v.kind == "argval" && v.parentID == arg.id {
+					result = append(result, v.arg)
+				}
 			}
 		}
 	}
+	return result
+}
+
+// Unknown returns the dashed arguments those match no flag as they are (i.e. [--foo=bar -x] for
+// `app --foo=bar -x baz`) regardless of the partial mode and the allow-unknown-arg settings
+func (flagSet *FlagSet) Unknown() []string {
+	var result []string
+	for k, arg := range flagSet.args {
+		if k > 0 && arg.kind == "arg" && arg.dash != "" && arg.flagID == -1 && !arg.terminated {
+			result = append(result, arg.arg)
+		}
+	}
+	return result
+}
 
+// Unparsed returns the arguments those are consumed by no flag, command or positional field as they
+// are (i.e. [--foo bar baz] for `app --foo bar baz` when there is no foo flag and no positional field).
+// The arguments after the end-of-flags terminator are not included (see PassthroughArgs).
+func (flagSet *FlagSet) Unparsed() []string {
+	var result []string
+	for k, arg := range flagSet.args {
+		if k == 0 || arg.terminated {
+			continue
+		}
+		if arg.kind == "arg" && arg.flagID == -1 {
+			result = append(result, arg.arg)
+		} else if arg.kind == "argval" && arg.parentID > 0 && arg.parentID < len(flagSet.args) {
+			// Values of the unknown arguments (i.e. `bar` for `--foo bar`)
+			if parent := flagSet.args[arg.parentID]; parent.kind == "arg" && parent.flagID == -1 {
+				result = append(result, arg.arg)
+			}
+		}
+	}
 	return result
 }
 
+// UnknownCommand returns the first unknown argument those is in a command position and its parent
+// command flag (nil for top level) or returns an empty string if there is none (i.e. `sqr` for `app math sqr`)
+func (flagSet *FlagSet) UnknownCommand() (string, *Flag) {
+	for k, arg := range flagSet.args {
+		if k == 0 || arg.kind != "arg" || !arg.unnamed || arg.terminated || arg.flagID != -1 || arg.err == nil {
+			continue
+		}
+		parentID := -1
+		if c := flagSet.commandByID(arg.commandID); c != nil {
+			parentID = c.flagID
+		}
+		for _, v := range flagSet.flags {
+			if v.kind == "command" && v.parentID == parentID {
+				return arg.arg, flagSet.flagByID(parentID)
+			}
+		}
+	}
+	return "", nil
+}
+
+// CommandSuggestions returns the closest command names of the given parent command (nil for top level)
+// for the given name (i.e. [bar baz] for `ba`)
+func (flagSet *FlagSet) CommandSuggestions(name string, parent *Flag) []string {
+	parentID := -1
+	if parent != nil {
+		parentID = parent.id
+	}
+	return flagSet.commandSuggestions(name, parentID)
+}
+
+// Warnings returns the warnings for the deprecated commands those are present
+// (i.e. `command old is deprecated: renamed to sync`)
+func (flagSet *FlagSet) Warnings() []string {
+	return flagSet.warnings
+}
+
 // Flags returns the flags
 func (flagSet *FlagSet) Flags() []*Flag {
 	return flagSet.flags
 }
 
+// Target returns the flags those the values are applied to. It's the Flags option except the builders
+// those are replaced by their generated struct pointers (see Builder).
+func (flagSet *FlagSet) Target() interface{} {
+	return flagSet.flagsRaw
+}
+
+// IsSet returns whether the value of the flag by the given name is provided explicitly or not
+// (see FlagByName and Flag.Changed)
+func (flagSet *FlagSet) IsSet(name string) bool {
+	flag := flagSet.FlagByName(name)
+	return flag != nil && flag.Changed()
+}
+
+// Visit calls the given function for each flag those is set by a value source other than the
+// default value (i.e. arg, env or config) in the declaration order
+func (flagSet *FlagSet) Visit(fn func(*Flag)) {
+	for _, flag := range flagSet.flags {
+		if flag.Changed() {
+			fn(flag)
+		}
+	}
+}
+
+// VisitAll calls the given function for each flag (including the commands) in the declaration order
+func (flagSet *FlagSet) VisitAll(fn func(*Flag)) {
+	for _, flag := range flagSet.flags {
+		fn(flag)
+	}
+}
+
+// Err returns the flag and argument errors as a single error (see MultiError) or nil if there is none
+func (flagSet *FlagSet) Err() error {
+	if errs := flagSet.Errors(); errs != nil {
+		return MultiError(errs)
+	}
+	return nil
+}
+
 // Errors returns the flag and argument errors
 func (flagSet *FlagSet) Errors() []error {
 	var result []error
+	if flagSet.configErr != nil {
+		result = append(result, flagSet.configErr)
+	}
 	for _, flag := range flagSet.flags {
 		if flag.err != nil {
 			result = append(result, flag.err)
@@ -580,7 +1834,11 @@ func (flagSet *FlagSet) parseCommands() {
 
 	// Iterate over the raw arguments and update commands
 	lenCmds := len(flagSet.commands)
+	var lastCmd *Command // last command those is found in the arguments
 	for argIndex, argVal := range flagSet.argsRaw {
+		if flagSet.terminator > 0 && argIndex >= flagSet.terminator {
+			break // no more commands after the terminator
+		}
 		for i := 0; i < lenCmds; i++ {
 			cmd := flagSet.commands[i]
 			// Checking argID prevents issues when a nested command has same name as parent command (i.e. `app foo -b foo -b`)
@@ -588,12 +1846,14 @@ func (flagSet *FlagSet) parseCommands() {
 				found := false
 				// If it's a nested command then
 				if cmd.parentID != -1 {
-					// Make sure it's after the parent command
-					for j := 0; j < lenCmds; j++ {
-						parentArgID := flagSet.commands[j].argID
-						if parentArgID != -1 && parentArgID < argIndex {
-							found = true
-							break
+					// Make sure it's in the scope of its parent commands (i.e. not `app bar foo` if foo belongs to baz)
+					// One of the parent commands must be the last command or one of its parents
+					for c := lastCmd; c != nil && !found; c = flagSet.commandByID(c.parentID) {
+						for p := flagSet.commandByID(cmd.parentID); p != nil; p = flagSet.commandByID(p.parentID) {
+							if c.id == p.id {
+								found = true
+								break
+							}
 						}
 					}
 				} else {
@@ -601,16 +1861,10 @@ func (flagSet *FlagSet) parseCommands() {
 				}
 
 				if found == true {
+					lastCmd = cmd
 					cmd.indexFrom = argIndex
 					cmd.argID = argIndex
 					cmd.updatedBy = append(cmd.updatedBy, "found in the arguments")
-					// If the previous command is found in the arguments then
-					if i > 0 && flagSet.commands[i-1].argID != -1 {
-						// Update the previous command
-						prevCmd := flagSet.commands[i-1]
-						prevCmd.indexTo = argIndex
-						prevCmd.updatedBy = append(prevCmd.updatedBy, "previously found in the arguments")
-					}
 					break
 				}
 			}
@@ -620,30 +1874,24 @@ func (flagSet *FlagSet) parseCommands() {
 	// Update indexTo value (i.e. commands: foo, bar `app foo -b`. indexTo for foo must be 2)
 	for i := 0; i < lenCmds; i++ {
 		cmd := flagSet.commands[i]
-		// If the command is not found or indexTo is already up to date then
-		if cmd.argID == -1 || cmd.indexTo != -1 {
-			continue
-		}
-
-		// If it's the last loop then
-		if i+1 == lenCmds {
-			cmd.indexTo = len(flagSet.argsRaw)
-			cmd.updatedBy = append(cmd.updatedBy, "last loop")
+		// If the command is not found then
+		if cmd.argID == -1 {
 			continue
 		}
 
-		// Otherwise search for the following command
-		for j := i + 1; j < lenCmds; j++ {
-			if flagSet.commands[j].indexFrom != -1 {
-				cmd.indexTo = flagSet.commands[j].indexFrom
-				cmd.updatedBy = append(cmd.updatedBy, "next command")
-				break
+		// Search for the following command in the arguments (not in the definition order)
+		for j := 0; j < lenCmds; j++ {
+			next := flagSet.commands[j]
+			if next.indexFrom > cmd.indexFrom && (cmd.indexTo == -1 || next.indexFrom < cmd.indexTo) {
+				cmd.indexTo = next.indexFrom
 			}
 		}
 		// If it's not found then
 		if cmd.indexTo == -1 {
 			cmd.indexTo = len(flagSet.argsRaw)
 			cmd.updatedBy = append(cmd.updatedBy, "last command")
+		} else {
+			cmd.updatedBy = append(cmd.updatedBy, "next command")
 		}
 	}
 
@@ -653,13 +1901,34 @@ func (flagSet *FlagSet) parseCommands() {
 			if cmd.flagID == flag.id {
 				flag.commandID = cmd.id
 				if flag.global {
-					cmd.err = fmt.Errorf("command %s can't be global", flag.command)
+					cmd.err = flagSet.errorf("command %s can't be global", flag.command)
 				}
 				break
 			}
 		}
 	}
 
+	// Sibling commands are mutually exclusive unless they are chained (i.e. not `app start stop` if start and stop belong to app)
+	firstCmds := map[int]*Command{}
+	for _, cmd := range flagSet.commands {
+		if cmd.argID == -1 || flagSet.chain {
+			continue
+		}
+		first, ok := firstCmds[cmd.parentID]
+		if !ok {
+			firstCmds[cmd.parentID] = cmd
+			continue
+		}
+		later := cmd
+		if cmd.argID < first.argID {
+			firstCmds[cmd.parentID] = cmd
+			first, later = cmd, first
+		}
+		if later.err == nil {
+			later.err = flagSet.errorf("command %s can't be used with command %s", later.command, first.command)
+		}
+	}
+
 	flagSet.commandsParsed = true
 }
 
@@ -710,6 +1979,15 @@ func (flagSet *FlagSet) parseArgs() {
 			}
 		}
 
+		// Check the end-of-flags terminator (i.e. `app -- -f`)
+		if flagSet.terminator > 0 && argIndex >= flagSet.terminator {
+			if argIndex == flagSet.terminator && argVal == "--" {
+				newArg.kind = "terminator"
+			} else {
+				newArg.terminated = true
+			}
+		}
+
 		if newArg.kind == "" {
 			newArg.kind = "arg"
 		}
@@ -724,6 +2002,13 @@ func (flagSet *FlagSet) parseArgs() {
 			continue
 		}
 
+		// Arguments after the terminator are unnamed as they are
+		if arg.terminated {
+			arg.name = arg.arg
+			arg.unnamed = true
+			continue
+		}
+
 		arg.name = strings.TrimSpace(strings.TrimLeft(arg.arg, "-"))
 
 		if strings.HasPrefix(arg.arg, "--") {
@@ -757,7 +2042,7 @@ func (flagSet *FlagSet) parseArgs() {
 			// Check the next argument (i.e. `[--arg value]`)
 			if argIndex+1 < argsLen {
 				nextArg := flagSet.args[argIndex+1]
-				if nextArg.kind == "arg" && !strings.HasPrefix(nextArg.arg, "-") {
+				if nextArg.kind == "arg" && !nextArg.terminated && isArgValue(flagSet.argFlag(arg), nextArg.arg) {
 					arg.value = nextArg.arg
 					arg.indexTo = nextArg.indexTo
 					if strings.HasPrefix(arg.value, "\"") {
@@ -776,6 +2061,34 @@ func (flagSet *FlagSet) parseArgs() {
 		if arg.hasEq && arg.value == "" {
 			arg.unset = true // for example `--arg= --arg="" --arg=''`
 		}
+
+		// Check the following arguments for the multi-value arguments (i.e. `--range 10 20`)
+		if arg.value != "" {
+			if f := flagSet.argFlag(arg); f != nil && f.nargs != "" {
+				need := -1 // as many as possible (i.e. `nargs:"+"`)
+				if n, err := strconv.Atoi(f.nargs); err == nil {
+					need = n - 1
+				}
+				for i := arg.indexTo; i < argsLen && need != 0; i++ {
+					nextArg := flagSet.args[i]
+					if nextArg.kind != "arg" || nextArg.terminated || !isArgValue(f, nextArg.arg) {
+						break
+					}
+					value := nextArg.arg
+					if strings.HasPrefix(value, "\"") {
+						value = strings.Trim(value, "\"")
+					} else if strings.HasPrefix(value, "'") {
+						value = strings.Trim(value, "'")
+					}
+					arg.values = append(arg.values, value)
+					arg.indexTo = nextArg.indexTo
+					nextArg.kind = "argval"
+					nextArg.value = value
+					nextArg.parentID = arg.id
+					need--
+				}
+			}
+		}
 	}
 
 	// Iterate over the flags and update the values
@@ -791,22 +2104,48 @@ func (flagSet *FlagSet) parseArgs() {
 						if arg.commandID == cmd.id {
 
 							// Arguments those have not flag (flagID: -1) but have a command (commandID > 0) might be global
-							if arg.flagID == -1 {
+							if arg.flagID == -1 && !arg.terminated {
 								if f := flagSet.FlagByArg(arg.name, ""); f != nil && f.global {
 									// Update the argument and it's flag
 									f.updatedBy = append(flag.updatedBy, "global argument")
 									f.args = append(f.args, arg)
 									arg.updatedBy = append(arg.updatedBy, "global argument")
 									arg.flagID = f.id
-									arg.commandID = -1
+									arg.commandID = -1
+									// Check the value argument
+									if arg.valueID > -1 {
+										for _, a := range flagSet.args {
+											if a.id == arg.valueID {
+												a.updatedBy = append(a.updatedBy, "global argument")
+												a.flagID = f.id
+												a.commandID = -1
+												break
+											}
+										}
+									}
+									continue
+								}
+							}
+
+							// Arguments those belong to the parent commands are resolved upward (i.e. `app foo --verbose`)
+							if arg.flagID == -1 && arg.kind == "arg" && !arg.unnamed && !arg.terminated {
+								if f := flagSet.argFlag(arg); f != nil && f.parentID != flag.id {
+									f.updatedBy = append(f.updatedBy, "parent argument")
+									f.args = append(f.args, arg)
+									for _, c := range flagSet.commands {
+										if c.flagID == f.parentID {
+											f.commandID = c.id
+											break
+										}
+									}
+									arg.updatedBy = append(arg.updatedBy, "parent argument")
+									arg.flagID = f.id
 									// Check the value argument
 									if arg.valueID > -1 {
 										for _, a := range flagSet.args {
-											if a.id == arg.valueID {
-												a.updatedBy = append(a.updatedBy, "global argument")
+											if a.parentID == arg.id {
+												a.updatedBy = append(a.updatedBy, "parent argument")
 												a.flagID = f.id
-												a.commandID = -1
-												break
 											}
 										}
 									}
@@ -836,7 +2175,7 @@ func (flagSet *FlagSet) parseArgs() {
 				if parentFlag != nil && parentFlag.args != nil {
 					// Iterate over the parent flag's arguments
 					for _, pArg := range parentFlag.args {
-						if pArg.name != "" && (flag.short == pArg.name || flag.long == pArg.name) {
+						if !pArg.terminated && !pArg.unnamed && flagSet.argMatches(flag, pArg.name) {
 							flag.updatedBy = append(flag.updatedBy, "matched argument")
 							flag.commandID = pArg.commandID
 							pArg.flagID = flag.id
@@ -851,7 +2190,7 @@ func (flagSet *FlagSet) parseArgs() {
 				for _, arg := range flagSet.args {
 					// Flag has no parent so make sure the argument is not belong to any other command (i.e. `app command --foo`)
 					// Command arguments are handled previously
-					if arg.commandID == -1 && arg.name != "" && (flag.short == arg.name || flag.long == arg.name) {
+					if arg.commandID == -1 && !arg.terminated && !arg.unnamed && flagSet.argMatches(flag, arg.name) {
 						flag.updatedBy = append(flag.updatedBy, "top level flag")
 						arg.updatedBy = append(arg.updatedBy, "top level arg")
 						arg.flagID = flag.id
@@ -861,11 +2200,43 @@ func (flagSet *FlagSet) parseArgs() {
 				}
 			}
 		}
+
+		// Positional arguments
+		if flag.kind == "pos" {
+			// Unnamed arguments of the parent command or the top level ones (skip the program name)
+			args := flagSet.args
+			if flag.parentID != -1 {
+				args = nil
+				if parentFlag := flagSet.flagByIndex(flag.parentIndex); parentFlag != nil {
+					args = parentFlag.args
+				}
+			}
+			for k, arg := range args {
+				if arg.kind != "arg" || !arg.unnamed || arg.flagID != -1 {
+					continue
+				}
+				if flag.parentID == -1 && (k == 0 || arg.commandID != -1) {
+					continue
+				}
+				flag.updatedBy = append(flag.updatedBy, "positional argument")
+				arg.updatedBy = append(arg.updatedBy, "positional flag")
+				arg.flagID = flag.id
+				flag.args = append(flag.args, arg)
+			}
+		}
 	}
 
 	flagSet.argsParsed = true
 }
 
+// fieldValue returns the struct field value of the given flag
+func (flagSet *FlagSet) fieldValue(flag *Flag) reflect.Value {
+	if values, ok := flagSet.flagsRaw.([]interface{}); ok {
+		return reflect.ValueOf(values[flag.fieldIndex[0]]).Elem().FieldByIndex(flag.fieldIndex[1:])
+	}
+	return reflect.ValueOf(flagSet.flagsRaw).Elem().FieldByIndex(flag.fieldIndex)
+}
+
 // setFlag sets a flag value by the given flag id and value
 func (flagSet *FlagSet) setFlag(id int, value string) error {
 	if id < 0 {
@@ -877,30 +2248,62 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 	if flag == nil {
 		return fmt.Errorf("no flag for id %d", id)
 	}
-	rValue := reflect.ValueOf(flagSet.flagsRaw).Elem()
-	fv := rValue.FieldByIndex(flag.fieldIndex)
+	fv := flagSet.fieldValue(flag)
 	if !fv.CanSet() {
 		return fmt.Errorf("flag %s can't be set", flag.name)
 	}
 
+	// Expand the env variables (i.e. `${HOME}/data`)
+	if flag.expand {
+		value = os.ExpandEnv(value)
+	}
+
+	// Read the file values (i.e. `file:/run/secrets/token`)
+	value, err := flagSet.fileValue(flag, value)
+	if err != nil {
+		return err
+	}
+
+	// Secret values never appear in the errors
+	shown := value
+	if flag.secret {
+		shown = SecretPlaceholder
+	}
+
+	// Normalize the numbers (i.e. `1_000` to `1000`)
+	if flag.lenientNumbers || flagSet.lenientNumbers {
+		value = normalizeNumber(value, flag.valueType)
+	}
+
+	// The fixed-size arrays are set by a slice those has the values set so far (see nargs tag)
+	var array reflect.Value
+	if fv.Kind() == reflect.Array {
+		n := 0
+		if v, ok := flag.value.(reflect.Value); ok && v.Kind() == reflect.Slice {
+			n = v.Len()
+		}
+		if n >= fv.Len() {
+			return flagSet.errorf("flag %s takes %d values", flag.name, fv.Len())
+		}
+		array, fv = fv, reflect.New(reflect.SliceOf(fv.Type().Elem())).Elem()
+		fv.Set(array.Slice(0, n))
+		defer reflect.Copy(array, fv)
+	}
+
 	// Set the value
 	switch flag.valueType {
 	case "bool":
-		if value != "true" && value != "false" {
-			return fmt.Errorf("failed to parse '%s' as bool", value)
-		}
-		if value == "true" {
-			fv.SetBool(true)
-			flag.value = true
-		} else if value == "false" {
-			fv.SetBool(false)
-			flag.value = false
+		v, err := parseBool(value)
+		if err != nil {
+			return flagSet.parseError(flag, shown, "bool")
 		}
+		fv.SetBool(v)
+		flag.value = v
 	case "float64":
 		if value != "" {
 			v, err := strconv.ParseFloat(value, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as float64", value)
+				return flagSet.parseError(flag, shown, "float64")
 			}
 			fv.SetFloat(v)
 			flag.value = v
@@ -909,7 +2312,7 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		if value != "" {
 			v, err := strconv.ParseInt(value, 10, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as int", value)
+				return flagSet.parseError(flag, shown, "int")
 			}
 			fv.SetInt(v)
 			flag.value = v
@@ -918,7 +2321,7 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		if value != "" {
 			v, err := strconv.ParseInt(value, 10, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as int64", value)
+				return flagSet.parseError(flag, shown, "int64")
 			}
 			fv.SetInt(v)
 			flag.value = v
@@ -927,7 +2330,7 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		if value != "" {
 			v, err := strconv.ParseUint(value, 10, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as uint", value)
+				return flagSet.parseError(flag, shown, "uint")
 			}
 			fv.SetUint(v)
 			flag.value = v
@@ -936,7 +2339,7 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		if value != "" {
 			v, err := strconv.ParseUint(value, 10, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as uint64", value)
+				return flagSet.parseError(flag, shown, "uint64")
 			}
 			fv.SetUint(v)
 			flag.value = v
@@ -945,23 +2348,18 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		fv.SetString(value)
 		flag.value = value
 	case "[]bool":
-		if value != "true" && value != "false" {
-			return fmt.Errorf("failed to parse '%s' as bool", value)
+		bv, err := parseBool(value)
+		if err != nil {
+			return flagSet.parseError(flag, shown, "bool")
 		}
-		var b reflect.Value
-		if value == "true" {
-			b = reflect.ValueOf(true)
-		} else if value == "false" {
-			b = reflect.ValueOf(false)
-		}
-		v := reflect.Append(fv, b)
+		v := reflect.Append(fv, reflect.ValueOf(bv))
 		fv.Set(v)
 		flag.value = v
 	case "[]float64":
 		if value != "" {
 			f, err := strconv.ParseFloat(value, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as float64", value)
+				return flagSet.parseError(flag, shown, "float64")
 			}
 			v := reflect.Append(fv, reflect.ValueOf(f))
 			fv.Set(v)
@@ -971,7 +2369,7 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		if value != "" {
 			i, err := strconv.ParseInt(value, 10, 32)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as int", value)
+				return flagSet.parseError(flag, shown, "int")
 			}
 			v := reflect.Append(fv, reflect.ValueOf(int(i)))
 			fv.Set(v)
@@ -981,7 +2379,7 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		if value != "" {
 			i, err := strconv.ParseInt(value, 10, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as int64", value)
+				return flagSet.parseError(flag, shown, "int64")
 			}
 			v := reflect.Append(fv, reflect.ValueOf(i))
 			fv.Set(v)
@@ -991,7 +2389,7 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		if value != "" {
 			u, err := strconv.ParseUint(value, 10, 32)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as uint", value)
+				return flagSet.parseError(flag, shown, "uint")
 			}
 			v := reflect.Append(fv, reflect.ValueOf(uint(u)))
 			fv.Set(v)
@@ -1001,7 +2399,7 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 		if value != "" {
 			u, err := strconv.ParseUint(value, 10, 64)
 			if err != nil {
-				return fmt.Errorf("failed to parse '%s' as uint64", value)
+				return flagSet.parseError(flag, shown, "uint64")
 			}
 			v := reflect.Append(fv, reflect.ValueOf(u))
 			fv.Set(v)
@@ -1018,6 +2416,62 @@ func (flagSet *FlagSet) setFlag(id int, value string) error {
 	return nil
 }
 
+// validateFlag validates the given value by the validators of the flag
+func (flagSet *FlagSet) validateFlag(flag *Flag, value string) error {
+	if flag == nil {
+		return nil
+	}
+	if flag.expand {
+		value = os.ExpandEnv(value)
+	}
+	if v, err := flagSet.fileValue(flag, value); err == nil {
+		value = v
+	}
+	for _, name := range flag.validate {
+		fn, ok := validator(name)
+		if !ok {
+			return fmt.Errorf("has an unknown validator %s", name)
+		}
+		if err := fn(value); err != nil {
+			return errors.New(flagSet.message(err.Error()))
+		}
+	}
+	return nil
+}
+
+// fileValue returns the content of the file by the given value when the flag reads its values
+// from the files (i.e. `file:/run/secrets/token` or `file:///run/secrets/token`, see `from-file` tag).
+// The content is trimmed and the other values are returned as is.
+func (flagSet *FlagSet) fileValue(flag *Flag, value string) (string, error) {
+	if !flag.fromFile || !strings.HasPrefix(value, "file:") {
+		return value, nil
+	}
+	path := strings.TrimPrefix(strings.TrimPrefix(value, "file:"), "//")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", flagSet.errorf("failed to read the value of %s due to %s", flag.FormattedArg(), err.Error())
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// secretFile returns the path of the secret file of the given flag in the secrets directory
+// (i.e. `/run/secrets/DB_PASSWORD` or `/run/secrets/db-password`) and whether it exists or not
+func (flagSet *FlagSet) secretFile(flag *Flag) (string, bool) {
+	if flagSet.secretsDir == "" {
+		return "", false
+	}
+	for _, name := range []string{flag.env, flag.long} {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(flagSet.secretsDir, name)
+		if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
 // unsetFlag sets a flag value to default by the given flag id
 func (flagSet *FlagSet) unsetFlag(id int) error {
 	if id < 0 {
@@ -1029,12 +2483,18 @@ func (flagSet *FlagSet) unsetFlag(id int) error {
 	if flag == nil {
 		return fmt.Errorf("no flag for id %d", id)
 	}
-	rValue := reflect.ValueOf(flagSet.flagsRaw).Elem()
-	fv := rValue.FieldByIndex(flag.fieldIndex)
+	fv := flagSet.fieldValue(flag)
 	if !fv.CanSet() {
 		return fmt.Errorf("flag %s can't be set", flag.name)
 	}
 
+	// The fixed-size arrays are set to their zero values and have no values set so far
+	if fv.Kind() == reflect.Array {
+		fv.Set(reflect.Zero(fv.Type()))
+		flag.value = reflect.Zero(reflect.SliceOf(fv.Type().Elem()))
+		return nil
+	}
+
 	// Set the value
 	switch flag.valueType {
 	case "bool":
@@ -1100,25 +2560,145 @@ func (flagSet *FlagSet) unsetFlag(id int) error {
 	return nil
 }
 
+// splitDelimited splits the given value by the given delimiter and returns the elements
+// Delimiters can be escaped by backslash (i.e. `a\,b`) or kept in quoted elements (i.e. `"a,b"`).
+// Elements are trimmed and the empty ones are ignored unless keepEmpty is true or they are quoted.
+func splitDelimited(value, delimiter string, keepEmpty bool) []string {
+	// Init vars
+	var result []string
+	var quote byte
+	cur := ""
+	quoted := false
+
+	// Add the current element into the result
+	flush := func() {
+		if !quoted {
+			cur = strings.TrimSpace(cur)
+		}
+		if cur != "" || quoted || keepEmpty {
+			result = append(result, cur)
+		}
+		cur = ""
+		quoted = false
+	}
+
+	// Iterate over the value
+	for i := 0; i < len(value); {
+		c := value[i]
+		if c == '\\' && i+1 < len(value) {
+			// Escaped delimiter, quote or backslash (i.e. `\,` `\"` `\\`)
+			if strings.HasPrefix(value[i+1:], delimiter) {
+				cur += delimiter
+				i += 1 + len(delimiter)
+				continue
+			} else if n := value[i+1]; n == '"' || n == '\'' || n == '\\' {
+				cur += value[i+1 : i+2]
+				i += 2
+				continue
+			}
+		}
+		if quote != 0 {
+			if c == quote {
+				quote = 0 // end of the quoted element
+			} else {
+				cur += value[i : i+1]
+			}
+			i++
+			continue
+		}
+		if (c == '"' || c == '\'') && !quoted && strings.TrimSpace(cur) == "" {
+			quote = c // start of the quoted element
+			quoted = true
+			cur = ""
+			i++
+			continue
+		}
+		if strings.HasPrefix(value[i:], delimiter) {
+			flush()
+			i += len(delimiter)
+			continue
+		}
+		if quoted && (c == ' ' || c == '\t') {
+			i++ // ignore the spaces after the quoted element
+			continue
+		}
+		cur += value[i : i+1]
+		i++
+	}
+	flush()
+
+	return result
+}
+
+// ZeroFlags returns a zero value copy of the given flags (i.e. a struct pointer or a slice of struct pointers)
+// so the copy can be parsed without changing the values of the given flags (i.e. for generating documents)
+// The builders are copied as their generated struct pointers (see Builder).
+func ZeroFlags(flags interface{}) (interface{}, error) {
+	if b, ok := flags.(*Builder); ok && b != nil {
+		return b.value(), nil
+	} else if v, ok := flags.([]interface{}); ok {
+		result := make([]interface{}, 0, len(v))
+		for _, vv := range v {
+			zero, err := ZeroFlags(vv)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, zero)
+		}
+		return result, nil
+	}
+	if !isStructPointer(flags) {
+		return nil, fmt.Errorf("flags must be a struct pointer")
+	}
+	return reflect.New(reflect.TypeOf(flags).Elem()).Interface(), nil
+}
+
+// isStructPointer returns whether the given value is a struct pointer or not
+func isStructPointer(value interface{}) bool {
+	if value == nil {
+		return false
+	} else if strings.HasPrefix(fmt.Sprintf("%T", value), "*struct") {
+		return true
+	}
+	return reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.Indirect(reflect.ValueOf(value)).Kind() == reflect.Struct
+}
+
 // structToFlags parses the given struct and return a list of flags
 func structToFlags(value interface{}) ([]*Flag, []error) {
 	// Init vars
 	var result []*Flag
 
-	// Iterate over the fields
-	vType := reflect.Indirect(reflect.ValueOf(value)).Type()
-	fields := typeToStructField(vType, nil)
-	for k, field := range fields {
-		flag := structFieldToFlag(field)
-		if flag.kind == "" {
-			continue // skip the non flag fields
-		}
-		flag.id = k
-		flag.fieldIndex = field.index
-		if field.parentIndex != nil {
-			flag.parentIndex = field.parentIndex // vType.FieldByIndex(flag.parentIndex).Name
+	// Iterate over the structs (i.e. `[]interface{}{&logFlags, &tlsFlags}`)
+	// The composed structs are handled like the fields of a virtual struct so the field
+	// indexes start with the struct index and the top level fields share the same namespace.
+	values, composed := value.([]interface{})
+	if !composed {
+		values = []interface{}{value}
+	}
+	offset := 0
+	for i, v := range values {
+		// Iterate over the fields
+		vType := reflect.Indirect(reflect.ValueOf(v)).Type()
+		fields := typeToStructField(vType, nil)
+		for k, field := range fields {
+			flag := structFieldToFlag(field)
+			if flag.kind == "" {
+				continue // skip the non flag fields
+			}
+			flag.id = offset + k
+			flag.fieldIndex = field.index
+			if field.parentIndex != nil {
+				flag.parentIndex = field.parentIndex // vType.FieldByIndex(flag.parentIndex).Name
+			}
+			if composed {
+				flag.fieldIndex = append([]int{i}, flag.fieldIndex...)
+				if flag.parentIndex != nil {
+					flag.parentIndex = append([]int{i}, flag.parentIndex...)
+				}
+			}
+			result = append(result, &flag)
 		}
-		result = append(result, &flag)
+		offset += len(fields)
 	}
 
 	// Iterate over the flags and set parent ids
@@ -1132,6 +2712,27 @@ func structToFlags(value interface{}) ([]*Flag, []error) {
 		}
 	}
 
+	// Iterate over the flags and prepend the env prefixes of the parent flags
+	for _, v := range result {
+		if v.env == "" {
+			continue
+		}
+		for pid := v.parentID; pid > -1; {
+			found := false
+			for _, vv := range result {
+				if vv.id == pid {
+					v.env = vv.envPrefix + v.env
+					pid = vv.parentID
+					found = true
+					break
+				}
+			}
+			if !found {
+				break
+			}
+		}
+	}
+
 	// Check the flag arguments
 	if errs := checkFlags(result); errs != nil {
 		return nil, errs
@@ -1156,12 +2757,21 @@ func structFieldToFlag(sf structField) Flag {
 		long:            strings.TrimSpace(sf.field.Tag.Get("long")),
 		command:         strings.TrimSpace(sf.field.Tag.Get("command")),
 		description:     strings.TrimSpace(sf.field.Tag.Get("description")),
+		group:           strings.TrimSpace(sf.field.Tag.Get("group")),
+		placeholder:     strings.TrimSpace(sf.field.Tag.Get("placeholder")),
 		required:        false,
 		nonempty:        false,
 		global:          false,
 		delimiter:       sf.field.Tag.Get("delimiter"),
+		nargs:           strings.TrimSpace(sf.field.Tag.Get("nargs")),
+		repeat:          strings.TrimSpace(sf.field.Tag.Get("repeat")),
+		redirect:        strings.TrimSpace(sf.field.Tag.Get("redirect")),
 		env:             strings.TrimSpace(sf.field.Tag.Get("env")),
+		envPrefix:       strings.TrimSpace(sf.field.Tag.Get("env-prefix")),
 		valueDefault:    strings.TrimSpace(sf.field.Tag.Get("default")),
+		defaultFrom:     strings.TrimSpace(sf.field.Tag.Get("default-from")),
+		keyring:         strings.TrimSpace(sf.field.Tag.Get("keyring")),
+		config:          strings.TrimSpace(sf.field.Tag.Get("config")),
 		valueType:       sf.field.Type.String(),
 		valueBy:         "",
 		value:           nil,
@@ -1175,6 +2785,12 @@ func structFieldToFlag(sf structField) Flag {
 		err:             nil,
 		updatedBy:       nil,
 	}
+	if sf.field.Type.Kind() == reflect.Array {
+		// The fixed-size arrays are set as the slices those have the same length (see nargs tag)
+		flag.valueType = "[]" + sf.field.Type.Elem().String()
+		flag.arrayLen = sf.field.Type.Len()
+	}
+
 	if sf.field.Tag.Get("required") == "true" {
 		flag.required = true
 		// If the flag is required then it's value should not be empty (i.e. `-foo= -foo="" -foo=''`)
@@ -1196,6 +2812,105 @@ func structFieldToFlag(sf structField) Flag {
 		flag.global = true
 	}
 
+	if sf.field.Tag.Get("once") == "true" {
+		flag.once = true
+	}
+
+	if sf.field.Tag.Get("secret") == "true" {
+		flag.secret = true
+	}
+
+	if sf.field.Tag.Get("keep-empty") == "true" {
+		flag.keepEmpty = true
+	}
+
+	if sf.field.Tag.Get("expand") == "true" {
+		flag.expand = true
+	}
+
+	if sf.field.Tag.Get("ordered") == "true" {
+		flag.ordered = true
+	}
+
+	if v := strings.TrimSpace(sf.field.Tag.Get("deprecated")); v != "" && v != "false" {
+		flag.deprecated = v
+	}
+
+	if sf.field.Tag.Get("advanced") == "true" {
+		flag.advanced = true
+	}
+
+	if sf.field.Tag.Get("from-file") == "true" {
+		flag.fromFile = true
+	}
+
+	if sf.field.Tag.Get("config-file") == "true" {
+		flag.configFile = true
+	}
+
+	if sf.field.Tag.Get("lenient-numbers") == "true" {
+		flag.lenientNumbers = true
+	}
+
+	if sf.field.Tag.Get("greedy") == "true" {
+		flag.greedy = true
+		if flag.nargs == "" {
+			flag.nargs = "+" // greedy is an alias for `nargs:"+"`
+		}
+	}
+
+	if v := sf.field.Tag.Get("validate"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				flag.validate = append(flag.validate, name)
+			}
+		}
+	}
+
+	if v := sf.field.Tag.Get("example"); v != "" {
+		for _, example := range strings.Split(v, "\n") { // multiple examples are separated by newline
+			if example = strings.TrimSpace(example); example != "" {
+				flag.examples = append(flag.examples, example)
+			}
+		}
+	}
+
+	if v := sf.field.Tag.Get("show-groups"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				flag.showGroups = append(flag.showGroups, name)
+			}
+		}
+	}
+
+	if v := sf.field.Tag.Get("hide-groups"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				flag.hideGroups = append(flag.hideGroups, name)
+			}
+		}
+	}
+
+	if v := strings.TrimSpace(sf.field.Tag.Get("long-description")); v != "" {
+		flag.longDescription = v
+	}
+
+	if v := sf.field.Tag.Get("see-also"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				flag.seeAlso = append(flag.seeAlso, name)
+			}
+		}
+	}
+
+	if v := sf.field.Tag.Get("requires"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				flag.requires = append(flag.requires, name)
+			}
+		}
+	}
+
 	// Cleanup args
 	regArg, err := regexp.Compile("[^a-zA-Z0-9-_.]+")
 	if err == nil {
@@ -1207,11 +2922,17 @@ func structFieldToFlag(sf structField) Flag {
 	// Check the flag kind
 	if flag.short != "" || flag.long != "" {
 		flag.kind = "arg"
-	} else if flag.command != "" && strings.HasPrefix(flag.valueType, "struct") {
+	} else if flag.command != "" && sf.field.Type.Kind() == reflect.Struct {
 		flag.kind = "command"
 		flag.valueType = "struct"
+		if flag.valueDefault == "true" {
+			flag.defaultCommand = true // i.e. `app` is same as `app serve` for `command:"serve" default:"true"`
+			flag.valueDefault = ""
+		}
 	} else if sf.field.Tag.Get("settings") == "true" {
 		flag.kind = "settings"
+	} else if sf.field.Tag.Get("pos") == "rest" || sf.field.Tag.Get("args") == "true" {
+		flag.kind = "pos"
 	}
 
 	return flag
@@ -1235,8 +2956,8 @@ func typeToStructField(value reflect.Type, parentIndex []int) []structField {
 		sf := structField{field: field, index: append(pi, field.Index...), parentIndex: parentIndex}
 		result = append(result, sf)
 
-		// Check nested fields
-		if strings.HasPrefix(field.Type.String(), "struct") {
+		// Check nested fields (named struct types are only for commands)
+		if strings.HasPrefix(field.Type.String(), "struct") || (field.Type.Kind() == reflect.Struct && field.Tag.Get("command") != "") {
 			result = append(result, typeToStructField(field.Type, sf.index)...)
 		}
 	}
@@ -1255,6 +2976,9 @@ func checkFlags(flags []*Flag) []error {
 	shorts := map[string]f{}
 	longs := map[string]f{}
 	commands := map[string]f{}
+	positionals := map[string]f{} // by parent
+	defaults := map[string]f{}    // default commands by parent
+	configFile := ""              // name of the config-file flag
 
 	// Iterate over the flags and check errors
 	for _, v := range flags {
@@ -1286,6 +3010,159 @@ func checkFlags(flags []*Flag) []error {
 				commands[v.command] = f{name: v.name, parent: parent}
 			}
 		}
+		if v.kind == "pos" {
+			if pf, ok := positionals[parent]; ok {
+				result = append(result, fmt.Errorf("positional field %s is already defined in %s field", v.name, pf.name))
+			} else {
+				positionals[parent] = f{name: v.name, parent: parent}
+			}
+		}
+
+		// Default commands
+		if v.defaultCommand {
+			if df, ok := defaults[parent]; ok {
+				result = append(result, fmt.Errorf("default command %s in %s field is already defined in %s field", v.command, v.name, df.name))
+			} else {
+				defaults[parent] = f{name: v.name, parent: parent}
+			}
+		}
+
+		// Companion flags
+		for _, name := range v.requires {
+			found := false
+			for _, vv := range flags {
+				if vv.name == name && vv.kind == "arg" && fmt.Sprint(vv.parentIndex) == parent {
+					found = true
+					break
+				}
+			}
+			if !found {
+				result = append(result, fmt.Errorf("requires tag in %s field refers to an undefined %s field", v.name, name))
+			}
+		}
+
+		// Default sources
+		if v.defaultFrom != "" {
+			if _, _, err := parseDefaultFrom(v.defaultFrom); err != nil {
+				result = append(result, fmt.Errorf("%s in %s field", err, v.name))
+			}
+		}
+
+		// Validators
+		for _, name := range v.validate {
+			if _, ok := validator(name); !ok {
+				result = append(result, fmt.Errorf("unknown validator %s in %s field", name, v.name))
+			}
+		}
+
+		// Number of values
+		if v.greedy && v.nargs != "+" {
+			result = append(result, fmt.Errorf("greedy tag in %s field conflicts with nargs tag", v.name))
+		} else if v.greedy && !strings.HasPrefix(v.valueType, "[]") {
+			result = append(result, fmt.Errorf("greedy tag in %s field requires a slice type", v.name))
+		} else if v.nargs != "" {
+			if n, err := strconv.Atoi(v.nargs); (err != nil || n < 1) && v.nargs != "+" {
+				result = append(result, fmt.Errorf("invalid nargs value %s in %s field", v.nargs, v.name))
+			} else if !strings.HasPrefix(v.valueType, "[]") {
+				result = append(result, fmt.Errorf("nargs tag in %s field requires a slice or array type", v.name))
+			}
+		}
+		if v.arrayLen > 0 && v.nargs != strconv.Itoa(v.arrayLen) {
+			// The arrays are filled by one occurrence (i.e. `--range 10 20` for `[2]int` and `nargs:"2"`)
+			result = append(result, fmt.Errorf("array type [%d]%s in %s field requires nargs tag %d", v.arrayLen, strings.TrimPrefix(v.valueType, "[]"), v.name, v.arrayLen))
+		}
+
+		// Repeat policy
+		if v.repeat != "" {
+			if !isRepeatPolicy(v.repeat) {
+				result = append(result, fmt.Errorf("invalid repeat policy %s in %s field", v.repeat, v.name))
+			} else if v.repeat == "append" && (!strings.HasPrefix(v.valueType, "[]") || v.arrayLen > 0) {
+				result = append(result, fmt.Errorf("repeat policy append in %s field requires a slice type", v.name))
+			}
+		}
+
+		// Ordered commands
+		if v.ordered && v.kind != "command" {
+			result = append(result, fmt.Errorf("ordered tag in %s field requires a command", v.name))
+		}
+
+		// Help groups
+		if v.showGroups != nil && v.kind != "command" {
+			result = append(result, fmt.Errorf("show-groups tag in %s field requires a command", v.name))
+		}
+		if v.hideGroups != nil && v.kind != "command" {
+			result = append(result, fmt.Errorf("hide-groups tag in %s field requires a command", v.name))
+		}
+
+		// Help metadata
+		if v.advanced && v.kind != "arg" {
+			result = append(result, fmt.Errorf("advanced tag in %s field requires an argument", v.name))
+		}
+		if v.longDescription != "" && v.kind != "command" {
+			result = append(result, fmt.Errorf("long-description tag in %s field requires a command", v.name))
+		}
+		if v.seeAlso != nil && v.kind != "command" {
+			result = append(result, fmt.Errorf("see-also tag in %s field requires a command", v.name))
+		}
+
+		// Config files
+		if v.config != "" && v.kind != "arg" {
+			result = append(result, fmt.Errorf("config tag in %s field requires an argument", v.name))
+		} else if v.config != "" {
+			for _, name := range strings.Split(v.config, ".") {
+				if strings.TrimSpace(name) == "" {
+					result = append(result, fmt.Errorf("invalid config key %s in %s field", v.config, v.name))
+					break
+				}
+			}
+		}
+		if v.configFile {
+			if v.kind != "arg" || (v.valueType != "string" && v.valueType != "[]string") {
+				result = append(result, fmt.Errorf("config-file tag in %s field requires a string or []string argument", v.name))
+			} else if configFile != "" {
+				result = append(result, fmt.Errorf("config-file tag in %s field conflicts with %s field", v.name, configFile))
+			} else {
+				configFile = v.name
+			}
+		}
+
+		// Keyring secrets
+		if v.keyring != "" && v.kind != "arg" {
+			result = append(result, fmt.Errorf("keyring tag in %s field requires an argument", v.name))
+		} else if _, _, ok := parseKeyring(v.keyring); v.keyring != "" && !ok {
+			result = append(result, fmt.Errorf("invalid keyring value %s in %s field", v.keyring, v.name))
+		}
+
+		// File values
+		if v.fromFile && v.kind != "arg" {
+			result = append(result, fmt.Errorf("from-file tag in %s field requires an argument", v.name))
+		}
+
+		// Deprecated commands
+		if v.deprecated != "" && v.kind != "command" {
+			result = append(result, fmt.Errorf("deprecated tag in %s field requires a command", v.name))
+		}
+		if v.redirect != "" {
+			if v.kind != "command" || v.deprecated == "" {
+				result = append(result, fmt.Errorf("redirect tag in %s field requires a deprecated command", v.name))
+			} else {
+				found := false
+				for _, vv := range flags {
+					if vv.kind == "command" && vv.command == v.redirect && vv.redirect == "" && fmt.Sprint(vv.parentIndex) == parent {
+						found = true
+						break
+					}
+				}
+				if !found {
+					result = append(result, fmt.Errorf("redirect command %s in %s field is not defined", v.redirect, v.name))
+				}
+			}
+		}
+
+		// Positional fields
+		if v.kind == "pos" && (!strings.HasPrefix(v.valueType, "[]") || v.arrayLen > 0) {
+			result = append(result, fmt.Errorf("positional field %s must be a slice", v.name))
+		}
 
 		// Type
 		ftFound := false
@@ -1302,3 +3179,108 @@ func checkFlags(flags []*Flag) []error {
 
 	return result
 }
+
+// isArgValue returns whether the given raw argument can be a value of the given flag.
+// Negative numbers are accepted as the values of the numeric flags (i.e. `--offset -5`).
+func isArgValue(flag *Flag, arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return true
+	} else if flag == nil || len(arg) < 2 || (arg[1] != '.' && (arg[1] < '0' || arg[1] > '9')) {
+		return false
+	}
+	switch strings.TrimPrefix(flag.valueType, "[]") {
+	case "float64", "int", "int64", "uint", "uint64":
+		_, err := strconv.ParseFloat(arg, 64)
+		return err == nil
+	}
+	return false
+}
+
+// deprecationWarning returns the warning message for the given deprecated command
+func (flagSet *FlagSet) deprecationWarning(flag *Flag) string {
+	if flag.deprecated == "true" {
+		return fmt.Sprintf(flagSet.message("command %s is deprecated"), flag.command)
+	}
+	return fmt.Sprintf(flagSet.message("command %s is deprecated: %s"), flag.command, flag.deprecated)
+}
+
+// levenshtein returns the edit distance between the given strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = cur[j-1] + 1
+			if v := prev[j] + 1; v < cur[j] {
+				cur[j] = v
+			}
+			if v := prev[j-1] + cost; v < cur[j] {
+				cur[j] = v
+			}
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// isRepeatPolicy returns whether the given value is a valid repeat policy or not
+func isRepeatPolicy(value string) bool {
+	switch value {
+	case "last", "first", "error", "append":
+		return true
+	}
+	return false
+}
+
+// envName returns the env variable name by the given long name (i.e. `LOG_LEVEL` for `log-level`)
+func envName(long string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(long))
+}
+
+// isValueSource returns whether the given value is a value source or not (see Options.Precedence)
+func isValueSource(value string) bool {
+	switch value {
+	case "arg", "env", "secrets", "keyring", "config", "default":
+		return true
+	}
+	return false
+}
+
+// parseBool returns the bool value of the given string. In addition to strconv.ParseBool
+// it accepts yes/no, y/n and on/off regardless of the case (i.e. `YES`, `Off`).
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(strings.ToLower(value))
+}
+
+// normalizeNumber returns the given value by removing the separators (i.e. `1_000`, `1,000`)
+// for the numeric types. Scientific notation is converted for the integer types (i.e. `1e6`).
+func normalizeNumber(value, valueType string) string {
+	valueType = strings.TrimPrefix(valueType, "[]")
+	switch valueType {
+	case "float64", "int", "int64", "uint", "uint64":
+	default:
+		return value
+	}
+	result := strings.NewReplacer("_", "", ",", "").Replace(value)
+	if valueType != "float64" && strings.ContainsAny(result, "eE") {
+		if f, err := strconv.ParseFloat(result, 64); err == nil && f == math.Trunc(f) {
+			result = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	return result
+}
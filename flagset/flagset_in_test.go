@@ -41,11 +41,11 @@ func TestFlagSet_1(t *testing.T) {
 		}{}
 		args := []string{"./app"}
 		flagSet, err := New(Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
-		So(flagErrors, ShouldContain, errors.New("argument -r is required"))
+		So(flagErrors, ShouldContain, flagSet.requiredError(flagSet.FlagByName("Required"), ""))
 
 		flagTests := []struct {
 			id           int
@@ -806,7 +806,7 @@ func TestFlagSet_2(t *testing.T) {
 func TestFlagSet_settingByID(t *testing.T) {
 	Convey("should return nil when the setting id is not valid", t, func() {
 		flagSet, err := New(Options{Flags: &struct{}{}})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.settingByID(-1), ShouldBeNil)
 	})
@@ -828,7 +828,7 @@ func TestFlagSet_settingByID(t *testing.T) {
 func TestFlagSet_commandByID(t *testing.T) {
 	Convey("should return nil when the command id is not valid", t, func() {
 		flagSet, err := New(Options{Flags: &struct{}{}})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.commandByID(-1), ShouldBeNil)
 	})
@@ -851,7 +851,7 @@ func TestFlagSet_commandByID(t *testing.T) {
 func TestFlagSet_argsByCommandID(t *testing.T) {
 	Convey("should return nil when the command id is not valid", t, func() {
 		flagSet, err := New(Options{Flags: &struct{}{}})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.argsByCommandID(-1), ShouldBeNil)
 	})
@@ -860,19 +860,19 @@ func TestFlagSet_argsByCommandID(t *testing.T) {
 func TestFlagSet_flagByID(t *testing.T) {
 	Convey("should return nil when the flag id is not valid", t, func() {
 		flagSet, err := New(Options{Flags: &struct{}{}})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.flagByID(-1), ShouldBeNil)
 	})
 
 	Convey("should return nil when the flag id doesn't exist", t, func() {
 		flagSet, err := New(Options{Flags: &struct{ NoFlag string }{}})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.flagByID(0), ShouldBeNil)
 
 		flagSet, err = New(Options{Flags: &struct{}{}})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.flagByID(0), ShouldBeNil)
 	})
@@ -882,7 +882,7 @@ func TestFlagSet_flagByIndex(t *testing.T) {
 	Convey("should return nil when the flag index is nil", t, func() {
 		flags := struct{}{}
 		flagSet, err := New(Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.flagByIndex(nil), ShouldBeNil)
 	})
@@ -890,7 +890,7 @@ func TestFlagSet_flagByIndex(t *testing.T) {
 	Convey("should return nil when the flag index doesn't exist", t, func() {
 		flags := struct{}{}
 		flagSet, err := New(Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.flagByIndex([]int{1}), ShouldBeNil)
 	})
@@ -916,7 +916,7 @@ func TestFlagSet_parseSettings(t *testing.T) {
 			"./app",
 		}
 		flagSet, err := New(Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldContain, errors.New("duplicate settings tag for `Foo` and `Settings` flags"))
@@ -3305,7 +3305,7 @@ func TestFlagSet_setFlag(t *testing.T) {
 	Convey("should return error when the flag id is not valid", t, func() {
 		flags := struct{}{}
 		flagSet, err := New(Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.setFlag(-1, ""), ShouldBeError, errors.New("flag id is required"))
 	})
@@ -3313,7 +3313,7 @@ func TestFlagSet_setFlag(t *testing.T) {
 	Convey("should return error when the flag id doesn't exist", t, func() {
 		flags := struct{}{}
 		flagSet, err := New(Options{Flags: &flags})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.setFlag(0, ""), ShouldBeError, errors.New("no flag for id 0"))
 	})
@@ -3324,7 +3324,7 @@ func TestFlagSet_setFlag(t *testing.T) {
 		flag := Flag{fieldIndex: []int{0}}
 		flagSet.flagsRaw = &struct{ Foo interface{} }{}
 		flagSet.flags = append(flagSet.flags, &flag)
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.setFlag(0, ""), ShouldBeError, fmt.Errorf("invalid type . Supported types: %s", supportedFlagValueTypes))
 	})
@@ -3337,7 +3337,7 @@ func TestFlagSet_setFlag(t *testing.T) {
 			bar struct{}
 		}{}
 		flagSet.flags = append(flagSet.flags, &flag)
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.setFlag(0, ""), ShouldBeError, fmt.Errorf("flag  can't be set"))
 	})
@@ -3349,7 +3349,7 @@ func TestFlagSet_unsetFlag(t *testing.T) {
 			Foo bool `short:"f"`
 		}{}
 		flagSet, err := New(Options{Flags: &flags01})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.unsetFlag(-1), ShouldBeError, errors.New("flag id is required"))
 		So(flagSet.unsetFlag(99), ShouldBeError, errors.New("no flag for id 99"))
@@ -3358,12 +3358,27 @@ func TestFlagSet_unsetFlag(t *testing.T) {
 			Foo struct{} `command:"foo"`
 		}{}
 		flagSet, err = New(Options{Flags: &flags02})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.unsetFlag(0), ShouldBeError, fmt.Errorf("invalid type struct. Supported types: %s", supportedFlagValueTypes))
 	})
 }
 
+func Test_splitDelimited(t *testing.T) {
+	Convey("should split the value by the given delimiter", t, func() {
+		So(splitDelimited("", ",", false), ShouldBeNil)
+		So(splitDelimited("a, b ,c", ",", false), ShouldResemble, []string{"a", "b", "c"})
+		So(splitDelimited("a,,b,", ",", false), ShouldResemble, []string{"a", "b"})
+		So(splitDelimited("a,,b,", ",", true), ShouldResemble, []string{"a", "", "b", ""})
+		So(splitDelimited(`a\,b,c`, ",", false), ShouldResemble, []string{"a,b", "c"})
+		So(splitDelimited(`"a,b" , ' c ',""`, ",", false), ShouldResemble, []string{"a,b", " c ", ""})
+		So(splitDelimited(`a\\,b`, ",", false), ShouldResemble, []string{`a\`, "b"})
+		So(splitDelimited(`C:\foo;D:\bar`, ";", false), ShouldResemble, []string{`C:\foo`, `D:\bar`})
+		So(splitDelimited(`a::b\::c`, "::", false), ShouldResemble, []string{"a", "b::c"})
+		So(splitDelimited("ä,ö", ",", false), ShouldResemble, []string{"ä", "ö"})
+	})
+}
+
 func Test_structToFlags(t *testing.T) {
 }
 
@@ -3378,3 +3393,35 @@ func Test_typeToStructField(t *testing.T) {
 
 func Test_checkFlags(t *testing.T) {
 }
+
+func Test_levenshtein(t *testing.T) {
+	Convey("should return the edit distance", t, func() {
+		So(levenshtein("", ""), ShouldEqual, 0)
+		So(levenshtein("foo", ""), ShouldEqual, 3)
+		So(levenshtein("", "foo"), ShouldEqual, 3)
+		So(levenshtein("verbose", "verbose"), ShouldEqual, 0)
+		So(levenshtein("verbos", "verbose"), ShouldEqual, 1)
+		So(levenshtein("vrebose", "verbose"), ShouldEqual, 2)
+		So(levenshtein("kitten", "sitting"), ShouldEqual, 3)
+		So(levenshtein("çay", "cay"), ShouldEqual, 1)
+	})
+}
+
+func Test_parseBool(t *testing.T) {
+	Convey("should return the bool value", t, func() {
+		for _, v := range []string{"true", "TRUE", "True", "t", "T", "1", "yes", "YES", "y", "on", "On"} {
+			b, err := parseBool(v)
+			So(err, ShouldBeNil)
+			So(b, ShouldEqual, true)
+		}
+		for _, v := range []string{"false", "FALSE", "f", "F", "0", "no", "No", "n", "off", "OFF"} {
+			b, err := parseBool(v)
+			So(err, ShouldBeNil)
+			So(b, ShouldEqual, false)
+		}
+		for _, v := range []string{"", "2", "maybe", "yess"} {
+			_, err := parseBool(v)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
@@ -8,13 +8,35 @@ package flagset_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/devfacet/gocmd/flagset"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// errorMessages returns the messages of the given errors (i.e. for comparing the typed errors)
+func errorMessages(errs []error) []string {
+	var result []string
+	for _, err := range errs {
+		result = append(result, err.Error())
+	}
+	return result
+}
+
+// secretStore represents a secret store for the tests (i.e. `service/account` keys)
+type secretStore map[string]string
+
+func (s secretStore) Secret(service, account string) (string, bool, error) {
+	if account == "fail" {
+		return "", false, errors.New("locked")
+	}
+	v, ok := s[service+"/"+account]
+	return v, ok, nil
+}
+
 func TestNew(t *testing.T) {
 	Convey("should fail to create a new flag set", t, func() {
 		flagSet, err := flagset.New(flagset.Options{})
@@ -77,7 +99,7 @@ func TestNew(t *testing.T) {
 			}
 		}{}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags05})
-		So(err, ShouldBeError, errors.New("short argument f in Bar field is already defined in Foo field"))
+		So(err, ShouldBeError, errors.New("short argument f in Bar field is already defined in Foo field\nlong argument foo in Bar field is already defined in Foo field"))
 		So(flagSet, ShouldBeNil)
 
 		flags06 := struct {
@@ -87,7 +109,7 @@ func TestNew(t *testing.T) {
 			} `command:"foo"`
 		}{}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags06})
-		So(err, ShouldBeError, errors.New("short argument f in Bar field is already defined in Foo field"))
+		So(err, ShouldBeError, errors.New("short argument f in Bar field is already defined in Foo field\nlong argument foo in Bar field is already defined in Foo field"))
 		So(flagSet, ShouldBeNil)
 
 		flags07 := struct {
@@ -96,6 +118,232 @@ func TestNew(t *testing.T) {
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags07})
 		So(err, ShouldBeError, errors.New("short argument vv in Version field must be one character long"))
 		So(flagSet, ShouldBeNil)
+
+		flags08 := struct {
+			Password string `long:"password" requires:"Username"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags08})
+		So(err, ShouldBeError, errors.New("requires tag in Password field refers to an undefined Username field"))
+		So(flagSet, ShouldBeNil)
+
+		flags09 := struct {
+			Files string `pos:"rest"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags09})
+		So(err, ShouldBeError, errors.New("positional field Files must be a slice"))
+		So(flagSet, ShouldBeNil)
+
+		flags10 := struct {
+			Range []int `long:"range" nargs:"0"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags10})
+		So(err, ShouldBeError, errors.New("invalid nargs value 0 in Range field"))
+		So(flagSet, ShouldBeNil)
+
+		flags11 := struct {
+			Range int `long:"range" nargs:"2"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags11})
+		So(err, ShouldBeError, errors.New("nargs tag in Range field requires a slice or array type"))
+		So(flagSet, ShouldBeNil)
+
+		flags12 := struct {
+			Site string `long:"site" validate:"missing"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags12})
+		So(err, ShouldBeError, errors.New("unknown validator missing in Site field"))
+		So(flagSet, ShouldBeNil)
+
+		flags13 := struct {
+			Output string `long:"output" repeat:"never"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags13})
+		So(err, ShouldBeError, errors.New("invalid repeat policy never in Output field"))
+		So(flagSet, ShouldBeNil)
+
+		flags14 := struct {
+			Output string `long:"output" repeat:"append"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags14})
+		So(err, ShouldBeError, errors.New("repeat policy append in Output field requires a slice type"))
+		So(flagSet, ShouldBeNil)
+
+		flags15 := struct {
+			Files []string `pos:"rest"`
+			Args  []string `args:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags15})
+		So(err, ShouldBeError, errors.New("positional field Args is already defined in Files field"))
+		So(flagSet, ShouldBeNil)
+
+		flags16 := struct {
+			Files []string `long:"files" greedy:"true" nargs:"2"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags16})
+		So(err, ShouldBeError, errors.New("greedy tag in Files field conflicts with nargs tag"))
+		So(flagSet, ShouldBeNil)
+
+		flags17 := struct {
+			File string `long:"file" greedy:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags17})
+		So(err, ShouldBeError, errors.New("greedy tag in File field requires a slice type"))
+		So(flagSet, ShouldBeNil)
+
+		flags18 := struct {
+			File string `long:"file" ordered:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags18})
+		So(err, ShouldBeError, errors.New("ordered tag in File field requires a command"))
+		So(flagSet, ShouldBeNil)
+
+		flags19 := struct {
+			Foo struct{} `command:"foo" default:"true"`
+			Bar struct{} `command:"bar" default:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags19})
+		So(err, ShouldBeError, errors.New("default command bar in Bar field is already defined in Foo field"))
+		So(flagSet, ShouldBeNil)
+
+		flags20 := struct {
+			File string `long:"file" deprecated:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags20})
+		So(err, ShouldBeError, errors.New("deprecated tag in File field requires a command"))
+		So(flagSet, ShouldBeNil)
+
+		flags21 := struct {
+			Foo struct{} `command:"foo" redirect:"bar"`
+			Bar struct{} `command:"bar"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags21})
+		So(err, ShouldBeError, errors.New("redirect tag in Foo field requires a deprecated command"))
+		So(flagSet, ShouldBeNil)
+
+		flags22 := struct {
+			Foo struct{} `command:"foo" deprecated:"true" redirect:"bar"`
+			Baz struct {
+				Bar struct{} `command:"bar"`
+			} `command:"baz"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags22})
+		So(err, ShouldBeError, errors.New("redirect command bar in Foo field is not defined"))
+		So(flagSet, ShouldBeNil)
+
+		flags23 := struct {
+			File string `long:"file" show-groups:"Networking"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags23})
+		So(err, ShouldBeError, errors.New("show-groups tag in File field requires a command"))
+		So(flagSet, ShouldBeNil)
+
+		flags24 := struct {
+			File string `long:"file" hide-groups:"Networking"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags24})
+		So(err, ShouldBeError, errors.New("hide-groups tag in File field requires a command"))
+		So(flagSet, ShouldBeNil)
+
+		flags25 := struct {
+			File string `long:"file" long-description:"The file to read"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags25})
+		So(err, ShouldBeError, errors.New("long-description tag in File field requires a command"))
+		So(flagSet, ShouldBeNil)
+
+		flags26 := struct {
+			File string `long:"file" see-also:"app cat"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags26})
+		So(err, ShouldBeError, errors.New("see-also tag in File field requires a command"))
+		So(flagSet, ShouldBeNil)
+
+		flags27 := struct {
+			Foo struct{} `command:"foo" advanced:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags27})
+		So(err, ShouldBeError, errors.New("advanced tag in Foo field requires an argument"))
+		So(flagSet, ShouldBeNil)
+
+		flags28 := struct {
+			Foo struct{} `command:"foo" config:"foo"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags28})
+		So(err, ShouldBeError, errors.New("config tag in Foo field requires an argument"))
+		So(flagSet, ShouldBeNil)
+
+		flags29 := struct {
+			Config int `long:"config" config-file:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags29})
+		So(err, ShouldBeError, errors.New("config-file tag in Config field requires a string or []string argument"))
+		So(flagSet, ShouldBeNil)
+
+		flags30 := struct {
+			Config  string `long:"config" config-file:"true"`
+			Config2 string `long:"config2" config-file:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags30})
+		So(err, ShouldBeError, errors.New("config-file tag in Config2 field conflicts with Config field"))
+		So(flagSet, ShouldBeNil)
+
+		flags31 := struct {
+			Port int `long:"port" config:"server..port"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags31})
+		So(err, ShouldBeError, errors.New("invalid config key server..port in Port field"))
+		So(flagSet, ShouldBeNil)
+
+		flags32 := struct {
+			Foo struct{} `command:"foo" from-file:"true"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags32})
+		So(err, ShouldBeError, errors.New("from-file tag in Foo field requires an argument"))
+		So(flagSet, ShouldBeNil)
+
+		flags33 := struct {
+			Foo struct{} `command:"foo" keyring:"app/token"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags33})
+		So(err, ShouldBeError, errors.New("keyring tag in Foo field requires an argument"))
+		So(flagSet, ShouldBeNil)
+
+		flags34 := struct {
+			Token string `long:"token" keyring:"app"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags34})
+		So(err, ShouldBeError, errors.New("invalid keyring value app in Token field"))
+		So(flagSet, ShouldBeNil)
+
+		flags35 := struct {
+			Range [2]int `long:"range" nargs:"3"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags35})
+		So(err, ShouldBeError, errors.New("array type [2]int in Range field requires nargs tag 2"))
+		So(flagSet, ShouldBeNil)
+
+		flags36 := struct {
+			Range [2]int `long:"range" nargs:"2" repeat:"append"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags36})
+		So(err, ShouldBeError, errors.New("repeat policy append in Range field requires a slice type"))
+		So(flagSet, ShouldBeNil)
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &struct{}{}, Repeat: "never"})
+		So(err, ShouldBeError, errors.New("invalid repeat policy never"))
+		So(flagSet, ShouldBeNil)
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &struct{}{}, Precedence: []string{"arg", "file"}})
+		So(err, ShouldBeError, errors.New("invalid value source file"))
+		So(flagSet, ShouldBeNil)
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &struct{}{}, Precedence: []string{"arg", "env", "env"}})
+		So(err, ShouldBeError, errors.New("value source env is duplicated"))
+		So(flagSet, ShouldBeNil)
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &struct{}{}, Precedence: []string{"env", "default"}})
+		So(err, ShouldBeError, errors.New("value source arg is required"))
+		So(flagSet, ShouldBeNil)
 	})
 
 	Convey("should return a new flag set", t, func() {
@@ -150,26 +398,26 @@ func TestNew(t *testing.T) {
 			"-S",
 		}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'DEFAULT' as int"))
-		So(flagErrors, ShouldContain, errors.New("argument -r is required"))
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'DEFAULT' as int")
+		So(errorMessages(flagErrors), ShouldContain, "argument -r is required")
 		So(flagErrors, ShouldContain, errors.New("argument -n needs a value"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foo' as bool"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foo' as float64"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foo' as int"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foo' as int64"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foo' as uint"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foo' as uint64"))
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as bool")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as float64")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as int")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as int64")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as uint")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as uint64")
 		So(flagErrors, ShouldContain, errors.New("argument -s needs a value"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foofoo' as bool"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foofoo' as float64"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foofoo' as int"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foofoo' as int64"))
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foofoo' as uint"))
-		So(flagErrors, ShouldContain, fmt.Errorf("failed to parse '%s' as int", os.Getenv("GOPATH")))
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foofoo' as bool")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foofoo' as float64")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foofoo' as int")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foofoo' as int64")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foofoo' as uint")
+		So(errorMessages(flagErrors), ShouldContain, fmt.Sprintf("failed to parse '%s' as int", os.Getenv("GOPATH")))
 		So(flagErrors, ShouldContain, errors.New("argument -S needs a value"))
 	})
 
@@ -417,7 +665,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-d"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags10, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -432,12 +680,12 @@ func TestNew(t *testing.T) {
 		}{}
 		args := []string{"./app"}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
-		So(flagErrors, ShouldContain, errors.New("argument -f is required"))
-		So(flagErrors, ShouldContain, errors.New("argument -s is required"))
+		So(errorMessages(flagErrors), ShouldContain, "argument -f is required")
+		So(errorMessages(flagErrors), ShouldContain, "argument -s is required")
 		So(flags01.Foo, ShouldEqual, false)
 		So(flags01.String, ShouldEqual, "")
 
@@ -447,12 +695,12 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
-		So(flagErrors, ShouldContain, errors.New("argument --foo is required"))
-		So(flagErrors, ShouldContain, errors.New("argument --string is required"))
+		So(errorMessages(flagErrors), ShouldContain, "argument --foo is required")
+		So(errorMessages(flagErrors), ShouldContain, "argument --string is required")
 		So(flags02.Foo, ShouldEqual, false)
 		So(flags02.String, ShouldEqual, "")
 
@@ -475,11 +723,11 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
-		So(flagErrors, ShouldContain, errors.New("command bar is required"))
+		So(errorMessages(flagErrors), ShouldContain, "command bar is required")
 		So(flags04.CommandFoo.Foo, ShouldEqual, false)
 
 		flags05 := struct {
@@ -493,11 +741,11 @@ func TestNew(t *testing.T) {
 			"bar",
 		}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
-		So(flagErrors, ShouldContain, errors.New("argument -f is required for bar command"))
+		So(errorMessages(flagErrors), ShouldContain, "argument -f is required for bar command")
 		So(flags05.CommandFoo.Foo, ShouldEqual, false)
 		So(flags05.CommandFoo.String, ShouldEqual, "")
 
@@ -573,7 +821,7 @@ func TestNew(t *testing.T) {
 			"command",
 		}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags10, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -638,7 +886,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=", "-b="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags06, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -651,7 +899,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=\"\"", "-b=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags07, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -664,7 +912,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=''", "-b=''"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags08, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -677,7 +925,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=", "-b="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags09, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -690,7 +938,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=", "-b="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags10, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.Errors(), ShouldNotBeNil)
 		So(flagErrors, ShouldContain, errors.New("argument -f needs a value"))
@@ -734,7 +982,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "foo"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags14, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldContain, errors.New("command foo needs an argument"))
@@ -745,7 +993,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "foo"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags15, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldContain, errors.New("command foo needs an argument"))
@@ -767,7 +1015,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "foo"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags17, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldContain, errors.New("command foo needs an argument"))
@@ -779,7 +1027,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "foo", "-g", "1"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags18, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldContain, errors.New("command foo needs an argument"))
@@ -812,6 +1060,234 @@ func TestNew(t *testing.T) {
 		So(flagErrors, ShouldBeNil)
 	})
 
+	Convey("should return correct flag errors (requires)", t, func() {
+		flags01 := struct {
+			Username string `short:"u" long:"username"`
+			Password string `short:"p" long:"password" requires:"Username"`
+		}{}
+		args := []string{"./app", "--password=secret"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors := flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(flagErrors, ShouldContain, errors.New("argument -p requires -u"))
+
+		flags02 := struct {
+			Username string `short:"u" long:"username"`
+			Password string `short:"p" long:"password" requires:"Username"`
+		}{}
+		args = []string{"./app", "-u", "foo", "-p", "secret"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.Username, ShouldEqual, "foo")
+		So(flags02.Password, ShouldEqual, "secret")
+
+		flags03 := struct {
+			Username string `short:"u" long:"username"`
+			Password string `short:"p" long:"password" requires:"Username"`
+		}{}
+		args = []string{"./app", "-u", "foo"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+
+		flags04 := struct {
+			Env      string `long:"env" env:"GOPATH"`
+			Host     string `long:"host"`
+			Password string `long:"password" requires:"Env, Host"`
+		}{}
+		args = []string{"./app", "--password=secret"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors = flagSet.Errors()
+		So(flagErrors, ShouldHaveLength, 1)
+		So(flagErrors, ShouldContain, errors.New("argument --password requires --host"))
+
+		flags05 := struct {
+			Username   string `long:"username"`
+			CommandFoo struct {
+				Username string `long:"username"`
+				Password string `long:"password" requires:"Username"`
+			} `command:"foo"`
+		}{}
+		args = []string{"./app", "--username=foo", "foo", "--password=secret"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors = flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(flagErrors, ShouldContain, errors.New("argument --password requires --username"))
+	})
+
+	Convey("should return correct flag errors (secret)", t, func() {
+		flags01 := struct {
+			Token int    `long:"token" secret:"true"`
+			Pin   []uint `long:"pin" delimiter:"," secret:"true"`
+			Port  int    `long:"port"`
+		}{}
+		args := []string{"./app", "--token=hunter2", "--pin=1,x2", "--port=foo"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors := flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse '***' as int")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse '***' as uint")
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as int")
+		So(fmt.Sprint(flagErrors), ShouldNotContainSubstring, "hunter2")
+		So(fmt.Sprint(flagErrors), ShouldNotContainSubstring, "x2")
+	})
+
+	Convey("should return correct flag errors (validate)", t, func() {
+		flags01 := struct {
+			Site  string   `long:"site" validate:"url"`
+			Email []string `short:"e" delimiter:"," validate:"nonempty,email"`
+		}{}
+		args := []string{"./app", "--site=example.com", "-e=foo@example.com,bar"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors := flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(flagErrors, ShouldContain, errors.New("argument --site must be a valid url"))
+		So(flagErrors, ShouldContain, errors.New("argument -e must be a valid email"))
+		So(flags01.Site, ShouldEqual, "")
+		So(flags01.Email, ShouldBeNil)
+
+		flags02 := struct {
+			Site string `long:"site" validate:"url" default:"http://example.com"`
+			Port int    `long:"port" validate:"port" env:"GOCMD_TEST_PORT"`
+		}{}
+		os.Setenv("GOCMD_TEST_PORT", "0")
+		defer os.Unsetenv("GOCMD_TEST_PORT")
+		args = []string{"./app"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors = flagSet.Errors()
+		So(flagErrors, ShouldHaveLength, 1)
+		So(flagErrors, ShouldContain, errors.New("env variable GOCMD_TEST_PORT must be a valid port number"))
+		So(flags02.Site, ShouldEqual, "http://example.com")
+	})
+
+	Convey("should return correct flag errors (once)", t, func() {
+		flags01 := struct {
+			Output string `short:"o" long:"output" once:"true"`
+		}{}
+		args := []string{"./app", "--output=a", "-o", "b"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors := flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(flagErrors, ShouldContain, errors.New("argument -o can't be repeated"))
+
+		flags02 := struct {
+			Output string `short:"o" long:"output" once:"true"`
+		}{}
+		args = []string{"./app", "--output=a"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.Output, ShouldEqual, "a")
+
+		flags03 := struct {
+			Output     string `long:"output" once:"true"`
+			CommandFoo struct {
+				Output string `long:"output" once:"true"`
+			} `command:"foo"`
+		}{}
+		args = []string{"./app", "--output=a", "foo", "--output=b"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags03.Output, ShouldEqual, "a")
+		So(flags03.CommandFoo.Output, ShouldEqual, "b")
+	})
+
+	Convey("should return correct flag errors (suggestion)", t, func() {
+		flags01 := struct {
+			Verbose    bool `short:"v" long:"verbose"`
+			Debug      bool `long:"debug" global:"true"`
+			CommandFoo struct {
+				Name       string `long:"name"`
+				CommandBar struct {
+				} `command:"bar"`
+			} `command:"foo"`
+		}{}
+		args := []string{"./app", "--verbos", "--xyz", "foo", "baz", "--nme=1", "--debg", "--verbose"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{
+			"unknown argument: --verbos, did you mean --verbose?",
+			"unknown argument: --xyz",
+			"unknown argument: baz, did you mean bar?",
+			"unknown argument: --nme, did you mean --name?",
+			"unknown argument: --debg, did you mean --debug?",
+		})
+
+		args = []string{"./app", "fo"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{"unknown argument: fo, did you mean foo?"})
+	})
+
+	Convey("should return correct flag values (repeat)", t, func() {
+		flags01 := struct {
+			Last   string `long:"last"`
+			First  string `long:"first" repeat:"first"`
+			Error  string `long:"error" repeat:"error"`
+			Append []int  `long:"append"`
+			Slice  []int  `long:"slice" repeat:"last"`
+		}{}
+		args := []string{"./app", "--last=a", "--last=b", "--first=a", "--first=b", "--error=a", "--append=1", "--append=2", "--slice=1", "--slice=2"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Last, ShouldEqual, "b")
+		So(flags01.First, ShouldEqual, "a")
+		So(flags01.Error, ShouldEqual, "a")
+		So(flags01.Append, ShouldResemble, []int{1, 2})
+		So(flags01.Slice, ShouldResemble, []int{2})
+
+		args = []string{"./app", "--error=a", "--error=b"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("argument --error can't be repeated")})
+
+		flags02 := struct {
+			Name   string `long:"name"`
+			Tags   []int  `long:"tag"`
+			Output string `long:"output" repeat:"last"`
+		}{}
+		args = []string{"./app", "--name=a", "--name=b", "--tag=1", "--tag=2", "--output=a", "--output=b"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args, Repeat: "first"})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.Name, ShouldEqual, "a")
+		So(flags02.Tags, ShouldResemble, []int{1})
+		So(flags02.Output, ShouldEqual, "b")
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args, Repeat: "append"})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.Name, ShouldEqual, "b")
+		So(flags02.Tags, ShouldResemble, []int{1, 2})
+	})
+
 	Convey("should return correct flag values (global)", t, func() {
 		flags01 := struct {
 			Global     bool `short:"g" long:"global" global:"true"`
@@ -889,7 +1365,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "foo", "-b", "-g"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags06, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -899,13 +1375,263 @@ func TestNew(t *testing.T) {
 		So(flags06.Global, ShouldEqual, true)
 		So(flags06.CommandFoo.Bar, ShouldEqual, false)
 		So(flags06.CommandFoo.Baz, ShouldEqual, false)
-	})
 
-	Convey("should return correct flag values (env)", t, func() {
-		flags01 := struct {
-			Env string `short:"e" long:"env" env:"GOPATH"`
+		flags07 := struct {
+			Verbose    bool `short:"v" long:"verbose" global:"true"`
+			CommandFoo struct {
+				CommandBar struct {
+					Baz bool `short:"b" long:"baz"`
+				} `command:"bar"`
+			} `command:"foo"`
 		}{}
-		args := []string{"./app"}
+		args = []string{"./app", "foo", "bar", "-b", "--verbose"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags07, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags07.Verbose, ShouldEqual, true)
+		So(flags07.CommandFoo.CommandBar.Baz, ShouldEqual, true)
+		So(flagSet.FlagArgs("CommandFoo.CommandBar"), ShouldResemble, []string{"bar", "-b=true"})
+	})
+
+	Convey("should return correct flag errors (subcommand required)", t, func() {
+		flags01 := struct {
+			CommandQux struct {
+				CommandFoo struct {
+					CommandBar struct {
+					} `command:"bar"`
+					CommandBaz struct {
+					} `command:"baz"`
+				} `command:"foo" required:"true"`
+			} `command:"qux"`
+		}{}
+		args := []string{"./app", "qux", "foo"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("command foo requires a subcommand: bar, baz")})
+
+		args = []string{"./app", "qux", "foo", "baz"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+
+		args = []string{"./app"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+	})
+
+	Convey("should return correct flag values (deprecated command)", t, func() {
+		flags01 := struct {
+			CommandOld struct {
+				Name string `short:"n"`
+			} `command:"old" deprecated:"renamed to sync" redirect:"sync"`
+			CommandSync struct {
+				Name string `short:"n"`
+			} `command:"sync"`
+			CommandLegacy struct {
+			} `command:"legacy" deprecated:"true"`
+		}{}
+		args := []string{"./app", "old", "-n", "foo"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.Warnings(), ShouldResemble, []string{"command old is deprecated: renamed to sync"})
+		So(flags01.CommandOld.Name, ShouldEqual, "")
+		So(flags01.CommandSync.Name, ShouldEqual, "foo")
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandSync"))
+
+		args = []string{"./app", "legacy"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.Warnings(), ShouldResemble, []string{"command legacy is deprecated"})
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandLegacy"))
+
+		args = []string{"./app", "sync"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.Warnings(), ShouldBeNil)
+	})
+
+	Convey("should return correct flag values (default command)", t, func() {
+		flags01 := struct {
+			Verbose      bool `short:"v" long:"verbose"`
+			CommandServe struct {
+				Port int `short:"p" long:"port"`
+			} `command:"serve" default:"true"`
+			CommandStop struct {
+			} `command:"stop"`
+		}{}
+		args := []string{"./app", "-v", "--port=80"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Verbose, ShouldEqual, true)
+		So(flags01.CommandServe.Port, ShouldEqual, 80)
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandServe"))
+		So(flagSet.RawArgs(""), ShouldBeNil)
+		So(flagSet.RawArgs("CommandServe"), ShouldResemble, []string{"-v", "--port=80"})
+
+		args = []string{"./app", "stop"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandStop"))
+		So(flagSet.FlagArgs("CommandServe"), ShouldBeNil)
+
+		flags02 := struct {
+			CommandFoo struct {
+				CommandBar struct {
+					Name string `long:"name"`
+				} `command:"bar" default:"true"`
+				CommandBaz struct {
+				} `command:"baz"`
+			} `command:"foo"`
+		}{}
+		args = []string{"./app", "foo", "--name=qux"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.CommandFoo.CommandBar.Name, ShouldEqual, "qux")
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandFoo.CommandBar"))
+
+		args = []string{"./app"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ActiveCommand(), ShouldBeNil)
+	})
+
+	Convey("should return correct flag values (arguments before commands)", t, func() {
+		flags01 := struct {
+			Config     string `short:"c" long:"config"`
+			Verbose    bool   `short:"v"`
+			CommandFoo struct {
+				Env        string `long:"env"`
+				CommandBar struct {
+					Force bool `short:"f"`
+				} `command:"bar"`
+			} `command:"foo"`
+			CommandBaz struct {
+			} `command:"baz"`
+		}{}
+		args := []string{"./app", "-v", "--config", "x", "foo", "--env", "prod", "bar", "-f"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Config, ShouldEqual, "x")
+		So(flags01.Verbose, ShouldEqual, true)
+		So(flags01.CommandFoo.Env, ShouldEqual, "prod")
+		So(flags01.CommandFoo.CommandBar.Force, ShouldEqual, true)
+		So(flagSet.RawArgs(""), ShouldResemble, []string{"-v", "--config", "x"})
+
+		args = []string{"./app", "--config=x", "baz", "bar"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{"unknown argument: bar"})
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandBaz"))
+	})
+
+	Convey("should return correct flag errors (sibling commands)", t, func() {
+		flags01 := struct {
+			CommandStart struct {
+				Force bool `short:"f"`
+			} `command:"start"`
+			CommandStop struct {
+				CommandNow struct {
+				} `command:"now"`
+				CommandLater struct {
+				} `command:"later"`
+			} `command:"stop"`
+		}{}
+		args := []string{"./app", "stop", "start", "-f"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("command start can't be used with command stop")})
+
+		args = []string{"./app", "stop", "later", "now"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("command now can't be used with command later")})
+
+		args = []string{"./app", "stop", "now"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandStop.CommandNow"))
+	})
+
+	Convey("should return correct flag values (parent arguments)", t, func() {
+		flags01 := struct {
+			Verbose    bool `short:"v" long:"verbose"`
+			CommandFoo struct {
+				Name       string `short:"n" long:"name"`
+				CommandBar struct {
+					Baz bool `short:"b" long:"baz"`
+				} `command:"bar"`
+			} `command:"foo"`
+		}{}
+		args := []string{"./app", "foo", "bar", "-b", "--name", "qux", "-v"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Verbose, ShouldEqual, true)
+		So(flags01.CommandFoo.Name, ShouldEqual, "qux")
+		So(flags01.CommandFoo.CommandBar.Baz, ShouldEqual, true)
+		So(flagSet.FlagArgs("CommandFoo.CommandBar"), ShouldResemble, []string{"bar", "-b=true"})
+		So(flagSet.FlagArgs("CommandFoo.Name"), ShouldResemble, []string{"qux"})
+
+		flags02 := struct {
+			Name       string `long:"name"`
+			CommandFoo struct {
+				Name string `long:"name"`
+			} `command:"foo"`
+		}{}
+		args = []string{"./app", "--name=a", "foo", "--name=b"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.Name, ShouldEqual, "a")
+		So(flags02.CommandFoo.Name, ShouldEqual, "b")
+
+		flags03 := struct {
+			CommandFoo struct {
+				Name string `long:"name"`
+			} `command:"foo"`
+			CommandBar struct {
+			} `command:"bar"`
+		}{}
+		args = []string{"./app", "bar", "--name=a"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{"unknown argument: --name"})
+	})
+
+	Convey("should return correct flag values (env)", t, func() {
+		flags01 := struct {
+			Env string `short:"e" long:"env" env:"GOPATH"`
+		}{}
+		args := []string{"./app"}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
 		So(err, ShouldBeNil)
 		So(flagSet, ShouldNotBeNil)
@@ -957,7 +1683,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-e"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags10, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -965,6 +1691,229 @@ func TestNew(t *testing.T) {
 		So(flags10.Env, ShouldEqual, "")
 	})
 
+	Convey("should return correct flag values (env-prefix)", t, func() {
+		os.Setenv("GOCMD_TEST_DB_HOST", "localhost")
+		os.Setenv("GOCMD_TEST_DB_REPLICA_HOST", "replica")
+		defer os.Unsetenv("GOCMD_TEST_DB_HOST")
+		defer os.Unsetenv("GOCMD_TEST_DB_REPLICA_HOST")
+
+		flags01 := struct {
+			Host       string `long:"host" env:"HOST"`
+			CommandFoo struct {
+				Host       string `long:"host" env:"HOST"`
+				CommandBar struct {
+					Host string `long:"host" env:"HOST"`
+				} `command:"bar" env-prefix:"REPLICA_"`
+			} `command:"foo" env-prefix:"GOCMD_TEST_DB_"`
+		}{}
+		args := []string{"./app", "foo", "bar"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.FlagByName("Host").Env(), ShouldEqual, "HOST")
+		So(flagSet.FlagByName("CommandFoo.Host").Env(), ShouldEqual, "GOCMD_TEST_DB_HOST")
+		So(flagSet.FlagByName("CommandFoo.CommandBar.Host").Env(), ShouldEqual, "GOCMD_TEST_DB_REPLICA_HOST")
+		So(flags01.CommandFoo.Host, ShouldEqual, "localhost")
+		So(flags01.CommandFoo.CommandBar.Host, ShouldEqual, "replica")
+	})
+
+	Convey("should return correct flag values (env prefix option)", t, func() {
+		os.Setenv("GOCMD_TEST_HOST", "localhost")
+		os.Setenv("GOCMD_TEST_DB_PORT", "5432")
+		defer os.Unsetenv("GOCMD_TEST_HOST")
+		defer os.Unsetenv("GOCMD_TEST_DB_PORT")
+
+		flags01 := struct {
+			Host       string `long:"host" env:"HOST"`
+			Token      string `long:"token" env:"GOCMD_TEST_TOKEN"`
+			CommandFoo struct {
+				Port int `long:"port" env:"PORT"`
+			} `command:"foo" env-prefix:"DB_"`
+		}{}
+		args := []string{"./app", "foo"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args, EnvPrefix: "GOCMD_TEST_"})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.FlagByName("Host").Env(), ShouldEqual, "GOCMD_TEST_HOST")
+		So(flagSet.FlagByName("Token").Env(), ShouldEqual, "GOCMD_TEST_TOKEN")
+		So(flagSet.FlagByName("CommandFoo.Port").Env(), ShouldEqual, "GOCMD_TEST_DB_PORT")
+		So(flags01.Host, ShouldEqual, "localhost")
+		So(flags01.CommandFoo.Port, ShouldEqual, 5432)
+	})
+
+	Convey("should return correct flag values (auto env)", t, func() {
+		os.Setenv("GOCMD_TEST_LOG_LEVEL", "debug")
+		os.Setenv("GOCMD_TEST_DB_PORT", "5432")
+		defer os.Unsetenv("GOCMD_TEST_LOG_LEVEL")
+		defer os.Unsetenv("GOCMD_TEST_DB_PORT")
+
+		flags01 := struct {
+			LogLevel   string `long:"log-level"`
+			Host       string `long:"host" env:"HOST_NAME"`
+			Verbose    bool   `short:"v"`
+			CommandFoo struct {
+				Port int `long:"port"`
+			} `command:"foo" env-prefix:"DB_"`
+		}{}
+		args := []string{"./app", "foo"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args, EnvPrefix: "GOCMD_TEST_", AutoEnv: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.FlagByName("LogLevel").Env(), ShouldEqual, "GOCMD_TEST_LOG_LEVEL")
+		So(flagSet.FlagByName("Host").Env(), ShouldEqual, "GOCMD_TEST_HOST_NAME")
+		So(flagSet.FlagByName("Verbose").Env(), ShouldEqual, "")
+		So(flagSet.FlagByName("CommandFoo").Env(), ShouldEqual, "")
+		So(flagSet.FlagByName("CommandFoo.Port").Env(), ShouldEqual, "GOCMD_TEST_DB_PORT")
+		So(flags01.LogLevel, ShouldEqual, "debug")
+		So(flags01.CommandFoo.Port, ShouldEqual, 5432)
+	})
+
+	Convey("should return correct flag values (from-file)", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		token := filepath.Join(dir, "token")
+		So(ioutil.WriteFile(token, []byte("s3cr3t\n"), 0644), ShouldBeNil)
+		os.Setenv("GOCMD_TEST_PASSWORD", "file://"+token)
+		defer os.Unsetenv("GOCMD_TEST_PASSWORD")
+
+		flags01 := struct {
+			Token    string `long:"token" from-file:"true" secret:"true" validate:"nonempty"`
+			Password string `long:"password" env:"GOCMD_TEST_PASSWORD" from-file:"true"`
+			Raw      string `long:"raw"`
+			Plain    string `long:"plain" from-file:"true"`
+		}{}
+		args := []string{"./app", "--token=file:" + token, "--raw=file:" + token, "--plain=foo"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Token, ShouldEqual, "s3cr3t")
+		So(flags01.Password, ShouldEqual, "s3cr3t")
+		So(flags01.Raw, ShouldEqual, "file:"+token)
+		So(flags01.Plain, ShouldEqual, "foo")
+
+		missing := filepath.Join(dir, "missing")
+		So(flagSet.Parse([]string{"./app", "--token=file:" + missing}), ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldHaveLength, 1)
+		So(flagSet.Errors()[0].Error(), ShouldStartWith, "failed to read the value of --token due to open "+missing)
+		So(flags01.Token, ShouldEqual, "")
+	})
+
+	Convey("should return correct flag values (keyring)", t, func() {
+		os.Setenv("GOCMD_TEST_TOKEN", "env-token")
+		defer os.Unsetenv("GOCMD_TEST_TOKEN")
+
+		store := secretStore{"app/token": "keyring-token", "app/password": "s3cr3t", "app/port": "foo"}
+		flags01 := struct {
+			Token    string `long:"token" env:"GOCMD_TEST_TOKEN" keyring:"app/token"`
+			Password string `long:"password" keyring:"app/password" required:"true"`
+			User     string `long:"user" keyring:"app/user" default:"admin"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: []string{"./app"}, SecretStore: store})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Token, ShouldEqual, "env-token")
+		So(flags01.Password, ShouldEqual, "s3cr3t")
+		So(flags01.User, ShouldEqual, "admin")
+		So(flagSet.FlagByName("Token").ValueBy(), ShouldEqual, "env")
+		So(flagSet.FlagByName("Password").ValueBy(), ShouldEqual, "keyring")
+		So(flagSet.FlagByName("User").ValueBy(), ShouldEqual, "default")
+
+		So(flagSet.Parse([]string{"./app", "--password=foo"}), ShouldBeNil)
+		So(flags01.Password, ShouldEqual, "foo")
+		So(flagSet.FlagByName("Password").ValueBy(), ShouldEqual, "arg")
+
+		flags02 := struct {
+			Port int `long:"port" keyring:"app/port"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: []string{"./app"}, SecretStore: store})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldHaveLength, 1)
+		So(flags02.Port, ShouldEqual, 0)
+
+		flags03 := struct {
+			Token string `long:"token" keyring:"app/fail"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: []string{"./app"}, SecretStore: store})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("failed to read keyring secret app/fail due to locked")})
+	})
+
+	Convey("should return correct flag values (secrets)", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		So(ioutil.WriteFile(filepath.Join(dir, "GOCMD_TEST_DB_PASSWORD"), []byte("s3cr3t\n"), 0644), ShouldBeNil)
+		So(ioutil.WriteFile(filepath.Join(dir, "api-token"), []byte("token"), 0644), ShouldBeNil)
+		So(ioutil.WriteFile(filepath.Join(dir, "port"), []byte("abc"), 0644), ShouldBeNil)
+		So(os.Mkdir(filepath.Join(dir, "user"), 0755), ShouldBeNil)
+
+		flags01 := struct {
+			Password string `long:"password" env:"GOCMD_TEST_DB_PASSWORD" required:"true"`
+			Token    string `long:"api-token" secret:"true"`
+			User     string `long:"user" default:"admin"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: []string{"./app"}, SecretsDir: dir})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Password, ShouldEqual, "s3cr3t")
+		So(flags01.Token, ShouldEqual, "token")
+		So(flags01.User, ShouldEqual, "admin")
+		So(flagSet.FlagByName("Password").ValueBy(), ShouldEqual, "secrets")
+		So(flagSet.FlagByName("User").ValueBy(), ShouldEqual, "default")
+
+		os.Setenv("GOCMD_TEST_DB_PASSWORD", "env")
+		defer os.Unsetenv("GOCMD_TEST_DB_PASSWORD")
+		So(flagSet.Parse([]string{"./app", "--api-token=arg"}), ShouldBeNil)
+		So(flags01.Password, ShouldEqual, "env")
+		So(flags01.Token, ShouldEqual, "arg")
+
+		flags02 := struct {
+			Port int `long:"port"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: []string{"./app"}, SecretsDir: dir})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("secret file " + filepath.Join(dir, "port") + " failed to parse 'abc' as int")})
+	})
+
+	Convey("should return correct flag values (expand)", t, func() {
+		os.Setenv("GOCMD_TEST_DIR", "/tmp/gocmd")
+		os.Setenv("GOCMD_TEST_PORT", "8080")
+		defer os.Unsetenv("GOCMD_TEST_DIR")
+		defer os.Unsetenv("GOCMD_TEST_PORT")
+
+		flags01 := struct {
+			Dir   string   `long:"dir" expand:"true"`
+			Raw   string   `long:"raw"`
+			Port  int      `long:"port" default:"${GOCMD_TEST_PORT}" expand:"true"`
+			Paths []string `long:"path" delimiter:"," expand:"true"`
+		}{}
+		args := []string{"./app", "--dir=${GOCMD_TEST_DIR}/data", "--raw=${GOCMD_TEST_DIR}", "--path=$GOCMD_TEST_DIR/a,$GOCMD_TEST_DIR/b"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Dir, ShouldEqual, "/tmp/gocmd/data")
+		So(flags01.Raw, ShouldEqual, "${GOCMD_TEST_DIR}")
+		So(flags01.Port, ShouldEqual, 8080)
+		So(flags01.Paths, ShouldResemble, []string{"/tmp/gocmd/a", "/tmp/gocmd/b"})
+
+		flags02 := struct {
+			Port int `long:"port" expand:"true"`
+		}{}
+		args = []string{"./app", "--port=${GOCMD_TEST_MISSING}1"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flags02.Port, ShouldEqual, 1)
+	})
+
 	Convey("should return correct flag values (bool)", t, func() {
 		flags01 := struct {
 			Foo bool `short:"f" long:"foo"`
@@ -1071,7 +2020,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags11, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1083,7 +2032,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags12, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1135,7 +2084,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags17, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1147,7 +2096,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags18, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1159,7 +2108,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags19, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1171,7 +2120,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags20, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1179,13 +2128,82 @@ func TestNew(t *testing.T) {
 		So(flags20.Foo, ShouldEqual, false)
 	})
 
+	Convey("should return correct flag values (relaxed bool)", t, func() {
+		os.Setenv("GOCMD_TEST_DEBUG", "TRUE")
+		os.Setenv("GOCMD_TEST_QUIET", "0")
+		defer os.Unsetenv("GOCMD_TEST_DEBUG")
+		defer os.Unsetenv("GOCMD_TEST_QUIET")
+
+		flags01 := struct {
+			Debug   bool   `long:"debug" env:"GOCMD_TEST_DEBUG"`
+			Quiet   bool   `long:"quiet" env:"GOCMD_TEST_QUIET" default:"true"`
+			Color   bool   `long:"color"`
+			Cache   bool   `long:"cache" default:"on"`
+			Options []bool `short:"o"`
+		}{}
+		args := []string{"./app", "--color=yes", "-o=1", "-o=off", "-o=T", "-o=N"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Debug, ShouldEqual, true)
+		So(flags01.Quiet, ShouldEqual, false)
+		So(flags01.Color, ShouldEqual, true)
+		So(flags01.Cache, ShouldEqual, true)
+		So(flags01.Options, ShouldResemble, []bool{true, false, true, false})
+
+		args = []string{"./app", "--color=maybe"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{"failed to parse 'maybe' as bool"})
+	})
+
+	Convey("should return correct flag values (lenient numbers)", t, func() {
+		flags01 := struct {
+			Int    int       `long:"int" lenient-numbers:"true"`
+			Uint64 uint64    `long:"uint64" lenient-numbers:"true"`
+			Float  float64   `long:"float" lenient-numbers:"true"`
+			Ints   []int64   `long:"ints" lenient-numbers:"true" delimiter:";"`
+			Strict int       `long:"strict"`
+			Floats []float64 `long:"floats"`
+		}{}
+		args := []string{"./app", "--int=1_000_000", "--uint64=1e19", "--float=1,234.5e2", "--ints=1,000;-2e3", "--strict=1000"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Int, ShouldEqual, 1000000)
+		So(flags01.Uint64, ShouldEqual, uint64(10000000000000000000))
+		So(flags01.Float, ShouldEqual, 123450)
+		So(flags01.Ints, ShouldResemble, []int64{1000, -2000})
+		So(flags01.Strict, ShouldEqual, 1000)
+
+		args = []string{"./app", "--int=1.5e0", "--strict=1_000"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{
+			"failed to parse '1.5e0' as int",
+			"failed to parse '1_000' as int",
+		})
+
+		args = []string{"./app", "--strict=2_000", "--floats=1_0.5"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args, LenientNumbers: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Strict, ShouldEqual, 2000)
+		So(flags01.Floats, ShouldResemble, []float64{10.5})
+	})
+
 	Convey("should return correct flag values (float)", t, func() {
 		flags01 := struct {
 			Float float64 `short:"f" long:"float"`
 		}{}
 		args := []string{"./app", "-f"}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1217,7 +2235,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1229,7 +2247,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1243,7 +2261,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args := []string{"./app", "-i"}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1275,7 +2293,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-i="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1287,7 +2305,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-i=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1301,7 +2319,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args := []string{"./app", "-i"}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1333,7 +2351,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-i="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1345,7 +2363,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-i=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1359,7 +2377,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args := []string{"./app", "-u"}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1391,7 +2409,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-u="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1403,7 +2421,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-u=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1417,7 +2435,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args := []string{"./app", "-u"}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1449,7 +2467,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-u=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1461,7 +2479,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-u=\"\""}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1475,7 +2493,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args := []string{"./app", "-s"}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1549,7 +2567,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-b", "-b="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		So(flagSet.Errors(), ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
@@ -1574,7 +2592,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f", "-f=0.2"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1586,7 +2604,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-f=0.1", "-f="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1610,7 +2628,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-i", "-i=2"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1622,7 +2640,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-i=1", "-i="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1646,7 +2664,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-i", "-i=2"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1658,7 +2676,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-i=1", "-i="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1682,7 +2700,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-u", "-u=2"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1694,7 +2712,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-u=1", "-u="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1718,7 +2736,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-u", "-u=2"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1730,7 +2748,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-u=1", "-u="}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1754,7 +2772,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-s=foo", "-s"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1766,7 +2784,7 @@ func TestNew(t *testing.T) {
 		}{}
 		args = []string{"./app", "-s=foo", "-s"}
 		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors = flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
@@ -1824,6 +2842,439 @@ func TestNew(t *testing.T) {
 		So(flags02.Bools, ShouldResemble, []bool{true, false})
 		So(flags02.Ints, ShouldResemble, []int{1})
 		So(flags02.Strings, ShouldResemble, []string{"bar"})
+
+		flags03 := struct {
+			Tags []string `long:"tags" delimiter:","`
+		}{}
+		args = []string{"./app", `--tags='a,b\,c'`, `--tags="d, 'e,f' ,g"`}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags03.Tags, ShouldResemble, []string{"a", "b,c", "d", "e,f", "g"})
+
+		flags04 := struct {
+			Tags []string `long:"tags" delimiter:"," keep-empty:"true"`
+		}{}
+		args = []string{"./app", "--tags=a,,b,"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags04.Tags, ShouldResemble, []string{"a", "", "b", ""})
+	})
+
+	Convey("should return correct flag values (combined short)", t, func() {
+		flags01 := struct {
+			All     bool   `short:"a"`
+			Bold    bool   `short:"b"`
+			File    string `short:"f"`
+			Verbose []bool `short:"v"`
+			Abc     bool   `long:"abc"`
+		}{}
+		args := []string{"./app", "-abf", "file.txt", "-vvv", "-abc"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.All, ShouldEqual, true)
+		So(flags01.Bold, ShouldEqual, true)
+		So(flags01.File, ShouldEqual, "file.txt")
+		So(flags01.Verbose, ShouldResemble, []bool{true, true, true})
+		So(flags01.Abc, ShouldEqual, true)
+
+		args = []string{"./app", "-ba", "-af=file.txt"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.File, ShouldEqual, "file.txt")
+		So(flagSet.FlagArgs("All"), ShouldResemble, []string{"true", "true"})
+
+		args = []string{"./app", "-xa", "-abx"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{
+			"unknown argument: -xa",
+			"unknown argument: -abx, did you mean --abc?",
+		})
+
+		flags02 := struct {
+			All        bool `short:"a"`
+			CommandFoo struct {
+				Bold bool `short:"b"`
+			} `command:"foo"`
+			CommandBar struct {
+				Count bool `short:"c"`
+			} `command:"bar"`
+		}{}
+		args = []string{"./app", "foo", "-ab"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.All, ShouldEqual, true)
+		So(flags02.CommandFoo.Bold, ShouldEqual, true)
+
+		args = []string{"./app", "foo", "-bc"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{"unknown argument: -bc"})
+		So(flags02.CommandBar.Count, ShouldEqual, false)
+	})
+
+	Convey("should return correct flag values (attached short)", t, func() {
+		flags01 := struct {
+			All    bool   `short:"a"`
+			Num    int    `short:"n"`
+			Output string `short:"o"`
+			Tags   []int  `short:"t"`
+		}{}
+		args := []string{"./app", "-n5", "-aooutput.txt", "-t1", "-t=2", "-t", "3"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.All, ShouldEqual, true)
+		So(flags01.Num, ShouldEqual, 5)
+		So(flags01.Output, ShouldEqual, "output.txt")
+		So(flags01.Tags, ShouldResemble, []int{1, 2, 3})
+
+		args = []string{"./app", "-n-5", "-oa=b", "-nx"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{"failed to parse 'x' as int"})
+		So(flags01.Output, ShouldEqual, "a=b")
+	})
+
+	Convey("should return correct flag values (ordered)", t, func() {
+		flags01 := struct {
+			All     bool     `short:"a"`
+			Bold    bool     `short:"b"`
+			Name    string   `short:"n"`
+			Range   []int    `long:"range" nargs:"2"`
+			Args    []string `pos:"rest"`
+			Command struct {
+				Name string `short:"n"`
+			} `command:"run"`
+		}{}
+		args := []string{"./app", "-ab", "-n", "foo", "--range", "1", "2", "git", "-ab", "status", "run"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args, Ordered: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.All, ShouldEqual, true)
+		So(flags01.Bold, ShouldEqual, true)
+		So(flags01.Name, ShouldEqual, "foo")
+		So(flags01.Range, ShouldResemble, []int{1, 2})
+		So(flags01.Args, ShouldResemble, []string{"git", "-ab", "status", "run"})
+		So(flagSet.PassthroughArgs(), ShouldResemble, []string{"git", "-ab", "status", "run"})
+		So(flagSet.FlagArgs("Command"), ShouldBeNil)
+
+		args = []string{"./app", "-abn", "foo", "git", "-abn", "bar"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args, Ordered: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Name, ShouldEqual, "foo")
+		So(flags01.Args, ShouldResemble, []string{"git", "-abn", "bar"})
+
+		flags02 := struct {
+			All     bool     `short:"a"`
+			Args    []string `pos:"rest"`
+			Command struct {
+				Name string   `short:"n"`
+				Args []string `pos:"rest"`
+			} `command:"run"`
+		}{}
+		args = []string{"./app", "-a", "run", "-n", "foo", "ls", "-n", "bar"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args, Ordered: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.All, ShouldEqual, true)
+		So(flags02.Command.Name, ShouldEqual, "foo")
+		So(flags02.Command.Args, ShouldResemble, []string{"ls", "-n", "bar"})
+		So(flags02.Args, ShouldBeNil)
+
+		flags03 := struct {
+			Name string   `short:"n"`
+			Args []string `pos:"rest"`
+		}{}
+		args = []string{"./app", "foo", "-n", "bar"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags03.Name, ShouldEqual, "bar")
+		So(flags03.Args, ShouldResemble, []string{"foo"})
+
+		flags04 := struct {
+			Verbose    bool     `short:"v"`
+			Files      []string `pos:"rest"`
+			CommandRun struct {
+				Args []string `pos:"rest"`
+			} `command:"run" ordered:"true"`
+		}{}
+		args = []string{"./app", "a", "-v", "run", "kubectl", "get", "pods", "-o", "json", "-v"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags04.Verbose, ShouldEqual, true)
+		So(flags04.Files, ShouldResemble, []string{"a"})
+		So(flags04.CommandRun.Args, ShouldResemble, []string{"kubectl", "get", "pods", "-o", "json", "-v"})
+		So(flagSet.PassthroughArgs(), ShouldResemble, []string{"kubectl", "get", "pods", "-o", "json", "-v"})
+
+		flags05 := struct {
+			All        bool     `short:"a"`
+			Args       []string `pos:"rest"`
+			CommandRun struct {
+				CommandExec struct {
+				} `command:"exec"`
+			} `command:"run"`
+		}{}
+		args = []string{"./app", "exec", "-a"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args, Ordered: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags05.All, ShouldEqual, false)
+		So(flags05.Args, ShouldResemble, []string{"exec", "-a"})
+		So(flagSet.PassthroughArgs(), ShouldResemble, []string{"exec", "-a"})
+	})
+
+	Convey("should return correct flag values (negative)", t, func() {
+		flags01 := struct {
+			Offset int       `long:"offset"`
+			Scale  float64   `short:"s"`
+			Range  []int64   `long:"range" nargs:"2"`
+			Name   string    `short:"n"`
+			Points []float64 `short:"p"`
+		}{}
+		args := []string{"./app", "--offset", "-5", "-s", "-.5", "--range", "-10", "-1", "-p=-1.5", "-p-2"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Offset, ShouldEqual, -5)
+		So(flags01.Scale, ShouldEqual, -0.5)
+		So(flags01.Range, ShouldResemble, []int64{-10, -1})
+		So(flags01.Points, ShouldResemble, []float64{-1.5, -2})
+
+		args = []string{"./app", "-n", "-5", "--offset", "-x"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{
+			"argument -n needs a value",
+			"unknown argument: -5",
+			"argument --offset needs a value",
+			"unknown argument: -x",
+		})
+	})
+
+	Convey("should return correct flag values (case-insensitive)", t, func() {
+		flags01 := struct {
+			Verbose    bool `short:"v" long:"verbose"`
+			Version    bool `short:"V" long:"version"`
+			CommandFoo struct {
+				Name string `long:"name"`
+			} `command:"foo"`
+		}{}
+		args := []string{"./app", "--Verbose", "foo", "--NAME=bar"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args, CaseInsensitive: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Verbose, ShouldEqual, true)
+		So(flags01.Version, ShouldEqual, false)
+		So(flags01.CommandFoo.Name, ShouldEqual, "bar")
+		So(flagSet.FlagByArg("VERBOSE", ""), ShouldNotBeNil)
+
+		flags02 := struct {
+			Verbose bool `short:"v" long:"verbose"`
+			Version bool `short:"V" long:"version"`
+		}{}
+		args = []string{"./app", "-V"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args, CaseInsensitive: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.Verbose, ShouldEqual, false)
+		So(flags02.Version, ShouldEqual, true)
+
+		flags03 := struct {
+			Verbose bool `long:"verbose"`
+		}{}
+		args = []string{"./app", "--Verbose"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors := flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(errorMessages(flagErrors), ShouldContain, "unknown argument: --Verbose, did you mean --verbose?")
+		So(flags03.Verbose, ShouldEqual, false)
+	})
+
+	Convey("should return correct flag values (nargs)", t, func() {
+		flags01 := struct {
+			Range []int    `short:"r" long:"range" nargs:"2"`
+			Files []string `pos:"rest"`
+		}{}
+		args := []string{"./app", "--range", "10", "20", "foo"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Range, ShouldResemble, []int{10, 20})
+		So(flags01.Files, ShouldResemble, []string{"foo"})
+		So(flagSet.FlagArgs("Range"), ShouldResemble, []string{"10", "20"})
+
+		flags02 := struct {
+			Range []int `short:"r" long:"range" nargs:"2"`
+		}{}
+		args = []string{"./app", "-r=10", "20", "-r", "30", "40"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags02.Range, ShouldResemble, []int{10, 20, 30, 40})
+
+		flags03 := struct {
+			Range []int `short:"r" long:"range" nargs:"2"`
+			Bool  bool  `short:"b"`
+		}{}
+		args = []string{"./app", "-r", "10", "-b"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors := flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(flagErrors, ShouldContain, errors.New("argument -r needs 2 values"))
+		So(flags03.Range, ShouldBeNil)
+		So(flags03.Bool, ShouldEqual, true)
+
+		flags04 := struct {
+			CommandFoo struct {
+				Names []string `short:"n" long:"name" nargs:"+"`
+				Bool  bool     `short:"b"`
+			} `command:"foo"`
+		}{}
+		args = []string{"./app", "foo", "-n", "a", "'b c'", "d", "-b"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags04.CommandFoo.Names, ShouldResemble, []string{"a", "b c", "d"})
+		So(flags04.CommandFoo.Bool, ShouldEqual, true)
+		So(flagSet.FlagArgs("CommandFoo"), ShouldResemble, []string{"foo", "-n=a", "b c", "d", "-b=true"})
+
+		flags05 := struct {
+			Files []string `long:"files" greedy:"true"`
+			Next  bool     `long:"next"`
+		}{}
+		args = []string{"./app", "--files", "a", "b", "c", "--next", "--files=d", "e"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags05.Files, ShouldResemble, []string{"a", "b", "c", "d", "e"})
+		So(flags05.Next, ShouldEqual, true)
+		So(flagSet.FlagByName("Files").Nargs(), ShouldEqual, "+")
+
+		flags06 := struct {
+			Range [2]int    `short:"r" long:"range" nargs:"2"`
+			Pair  [2]string `long:"pair" nargs:"2"`
+		}{}
+		args = []string{"./app", "-r", "10", "20", "--pair", "a", "b", "-r", "30", "40"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags06, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags06.Range, ShouldEqual, [2]int{30, 40})
+		So(flags06.Pair, ShouldEqual, [2]string{"a", "b"})
+		So(flagSet.FlagByLong("range").ValueType(), ShouldEqual, "[]int")
+
+		args = []string{"./app", "-r", "10"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags06, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldContain, errors.New("argument -r needs 2 values"))
+		So(flags06.Range, ShouldEqual, [2]int{})
+	})
+
+	Convey("should return correct flag values (pos)", t, func() {
+		flags01 := struct {
+			Output string   `short:"o" long:"output"`
+			Files  []string `pos:"rest"`
+		}{}
+		args := []string{"./app", "foo", "-o", "bar", "baz"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags01, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags01.Output, ShouldEqual, "bar")
+		So(flags01.Files, ShouldResemble, []string{"foo", "baz"})
+		So(flagSet.FlagArgs("Files"), ShouldResemble, []string{"foo", "baz"})
+
+		flags02 := struct {
+			Numbers []int `pos:"rest"`
+		}{}
+		args = []string{"./app", "1", "2", "foo"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors := flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as int")
+		So(flags02.Numbers, ShouldResemble, []int{1, 2})
+
+		flags03 := struct {
+			Files      []string `pos:"rest"`
+			CommandFoo struct {
+				Verbose bool      `short:"v"`
+				Numbers []float64 `pos:"rest"`
+			} `command:"foo"`
+		}{}
+		args = []string{"./app", "bar", "foo", "1.5", "2", "-v"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags03, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags03.Files, ShouldResemble, []string{"bar"})
+		So(flags03.CommandFoo.Verbose, ShouldEqual, true)
+		So(flags03.CommandFoo.Numbers, ShouldResemble, []float64{1.5, 2})
+
+		flags04 := struct {
+			Files []string `pos:"rest"`
+		}{}
+		args = []string{"./app"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags04, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags04.Files, ShouldBeNil)
+
+		flags05 := struct {
+			Args       []string `args:"true"`
+			CommandFoo struct {
+				Name string   `short:"n"`
+				Args []string `args:"true"`
+			} `command:"foo"`
+		}{}
+		args = []string{"./app", "a", "foo", "b", "-n", "c", "d"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags05, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags05.Args, ShouldResemble, []string{"a"})
+		So(flags05.CommandFoo.Name, ShouldEqual, "c")
+		So(flags05.CommandFoo.Args, ShouldResemble, []string{"b", "d"})
+		So(flagSet.FlagByName("CommandFoo.Args").Kind(), ShouldEqual, "pos")
 	})
 
 	Convey("should return correct flag values (command)", t, func() {
@@ -2234,41 +3685,273 @@ func TestNew(t *testing.T) {
 	})
 }
 
-func TestFlagSet_FlagByName(t *testing.T) {
-	Convey("should return a flag by the given name", t, func() {
-		flags := struct {
-			Foo        string `long:"foo-foo"`
-			Bar        string `long:"bar.bar"`
-			Baz        string `long:"baz_baz"`
+func TestNew_composed(t *testing.T) {
+	Convey("should create a new flag set by composing the flags", t, func() {
+		logFlags := struct {
+			Verbose bool   `short:"v" long:"verbose"`
+			Level   string `long:"level" default:"info"`
+		}{}
+		tlsFlags := struct {
+			Cert       string `long:"cert"`
 			CommandFoo struct {
-				Foo        string `long:"foo-foo"`
-				Bar        string `long:"bar.bar"`
-				Baz        string `long:"baz_baz"`
-				CommandBar struct {
-					Test string `long:"test"`
-				} `command:"command.bar"`
-			} `command:"command-foo"`
-			CommandBaz struct{} `command:"command_baz"`
-			Qux        string   `long:"qux"`
+				Bar string `long:"bar"`
+			} `command:"foo"`
 		}{}
-		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{}})
+		flagSet, err := flagset.New(flagset.Options{Flags: []interface{}{&logFlags, &tlsFlags}, Args: []string{"./app", "-v", "--cert=a.pem", "foo", "--bar=baz"}})
 		So(err, ShouldBeNil)
 		So(flagSet, ShouldNotBeNil)
-		So(flagSet.Errors(), ShouldBeNil)
-		flag := flagSet.FlagByName("Foo")
-		So(flag, ShouldNotBeNil)
-		So(flag.Long(), ShouldEqual, "foo-foo")
-		flag = flagSet.FlagByName("Bar")
-		So(flag, ShouldNotBeNil)
-		So(flag.Long(), ShouldEqual, "bar.bar")
-		flag = flagSet.FlagByName("Baz")
-		So(flag, ShouldNotBeNil)
-		So(flag.Long(), ShouldEqual, "baz_baz")
-		flag = flagSet.FlagByName("CommandFoo")
-		So(flag, ShouldNotBeNil)
-		So(flag.Command(), ShouldEqual, "command-foo")
-		flag = flagSet.FlagByName("CommandFoo.Foo")
-		So(flag, ShouldNotBeNil)
+		So(logFlags.Verbose, ShouldBeTrue)
+		So(logFlags.Level, ShouldEqual, "info")
+		So(tlsFlags.Cert, ShouldEqual, "a.pem")
+		So(tlsFlags.CommandFoo.Bar, ShouldEqual, "baz")
+		So(flagSet.FlagByName("Cert").FieldIndex(), ShouldResemble, []int{1, 0})
+		So(flagSet.FlagByName("CommandFoo.Bar").ParentID(), ShouldEqual, flagSet.FlagByName("CommandFoo").ID())
+		So(flagSet.ActiveCommand().Name(), ShouldEqual, "CommandFoo")
+	})
+
+	Convey("should fail to create a new flag set by composing the flags", t, func() {
+		flagSet, err := flagset.New(flagset.Options{Flags: []interface{}{}})
+		So(err, ShouldBeError, errors.New("flags are required"))
+		So(flagSet, ShouldBeNil)
+
+		flags := struct {
+			Verbose bool `short:"v"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: []interface{}{&flags, flags}})
+		So(err, ShouldBeError, errors.New("flags must be a struct pointer"))
+		So(flagSet, ShouldBeNil)
+
+		logFlags := struct {
+			Verbose bool `short:"v"`
+		}{}
+		appFlags := struct {
+			Version bool `short:"v"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: []interface{}{&logFlags, &appFlags}})
+		So(err, ShouldBeError, errors.New("short argument v in Version field is already defined in Verbose field"))
+		So(flagSet, ShouldBeNil)
+	})
+}
+
+func TestZeroFlags(t *testing.T) {
+	Convey("should return a zero value copy of the flags", t, func() {
+		flags := struct {
+			Verbose bool `short:"v"`
+		}{Verbose: true}
+		zero, err := flagset.ZeroFlags(&flags)
+		So(err, ShouldBeNil)
+		So(zero, ShouldResemble, &struct {
+			Verbose bool `short:"v"`
+		}{})
+		So(flags.Verbose, ShouldBeTrue)
+
+		zeros, err := flagset.ZeroFlags([]interface{}{&flags, &flags})
+		So(err, ShouldBeNil)
+		So(zeros, ShouldHaveLength, 2)
+		So(zeros.([]interface{})[1], ShouldNotPointTo, &flags)
+
+		_, err = flagset.ZeroFlags(flags)
+		So(err, ShouldBeError, errors.New("flags must be a struct pointer"))
+	})
+}
+
+func TestCompile(t *testing.T) {
+	Convey("should fail to compile the flags", t, func() {
+		flagSet, err := flagset.Compile(flagset.Options{})
+		So(err, ShouldBeError, errors.New("flags are required"))
+		So(flagSet, ShouldBeNil)
+
+		flags := struct {
+			Foo bool `short:"ff"`
+		}{}
+		flagSet, err = flagset.Compile(flagset.Options{Flags: &flags})
+		So(err, ShouldBeError, errors.New("short argument ff in Foo field must be one character long"))
+		So(flagSet, ShouldBeNil)
+	})
+
+	Convey("should compile the flags without parsing the arguments", t, func() {
+		flags := struct {
+			Foo        bool   `short:"f"`
+			Bar        string `short:"b" default:"bar" env:"BAR"`
+			CommandQux struct {
+				Quux string `long:"quux"`
+			} `command:"qux"`
+		}{}
+		flagSet, err := flagset.Compile(flagset.Options{Flags: &flags, Args: []string{"./app", "-f"}, EnvPrefix: "GOCMD_TEST_"})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Flags(), ShouldHaveLength, 4)
+		So(flagSet.FlagByName("Bar").Env(), ShouldEqual, "GOCMD_TEST_BAR")
+		So(flagSet.OriginalArgs(), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Foo, ShouldBeFalse)
+		So(flags.Bar, ShouldEqual, "")
+
+		So(flagSet.Parse([]string{"./app", "-f", "qux", "--quux=1"}), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Foo, ShouldBeTrue)
+		So(flags.Bar, ShouldEqual, "bar")
+		So(flags.CommandQux.Quux, ShouldEqual, "1")
+
+		So(flagSet.Parse([]string{"./app", "-b", "baz"}), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Foo, ShouldBeFalse)
+		So(flags.Bar, ShouldEqual, "baz")
+		So(flags.CommandQux.Quux, ShouldEqual, "")
+	})
+}
+
+func TestFlagSet_Parse(t *testing.T) {
+	Convey("should fail to parse the arguments", t, func() {
+		flagSet := flagset.FlagSet{}
+		So(flagSet.Parse([]string{"./app"}), ShouldBeError, errors.New("flags are required"))
+	})
+
+	Convey("should parse the arguments multiple times", t, func() {
+		flags := struct {
+			Foo        bool     `short:"f"`
+			Bar        string   `short:"b" default:"bar"`
+			Baz        []int    `long:"baz"`
+			Args       []string `pos:"rest"`
+			CommandQux struct {
+				Quux string `long:"quux" required:"true"`
+			} `command:"qux"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "-f", "-b=foo", "--baz=1", "--baz=2", "a", "qux", "--quux=1"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Foo, ShouldEqual, true)
+		So(flags.Bar, ShouldEqual, "foo")
+		So(flags.Baz, ShouldResemble, []int{1, 2})
+		So(flags.Args, ShouldResemble, []string{"a"})
+		So(flags.CommandQux.Quux, ShouldEqual, "1")
+
+		So(flagSet.Parse([]string{"./app", "--baz=3", "qux", "--unknown"}), ShouldResemble, flagSet.Err())
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{
+			"argument --quux is required for qux command",
+			"unknown argument: --unknown",
+		})
+		So(flags.Foo, ShouldEqual, false)
+		So(flags.Bar, ShouldEqual, "bar")
+		So(flags.Baz, ShouldResemble, []int{3})
+		So(flags.Args, ShouldBeNil)
+		So(flags.CommandQux.Quux, ShouldEqual, "")
+		So(flagSet.FlagByName("Bar").ValueBy(), ShouldEqual, "default")
+		So(flagSet.FlagArgs("Foo"), ShouldBeNil)
+
+		So(flagSet.Parse([]string{"./app", "-f"}), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Foo, ShouldEqual, true)
+		So(flags.Baz, ShouldBeNil)
+		So(flagSet.FlagArgs("CommandQux"), ShouldBeNil)
+	})
+
+	Convey("should parse the arguments by the custom precedence", t, func() {
+		os.Setenv("GOCMD_TEST_PORT", "9090")
+		defer os.Unsetenv("GOCMD_TEST_PORT")
+		os.Setenv("GOCMD_TEST_TAGS", "c")
+		defer os.Unsetenv("GOCMD_TEST_TAGS")
+
+		flags := struct {
+			Port int      `long:"port" env:"GOCMD_TEST_PORT"`
+			Tags []string `long:"tag" env:"GOCMD_TEST_TAGS"`
+			Host string   `long:"host" env:"GOCMD_TEST_HOST" default:"localhost"`
+			Name string   `long:"name" default:"foo"`
+		}{}
+		args := []string{"./app", "--port=8080", "--tag=a", "--tag=b", "--host=example.com"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, Precedence: []string{"env", "arg", "default"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Port, ShouldEqual, 9090)
+		So(flags.Tags, ShouldResemble, []string{"c"})
+		So(flags.Host, ShouldEqual, "example.com")
+		So(flags.Name, ShouldEqual, "foo")
+		So(flagSet.FlagByName("Port").ValueBy(), ShouldEqual, "env")
+		So(flagSet.FlagByName("Host").ValueBy(), ShouldEqual, "arg")
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, Precedence: []string{"arg"}})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Port, ShouldEqual, 0)
+		So(flags.Name, ShouldEqual, "")
+	})
+}
+
+func TestFlagSet_Reset(t *testing.T) {
+	Convey("should reset the flag set", t, func() {
+		flags := struct {
+			Foo        bool     `short:"f"`
+			Bar        string   `short:"b" default:"bar"`
+			Args       []string `pos:"rest"`
+			CommandQux struct {
+				Quux int `long:"quux"`
+			} `command:"qux"`
+		}{}
+		args := []string{"./app", "a", "-f", "qux", "--quux=abc", "--unknown"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldHaveLength, 2)
+		So(flags.Foo, ShouldBeTrue)
+		So(flags.Bar, ShouldEqual, "bar")
+		So(flags.Args, ShouldResemble, []string{"a"})
+
+		flagSet.Reset()
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.OriginalArgs(), ShouldBeNil)
+		So(flagSet.Args(), ShouldBeNil)
+		So(flagSet.ActiveCommand(), ShouldBeNil)
+		So(flagSet.FlagArgs("Foo"), ShouldBeNil)
+		So(flagSet.FlagByName("Bar").ValueBy(), ShouldEqual, "")
+		So(flags.Foo, ShouldBeFalse)
+		So(flags.Bar, ShouldEqual, "")
+		So(flags.Args, ShouldBeNil)
+		So(flags.CommandQux.Quux, ShouldEqual, 0)
+
+		So(flagSet.Parse([]string{"./app", "qux", "--quux=1"}), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Bar, ShouldEqual, "bar")
+		So(flags.CommandQux.Quux, ShouldEqual, 1)
+	})
+}
+
+func TestFlagSet_FlagByName(t *testing.T) {
+	Convey("should return a flag by the given name", t, func() {
+		flags := struct {
+			Foo        string `long:"foo-foo"`
+			Bar        string `long:"bar.bar"`
+			Baz        string `long:"baz_baz"`
+			CommandFoo struct {
+				Foo        string `long:"foo-foo"`
+				Bar        string `long:"bar.bar"`
+				Baz        string `long:"baz_baz"`
+				CommandBar struct {
+					Test string `long:"test"`
+				} `command:"command.bar"`
+			} `command:"command-foo"`
+			CommandBaz struct{} `command:"command_baz"`
+			Qux        string   `long:"qux"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		flag := flagSet.FlagByName("Foo")
+		So(flag, ShouldNotBeNil)
+		So(flag.Long(), ShouldEqual, "foo-foo")
+		flag = flagSet.FlagByName("Bar")
+		So(flag, ShouldNotBeNil)
+		So(flag.Long(), ShouldEqual, "bar.bar")
+		flag = flagSet.FlagByName("Baz")
+		So(flag, ShouldNotBeNil)
+		So(flag.Long(), ShouldEqual, "baz_baz")
+		flag = flagSet.FlagByName("CommandFoo")
+		So(flag, ShouldNotBeNil)
+		So(flag.Command(), ShouldEqual, "command-foo")
+		flag = flagSet.FlagByName("CommandFoo.Foo")
+		So(flag, ShouldNotBeNil)
 		So(flag.Long(), ShouldEqual, "foo-foo")
 		flag = flagSet.FlagByName("CommandFoo.Bar")
 		So(flag, ShouldNotBeNil)
@@ -2311,6 +3994,67 @@ func TestFlagSet_FlagByName(t *testing.T) {
 		flag = flagSet.FlagByName("")
 		So(flag, ShouldBeNil)
 	})
+
+	Convey("should return a flag by the given argument name", t, func() {
+		flags := struct {
+			Verbose    bool   `short:"v" long:"verbose"`
+			Test       string `short:"t" long:"Test"`
+			Other      string `long:"t"`
+			CommandFoo struct {
+				Bar string `short:"b" long:"bar"`
+			} `command:"foo"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.FlagByName("verbose"), ShouldEqual, flagSet.FlagByName("Verbose"))
+		So(flagSet.FlagByName("v"), ShouldEqual, flagSet.FlagByName("Verbose"))
+		So(flagSet.FlagByName("bar"), ShouldEqual, flagSet.FlagByName("CommandFoo.Bar"))
+		So(flagSet.FlagByName("Test").Name(), ShouldEqual, "Test")
+		So(flagSet.FlagByName("t").Name(), ShouldEqual, "Other")
+		So(flagSet.FlagByName("qux"), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_FlagByShort(t *testing.T) {
+	Convey("should return a flag by the given short argument name", t, func() {
+		flags := struct {
+			Verbose    bool `short:"v"`
+			CommandFoo struct {
+				Verbose bool   `short:"v"`
+				Bar     string `short:"b" long:"bar"`
+			} `command:"foo"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.FlagByShort("v"), ShouldEqual, flagSet.FlagByName("Verbose"))
+		So(flagSet.FlagByShort("-v"), ShouldEqual, flagSet.FlagByName("Verbose"))
+		So(flagSet.FlagByShort("b"), ShouldEqual, flagSet.FlagByName("CommandFoo.Bar"))
+		So(flagSet.FlagByShort("bar"), ShouldBeNil)
+		So(flagSet.FlagByShort("x"), ShouldBeNil)
+		So(flagSet.FlagByShort(""), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_FlagByLong(t *testing.T) {
+	Convey("should return a flag by the given long argument name", t, func() {
+		flags := struct {
+			Verbose    bool `short:"v" long:"verbose"`
+			CommandFoo struct {
+				Verbose bool   `long:"verbose"`
+				Bar     string `short:"b" long:"bar"`
+			} `command:"foo"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.FlagByLong("verbose"), ShouldEqual, flagSet.FlagByName("Verbose"))
+		So(flagSet.FlagByLong("--verbose"), ShouldEqual, flagSet.FlagByName("Verbose"))
+		So(flagSet.FlagByLong("bar"), ShouldEqual, flagSet.FlagByName("CommandFoo.Bar"))
+		So(flagSet.FlagByLong("b"), ShouldBeNil)
+		So(flagSet.FlagByLong(""), ShouldBeNil)
+	})
 }
 
 func TestFlagSet_FlagByArg(t *testing.T) {
@@ -2767,6 +4511,406 @@ func TestFlagSet_FlagArgs(t *testing.T) {
 	})
 }
 
+func TestFlagSet_ActiveCommand(t *testing.T) {
+	Convey("should return the deepest present command", t, func() {
+		flags := struct {
+			CommandFoo struct {
+				CommandBar struct {
+					Name string `short:"n"`
+				} `command:"bar"`
+			} `command:"foo"`
+			CommandBaz struct{} `command:"baz"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "foo", "bar", "-n", "baz"}})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandFoo.CommandBar"))
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "baz"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.ActiveCommand(), ShouldEqual, flagSet.FlagByName("CommandBaz"))
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.ActiveCommand(), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_OriginalArgs(t *testing.T) {
+	Convey("should return the arguments without the program name", t, func() {
+		flags := struct {
+			Foo bool `short:"f"`
+		}{}
+		args := []string{"./app", "-fx", "--help", "--", "bar"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.OriginalArgs(), ShouldResemble, []string{"-fx", "--help", "--", "bar"})
+
+		args = []string{"./app"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.OriginalArgs(), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_RedactedArgs(t *testing.T) {
+	Convey("should return the arguments those the secret values are redacted", t, func() {
+		flags := struct {
+			Token   string   `short:"t" long:"token" secret:"true"`
+			Keys    []string `long:"keys" nargs:"2" secret:"true"`
+			Verbose bool     `short:"v"`
+			Name    string   `short:"n"`
+		}{}
+		for _, v := range []struct {
+			args []string
+			want []string
+		}{
+			{[]string{"--token=foo", "-v", "-n", "bar"}, []string{"--token=***", "-v", "-n", "bar"}},
+			{[]string{"-t", "foo", "-n=bar"}, []string{"-t", "***", "-n=bar"}},
+			{[]string{"-vtfoo", "--keys", "a", "b"}, []string{"-vt***", "--keys", "***", "***"}},
+			{[]string{"-v", "--", "--token=foo"}, []string{"-v", "--", "--token=foo"}},
+		} {
+			flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: append([]string{"./app"}, v.args...)})
+			So(err, ShouldBeNil)
+			So(flagSet.RedactedArgs(), ShouldResemble, v.want)
+			So(flagSet.OriginalArgs(), ShouldResemble, v.args)
+		}
+	})
+}
+
+func TestFlagSet_Args(t *testing.T) {
+	Convey("should return the parsed arguments without the program name", t, func() {
+		flags := struct {
+			Foo        string `short:"f"`
+			Verbose    bool   `long:"verbose"`
+			CommandBar struct {
+				Baz string `long:"baz"`
+			} `command:"bar"`
+		}{}
+		args := []string{"./app", "-f", "foo", "--verbose", "bar", "--baz=qux", "--", "-x"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		parsed := flagSet.Args()
+		So(parsed, ShouldHaveLength, 7)
+
+		So(parsed[0].ID(), ShouldEqual, 1)
+		So(parsed[0].Arg(), ShouldEqual, "-f")
+		So(parsed[0].Name(), ShouldEqual, "f")
+		So(parsed[0].Value(), ShouldEqual, "foo")
+		So(parsed[0].Dash(), ShouldEqual, "-")
+		So(parsed[0].Kind(), ShouldEqual, "arg")
+		So(parsed[0].FlagID(), ShouldEqual, flagSet.FlagByName("Foo").ID())
+		So(parsed[0].CommandID(), ShouldEqual, -1)
+		So(parsed[0].IndexFrom(), ShouldEqual, 1)
+		So(parsed[0].IndexTo(), ShouldEqual, 3)
+		So(parsed[0].Unnamed(), ShouldBeFalse)
+		So(parsed[0].Err(), ShouldBeNil)
+
+		So(parsed[1].Kind(), ShouldEqual, "argval")
+		So(parsed[1].Value(), ShouldEqual, "foo")
+		So(parsed[2].Name(), ShouldEqual, "verbose")
+		So(parsed[2].Dash(), ShouldEqual, "--")
+		So(parsed[3].Kind(), ShouldEqual, "command")
+		So(parsed[3].Name(), ShouldEqual, "bar")
+		So(parsed[4].Name(), ShouldEqual, "baz")
+		So(parsed[4].Value(), ShouldEqual, "qux")
+		So(parsed[4].CommandID(), ShouldNotEqual, -1)
+		So(parsed[5].Kind(), ShouldEqual, "terminator")
+		So(parsed[6].Arg(), ShouldEqual, "-x")
+		So(parsed[6].Terminated(), ShouldBeTrue)
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet.Args(), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_Unnamed(t *testing.T) {
+	Convey("should return the unnamed arguments", t, func() {
+		flags := struct {
+			Foo  string   `short:"f"`
+			Rest []string `pos:"rest"`
+		}{}
+		args := []string{"./app", "-f", "foo", "bar", "--", "-b", "baz"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		var result []string
+		for _, arg := range flagSet.Unnamed() {
+			So(arg.Unnamed(), ShouldBeTrue)
+			result = append(result, arg.Arg())
+		}
+		So(result, ShouldResemble, []string{"bar", "-b", "baz"})
+	})
+
+	Convey("should not match the unnamed arguments by the flag names", t, func() {
+		flags := struct {
+			Foo    string   `short:"f"`
+			Config string   `long:"config"`
+			Rest   []string `pos:"rest"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "config", "f", "x"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeEmpty)
+		So(flags.Config, ShouldBeEmpty)
+		So(flags.Foo, ShouldBeEmpty)
+		So(flags.Rest, ShouldResemble, []string{"config", "f", "x"})
+	})
+}
+
+func TestFlagSet_Unknown(t *testing.T) {
+	Convey("should return the unknown arguments", t, func() {
+		flags := struct {
+			Foo        bool `short:"f"`
+			CommandBar struct {
+				Baz string `long:"baz"`
+			} `command:"bar"`
+		}{}
+		args := []string{"./app", "-f", "--qux=1", "-x", "bar", "--baz=1", "--quux", "2", "--", "--corge"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldHaveLength, 3)
+		So(flagSet.Unknown(), ShouldResemble, []string{"--qux=1", "-x", "--quux"})
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args, Partial: true})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.Unknown(), ShouldResemble, []string{"--qux=1", "-x", "--quux"})
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "-f"}})
+		So(err, ShouldBeNil)
+		So(flagSet.Unknown(), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_Unparsed(t *testing.T) {
+	Convey("should return the arguments those are consumed by nothing", t, func() {
+		flags := struct {
+			Foo        string `short:"f"`
+			CommandBar struct {
+				Baz string `long:"baz"`
+			} `command:"bar"`
+		}{}
+		args := []string{"./app", "-f", "foo", "--qux", "1", "bar", "--baz=1", "quux", "--", "corge"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, Partial: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Unparsed(), ShouldResemble, []string{"--qux", "1", "quux"})
+
+		flags02 := struct {
+			Foo  string   `short:"f"`
+			Rest []string `pos:"rest"`
+		}{}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags02, Args: []string{"./app", "-f", "foo", "a", "b"}})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.Unparsed(), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_PassthroughArgs(t *testing.T) {
+	Convey("should return the arguments after the terminator", t, func() {
+		flags := struct {
+			Foo        bool     `short:"f"`
+			Args       []string `pos:"rest"`
+			CommandBar struct {
+				Baz string `short:"b"`
+			} `command:"bar"`
+		}{}
+		args := []string{"./app", "-f", "--", "-f", "bar", "--", "-ab"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.PassthroughArgs(), ShouldResemble, []string{"-f", "bar", "--", "-ab"})
+		So(flagSet.FlagArgs("Foo"), ShouldResemble, []string{"true"})
+		So(flags.Foo, ShouldEqual, true)
+		So(flags.Args, ShouldResemble, []string{"-f", "bar", "--", "-ab"})
+		So(flagSet.FlagArgs("CommandBar"), ShouldBeNil)
+
+		args = []string{"./app", "bar", "-b", "baz", "--", "-b"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.PassthroughArgs(), ShouldResemble, []string{"-b"})
+		So(flags.CommandBar.Baz, ShouldEqual, "baz")
+		So(flagSet.FlagArgs("CommandBar"), ShouldResemble, []string{"bar", "-b=baz", "-b"})
+
+		args = []string{"./app", "-f"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.PassthroughArgs(), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_RawArgs(t *testing.T) {
+	Convey("should return the original arguments", t, func() {
+		flags := struct {
+			All        bool `short:"a"`
+			Bold       bool `short:"b"`
+			CommandFoo struct {
+				Name       string `short:"n"`
+				CommandBar struct {
+					Name string `short:"n"`
+				} `command:"bar"`
+			} `command:"foo"`
+			CommandExec struct {
+				Args []string `pos:"rest"`
+			} `command:"exec"`
+		}{}
+		args := []string{"./app", "-ab", "foo", "-nfoo", "'x y'", "bar", "-n=\"z\""}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.RawArgs(""), ShouldResemble, []string{"-ab"})
+		So(flagSet.RawArgs("CommandFoo"), ShouldResemble, []string{"-nfoo", "'x y'"})
+		So(flagSet.RawArgs("CommandFoo.CommandBar"), ShouldResemble, []string{"-n=\"z\""})
+		So(flagSet.RawArgs("CommandExec"), ShouldBeNil)
+		So(flagSet.RawArgs("All"), ShouldBeNil)
+		So(flagSet.RawArgs("Missing"), ShouldBeNil)
+
+		args = []string{"./app", "exec", "-ab", "--", "-x"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.RawArgs(""), ShouldBeNil)
+		So(flagSet.RawArgs("CommandExec"), ShouldResemble, []string{"-ab", "--", "-x"})
+	})
+}
+
+func TestFlagSet_Remaining(t *testing.T) {
+	Convey("should return the unknown arguments", t, func() {
+		flags := struct {
+			Verbose    bool `short:"v"`
+			CommandFoo struct {
+				Name string `short:"n"`
+			} `command:"foo"`
+		}{}
+		args := []string{"./app", "--bar", "baz", "qux", "-v", "foo", "-n=1", "-x=2", "--", "-y"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, Partial: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.Remaining(), ShouldResemble, []string{"--bar", "baz", "qux", "-x=2"})
+		So(flags.Verbose, ShouldEqual, true)
+		So(flags.CommandFoo.Name, ShouldEqual, "1")
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldHaveLength, 3)
+		So(flagSet.Remaining(), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_UnknownCommand(t *testing.T) {
+	Convey("should return the unknown command and its parent", t, func() {
+		flags := struct {
+			Verbose    bool `short:"v"`
+			CommandFoo struct {
+				CommandBar struct {
+				} `command:"bar"`
+			} `command:"foo"`
+		}{}
+		args := []string{"./app", "-v", "foo", "baz"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		name, parent := flagSet.UnknownCommand()
+		So(name, ShouldEqual, "baz")
+		So(parent, ShouldEqual, flagSet.FlagByName("CommandFoo"))
+
+		args = []string{"./app", "fo"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		name, parent = flagSet.UnknownCommand()
+		So(name, ShouldEqual, "fo")
+		So(parent, ShouldBeNil)
+
+		args = []string{"./app", "foo", "bar", "baz"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		name, parent = flagSet.UnknownCommand()
+		So(name, ShouldEqual, "")
+		So(parent, ShouldBeNil)
+	})
+}
+
+func TestFlagSet_ChainedCommands(t *testing.T) {
+	Convey("should return the chained commands in order", t, func() {
+		flags := struct {
+			CommandBuild struct {
+				Verbose bool `short:"v"`
+			} `command:"build"`
+			CommandTest struct {
+				Race bool `long:"race"`
+			} `command:"test"`
+			CommandPublish struct {
+				CommandDocker struct {
+				} `command:"docker"`
+			} `command:"publish"`
+		}{}
+		args := []string{"./app", "test", "--race", "build", "-v", "publish", "docker"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args, Chain: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.CommandTest.Race, ShouldEqual, true)
+		So(flags.CommandBuild.Verbose, ShouldEqual, true)
+		So(flagSet.ChainedCommands(), ShouldResemble, []*flagset.Flag{
+			flagSet.FlagByName("CommandTest"),
+			flagSet.FlagByName("CommandBuild"),
+			flagSet.FlagByName("CommandPublish.CommandDocker"),
+		})
+
+		args = []string{"./app", "test", "build"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("command build can't be used with command test")})
+
+		args = []string{"./app"}
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags, Args: args, Chain: true})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.ChainedCommands(), ShouldBeNil)
+	})
+}
+
+func TestFlagSet_CommandSuggestions(t *testing.T) {
+	Convey("should return the closest command names", t, func() {
+		flags := struct {
+			CommandStart struct {
+			} `command:"start"`
+			CommandStop struct {
+			} `command:"stop"`
+			CommandStat struct {
+				CommandStep struct {
+				} `command:"step"`
+			} `command:"stat"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.CommandSuggestions("sta", nil), ShouldResemble, []string{"stat"})
+		So(flagSet.CommandSuggestions("stot", nil), ShouldResemble, []string{"stop", "stat"})
+		So(flagSet.CommandSuggestions("xyz", nil), ShouldBeNil)
+		So(flagSet.CommandSuggestions("stap", flagSet.FlagByName("CommandStat")), ShouldResemble, []string{"step"})
+	})
+}
+
 func TestFlagSet_Flags(t *testing.T) {
 	Convey("should return flags", t, func() {
 		flags := struct {
@@ -2788,6 +4932,92 @@ func TestFlagSet_Flags(t *testing.T) {
 	})
 }
 
+func TestFlagSet_IsSet(t *testing.T) {
+	Convey("should return whether the value of the flag is provided explicitly or not", t, func() {
+		flags := struct {
+			Verbose    bool   `short:"v" long:"verbose"`
+			Host       string `long:"host" default:"localhost"`
+			CommandFoo struct {
+				Bar string `long:"bar"`
+			} `command:"foo"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "-v", "foo", "--bar=baz"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.IsSet("Verbose"), ShouldBeTrue)
+		So(flagSet.IsSet("verbose"), ShouldBeTrue)
+		So(flagSet.IsSet("CommandFoo.Bar"), ShouldBeTrue)
+		So(flagSet.IsSet("Host"), ShouldBeFalse)
+		So(flagSet.IsSet("Missing"), ShouldBeFalse)
+	})
+}
+
+func TestFlagSet_Visit(t *testing.T) {
+	Convey("should visit the flags those are set", t, func() {
+		os.Setenv("GOCMD_TEST_PORT", "8080")
+		defer os.Unsetenv("GOCMD_TEST_PORT")
+
+		flags := struct {
+			Bool       bool     `short:"b"`
+			Port       int      `long:"port" env:"GOCMD_TEST_PORT"`
+			Host       string   `long:"host" default:"localhost"`
+			String     string   `short:"s"`
+			Args       []string `pos:"rest"`
+			CommandFoo struct {
+				Bar string `long:"bar"`
+			} `command:"foo"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "a", "-b", "foo", "--bar=baz"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		var names []string
+		flagSet.Visit(func(flag *flagset.Flag) {
+			names = append(names, flag.Name())
+		})
+		So(names, ShouldResemble, []string{"Bool", "Port", "Args", "Bar"})
+	})
+}
+
+func TestFlagSet_VisitAll(t *testing.T) {
+	Convey("should visit all the flags", t, func() {
+		flags := struct {
+			Bool       bool `short:"b"`
+			String     string
+			CommandFoo struct {
+				Bar string `long:"bar"`
+			} `command:"foo"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		var names []string
+		flagSet.VisitAll(func(flag *flagset.Flag) {
+			names = append(names, flag.Name())
+		})
+		So(names, ShouldHaveLength, len(flagSet.Flags()))
+		So(names, ShouldContain, "Bool")
+		So(names, ShouldContain, "CommandFoo")
+		So(names, ShouldContain, "Bar")
+	})
+}
+
+func TestFlagSet_Err(t *testing.T) {
+	Convey("should return the flag and argument errors as a single error", t, func() {
+		flags := struct {
+			Foo bool `short:"f"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "-f"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Err(), ShouldBeNil)
+
+		So(flagSet.Parse([]string{"./app", "-x", "-y"}), ShouldResemble, flagSet.Err())
+		So(flagSet.Err(), ShouldResemble, flagset.MultiError(flagSet.Errors()))
+		So(flagSet.Err(), ShouldBeError, "unknown argument: -x\nunknown argument: -y")
+	})
+}
+
 func TestFlagSet_Errors(t *testing.T) {
 	Convey("should return flag errors", t, func() {
 		flags := struct {
@@ -2798,10 +5028,38 @@ func TestFlagSet_Errors(t *testing.T) {
 			"-b=foo",
 		}
 		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
-		So(err, ShouldBeNil)
+		So(err, ShouldResemble, flagSet.Err())
 		So(flagSet, ShouldNotBeNil)
 		flagErrors := flagSet.Errors()
 		So(flagErrors, ShouldNotBeNil)
-		So(flagErrors, ShouldContain, errors.New("failed to parse 'foo' as bool"))
+		So(errorMessages(flagErrors), ShouldContain, "failed to parse 'foo' as bool")
+	})
+}
+
+func TestFlagSet_Clone(t *testing.T) {
+	Convey("should clone the flag set by a copy of the flags", t, func() {
+		var b flagset.Builder
+		name := b.String("name", "", "Name", flagset.WithEnv("NAME"))
+		flags := struct {
+			Port int `long:"port" env:"PORT"`
+		}{}
+		os.Setenv("GOCMD_TEST_NAME", "foo")
+		defer os.Unsetenv("GOCMD_TEST_NAME")
+		flagSet, err := flagset.New(flagset.Options{Flags: []interface{}{&flags, &b}, Args: []string{"./app", "--port=80"}, EnvPrefix: "GOCMD_TEST_"})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+
+		clone, err := flagSet.Clone()
+		So(err, ShouldBeNil)
+		So(clone, ShouldNotBeNil)
+		So(clone.FlagByName("Port").Value(), ShouldEqual, 80)
+		So(clone.FlagByName("Name").Value(), ShouldEqual, "foo")
+		So(clone.FlagByName("Port").Env(), ShouldEqual, "GOCMD_TEST_PORT")
+		So(clone.Target().([]interface{})[0], ShouldNotEqual, &flags)
+
+		So(clone.Parse([]string{"./app", "--port=90", "--name=bar"}), ShouldBeNil)
+		So(clone.FlagByName("Port").Value(), ShouldEqual, 90)
+		So(flags.Port, ShouldEqual, 80)
+		So(*name, ShouldEqual, "foo")
 	})
 }
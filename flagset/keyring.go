@@ -0,0 +1,57 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// SecretStore represents a store of the secrets (i.e. the platform keychain, see `keyring` tag)
+type SecretStore interface {
+	// Secret returns the secret by the given service and account. It returns false when there is no secret
+	Secret(service, account string) (string, bool, error)
+}
+
+// Keyring represents the secret store of the platform keychain. It uses the `security` command on macOS
+// and the `secret-tool` command (libsecret) on the other Unix systems. There is no secret when the command
+// is not available or the platform is not supported.
+type Keyring struct{}
+
+// Secret returns the secret by the given service and account from the platform keychain
+func (k Keyring) Secret(service, account string) (string, bool, error) {
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		args = []string{"security", "find-generic-password", "-s", service, "-a", account, "-w"}
+	case "windows", "plan9", "js":
+		return "", false, nil
+	default:
+		args = []string{"secret-tool", "lookup", "service", service, "account", account}
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return "", false, nil
+	}
+	out, err := exec.Command(path, args[1:]...).Output()
+	if _, ok := err.(*exec.ExitError); ok {
+		return "", false, nil // not found
+	} else if err != nil {
+		return "", false, err
+	}
+	return strings.TrimRight(string(out), "\r\n"), true, nil
+}
+
+// parseKeyring parses the given keyring tag value and returns the service and the account
+// (i.e. `app` and `token` for `app/token`)
+func parseKeyring(value string) (string, string, bool) {
+	s := strings.SplitN(value, "/", 2)
+	if len(s) != 2 || strings.TrimSpace(s[0]) == "" || strings.TrimSpace(s[1]) == "" {
+		return "", "", false
+	}
+	return strings.TrimSpace(s[0]), strings.TrimSpace(s[1]), true
+}
@@ -0,0 +1,53 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"fmt"
+)
+
+var (
+	// English is the default localizer those returns the messages as is
+	English Localizer = Catalog{}
+)
+
+// Localizer represents a message catalog for the user-facing messages (i.e. the parse errors,
+// the warnings and the usage headings). The messages are identified by their English formats
+// (i.e. `argument %s is required`) so the translations must keep the same verbs in the same order.
+type Localizer interface {
+	// Localize returns the translation of the given message format
+	Localize(format string) string
+}
+
+// Catalog represents a localizer those maps the English message formats to their translations
+// The messages those have no translation fall back to English.
+type Catalog map[string]string
+
+// Localize returns the translation of the given message format
+func (c Catalog) Localize(format string) string {
+	if v, ok := c[format]; ok && v != "" {
+		return v
+	}
+	return format
+}
+
+// Localizer returns the localizer of the flag set
+func (flagSet *FlagSet) Localizer() Localizer {
+	if flagSet.localizer == nil {
+		return English
+	}
+	return flagSet.localizer
+}
+
+// message returns the translation of the given message format
+func (flagSet *FlagSet) message(format string) string {
+	return flagSet.Localizer().Localize(format)
+}
+
+// errorf returns an error by the translation of the given message format
+func (flagSet *FlagSet) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf(flagSet.message(format), a...)
+}
@@ -0,0 +1,54 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCatalog_Localize(t *testing.T) {
+	Convey("should return the translation of the message", t, func() {
+		catalog := flagset.Catalog{"argument %s is required": "das Argument %s ist erforderlich", "unknown argument: %s": ""}
+		So(catalog.Localize("argument %s is required"), ShouldEqual, "das Argument %s ist erforderlich")
+		So(catalog.Localize("unknown argument: %s"), ShouldEqual, "unknown argument: %s")
+		So(catalog.Localize("argument %s needs a value"), ShouldEqual, "argument %s needs a value")
+		So(flagset.English.Localize("argument %s is required"), ShouldEqual, "argument %s is required")
+	})
+}
+
+func TestFlagSet_Localizer(t *testing.T) {
+	Convey("should return the translated errors and warnings", t, func() {
+		flags := struct {
+			Name string `long:"name" required:"true" validate:"alpha"`
+			Old  struct {
+			} `command:"old" deprecated:"true"`
+		}{}
+		catalog := flagset.Catalog{
+			"argument %s is required":   "das Argument %s ist erforderlich",
+			"argument %s%s %s":          "das Argument %s%s %s",
+			"must contain letters only": "darf nur Buchstaben enthalten",
+			"command %s is deprecated":  "der Befehl %s ist veraltet",
+		}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "old"}, Localizer: catalog})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Localizer(), ShouldResemble, catalog)
+		So(errorMessages(flagSet.Errors()), ShouldResemble, []string{"das Argument --name ist erforderlich"})
+		So(flagSet.Warnings(), ShouldResemble, []string{"der Befehl old ist veraltet"})
+
+		So(flagSet.Parse([]string{"./app", "--name=foo1"}), ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("das Argument --name darf nur Buchstaben enthalten")})
+	})
+
+	Convey("should return the default localizer", t, func() {
+		flagSet, err := flagset.New(flagset.Options{Flags: &struct{}{}, Args: []string{"./app"}})
+		So(err, ShouldBeNil)
+		So(flagSet.Localizer(), ShouldEqual, flagset.English)
+	})
+}
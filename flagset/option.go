@@ -0,0 +1,75 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"io"
+)
+
+// Option represents an option of a flag set (i.e. `flagset.New(flagset.WithFlags(&flags), flagset.WithArgs(args))`)
+// The Options struct is an option too so it sets all of its fields at once and replaces the fields those
+// are set by the previous options (i.e. `flagset.New(flagset.Options{Flags: &flags}, flagset.WithStrict())`).
+type Option interface {
+	apply(o *options)
+}
+
+// options represents the options those are applied in order
+type options struct {
+	Options
+	strict bool
+	output io.Writer
+}
+
+// optionFunc represents an option by a function
+type optionFunc func(o *options)
+
+// apply applies the option
+func (f optionFunc) apply(o *options) {
+	f(o)
+}
+
+// apply applies the fields of the options
+func (o Options) apply(dst *options) {
+	dst.Options = o
+}
+
+// WithFlags returns an option for the flags (see Options.Flags)
+func WithFlags(flags interface{}) Option {
+	return optionFunc(func(o *options) { o.Flags = flags })
+}
+
+// WithArgs returns an option for the command line arguments (see Options.Args)
+func WithArgs(args []string) Option {
+	return optionFunc(func(o *options) { o.Args = args })
+}
+
+// WithEnvPrefix returns an option for the prefix of the env variable names (see Options.EnvPrefix)
+func WithEnvPrefix(prefix string) Option {
+	return optionFunc(func(o *options) { o.EnvPrefix = prefix })
+}
+
+// WithStrict returns an option those disables the lenient parsing regardless of the other options:
+// the partial mode, the case-insensitive long arguments and the lenient numbers (see Options)
+func WithStrict() Option {
+	return optionFunc(func(o *options) { o.strict = true })
+}
+
+// WithOutput returns an option for the writer of the warnings (i.e. os.Stderr). The warnings of
+// each parse are written line by line (see Warnings method). Otherwise they are only collected.
+func WithOutput(w io.Writer) Option {
+	return optionFunc(func(o *options) { o.output = w })
+}
+
+// applyOptions returns the options by applying the given options in order
+func applyOptions(opts []Option) options {
+	var result options
+	for _, v := range opts {
+		if v != nil {
+			v.apply(&result)
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOption(t *testing.T) {
+	Convey("should create a new flag set by the functional options", t, func() {
+		os.Setenv("GOCMD_TEST_APP_PORT", "8080")
+		defer os.Unsetenv("GOCMD_TEST_APP_PORT")
+
+		flags := struct {
+			Verbose bool   `short:"v"`
+			Port    int    `long:"port" env:"PORT"`
+			Name    string `long:"name"`
+		}{}
+		flagSet, err := flagset.New(flagset.WithFlags(&flags), flagset.WithArgs([]string{"./app", "-v"}), flagset.WithEnvPrefix("GOCMD_TEST_APP_"))
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flags.Verbose, ShouldBeTrue)
+		So(flags.Port, ShouldEqual, 8080)
+		So(flagSet.FlagByName("Port").Env(), ShouldEqual, "GOCMD_TEST_APP_PORT")
+
+		flagSet, err = flagset.New(flagset.Options{Flags: &flags}, flagset.WithArgs([]string{"./app", "--name=foo"}))
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flags.Verbose, ShouldBeFalse)
+		So(flags.Name, ShouldEqual, "foo")
+
+		flagSet, err = flagset.New(flagset.WithArgs([]string{"./app"}), flagset.Options{Flags: &flags})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.OriginalArgs(), ShouldResemble, os.Args[1:])
+
+		flagSet, err = flagset.New(flagset.WithArgs([]string{"./app"}))
+		So(err, ShouldBeError, errors.New("flags are required"))
+		So(flagSet, ShouldBeNil)
+	})
+
+	Convey("should disable the lenient parsing when it's strict", t, func() {
+		flags := struct {
+			Port int `long:"port"`
+		}{}
+		lenient := flagset.Options{Flags: &flags, Partial: true, CaseInsensitive: true, LenientNumbers: true}
+		flagSet, err := flagset.New(lenient, flagset.WithArgs([]string{"./app", "--PORT=1_000", "--foo"}))
+		So(err, ShouldBeNil)
+		So(flags.Port, ShouldEqual, 1000)
+
+		flagSet, err = flagset.New(lenient, flagset.WithArgs([]string{"./app", "--port=1_000"}), flagset.WithStrict())
+		So(err, ShouldBeError, "failed to parse '1_000' as int")
+		So(errors.Is(err, flagset.ErrParse), ShouldBeTrue)
+		So(flagSet, ShouldNotBeNil)
+
+		flagSet, err = flagset.Compile(lenient, flagset.WithStrict())
+		So(err, ShouldBeNil)
+		So(flagSet.Parse([]string{"./app", "--port=80"}), ShouldBeNil)
+		So(flags.Port, ShouldEqual, 80)
+		So(flagSet.Parse([]string{"./app", "--foo"}), ShouldBeError, "unknown argument: --foo")
+		So(flagSet.Parse([]string{"./app", "--PORT=80"}), ShouldBeError, "unknown argument: --PORT")
+	})
+
+	Convey("should write the warnings to the output", t, func() {
+		flags := struct {
+			Old struct{} `command:"old" deprecated:"renamed to sync"`
+		}{}
+		var buf bytes.Buffer
+		flagSet, err := flagset.New(flagset.WithFlags(&flags), flagset.WithArgs([]string{"./app", "old"}), flagset.WithOutput(&buf))
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(buf.String(), ShouldEqual, "command old is deprecated: renamed to sync\n")
+		So(flagSet.Warnings(), ShouldResemble, []string{"command old is deprecated: renamed to sync"})
+	})
+}
@@ -0,0 +1,150 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// defaultHTTPConfigSource is the config source of the HTTP(S) locations when there is no config source
+	defaultHTTPConfigSource = &HTTPConfigSource{}
+)
+
+// ConfigSource represents a source of the remote config files (i.e. `https://config.internal/app.json`)
+// The format of the content is determined by the extension of the location (see Options.ConfigFile).
+type ConfigSource interface {
+	// ReadConfig returns the content of the config file by the given location
+	ReadConfig(location string) ([]byte, error)
+}
+
+// HTTPConfigSource represents a config source those reads the config files over HTTP(S)
+type HTTPConfigSource struct {
+	// Header holds the request headers (i.e. `Authorization` for the auth tokens)
+	// They are only sent over HTTPS unless AllowInsecure is set.
+	Header http.Header
+	// AllowInsecure allows sending the headers over plain HTTP (i.e. for the local servers)
+	AllowInsecure bool
+	// Timeout is the time limit of the requests. Default is 10 seconds
+	Timeout time.Duration
+	// CacheTTL is the duration those the responses are used without a request. When it's expired
+	// the cached response is validated by its ETag or Last-Modified header. Default is 0 (the responses
+	// those have an ETag or Last-Modified header are validated by every request)
+	CacheTTL time.Duration
+	// Client is the HTTP client of the requests. Default is a client with the timeout. The redirects
+	// those would send the headers over insecure HTTP fail regardless of the client (see AllowInsecure)
+	Client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*httpConfigCache
+}
+
+// httpConfigCache represents a cached response of a config file
+type httpConfigCache struct {
+	content      []byte
+	etag         string
+	lastModified string
+	time         time.Time
+}
+
+// ReadConfig returns the content of the config file by the given URL
+func (s *HTTPConfigSource) ReadConfig(location string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Check the cache
+	cached := s.cache[location]
+	if cached != nil && time.Since(cached.time) < s.CacheTTL {
+		return cached.content, nil
+	}
+
+	// Send the request
+	req, err := http.NewRequest("GET", location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkScheme(req); err != nil {
+		return nil, err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+	client := &http.Client{Timeout: s.Timeout}
+	if client.Timeout <= 0 {
+		client.Timeout = 10 * time.Second
+	}
+	if s.Client != nil {
+		*client = *s.Client // a copy so the redirects are checked regardless of the given client
+	}
+	redirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := s.checkScheme(req); err != nil {
+			return err
+		} else if redirect != nil {
+			return redirect(req, via)
+		} else if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Check the response
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		cached.time = time.Now()
+		return cached.content, nil
+	} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if s.CacheTTL > 0 || etag != "" || lastModified != "" {
+		if s.cache == nil {
+			s.cache = map[string]*httpConfigCache{}
+		}
+		s.cache[location] = &httpConfigCache{
+			content:      b,
+			etag:         etag,
+			lastModified: lastModified,
+			time:         time.Now(),
+		}
+	}
+	return b, nil
+}
+
+// checkScheme checks whether the headers can be sent by the given request or not
+func (s *HTTPConfigSource) checkScheme(req *http.Request) error {
+	if len(s.Header) > 0 && req.URL.Scheme != "https" && !s.AllowInsecure {
+		return fmt.Errorf("headers can't be sent over insecure %s", req.URL.Scheme)
+	}
+	return nil
+}
+
+// isConfigURL returns whether the given config location is a URL or not (i.e. `https://host/app.json`)
+func isConfigURL(location string) bool {
+	return strings.Contains(location, "://")
+}
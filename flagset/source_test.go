@@ -0,0 +1,127 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHTTPConfigSource_ReadConfig(t *testing.T) {
+	requests, validated := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/app.json":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				validated++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprint(w, `{"port": 8080}`)
+		case "/app.ini":
+			fmt.Fprint(w, "port = 9090\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	header := http.Header{"Authorization": []string{"Bearer token"}}
+
+	Convey("should read the config files over HTTP", t, func() {
+		source := &flagset.HTTPConfigSource{Header: header, Timeout: time.Second, AllowInsecure: true}
+		b, err := source.ReadConfig(server.URL + "/app.json")
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"port": 8080}`)
+
+		flags := struct {
+			Port int `long:"port"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigFile: server.URL + "/app.ini?env=prod", ConfigSource: source})
+		So(err, ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flagSet.ConfigFile(), ShouldEqual, server.URL+"/app.ini?env=prod")
+		So(flags.Port, ShouldEqual, 9090)
+	})
+
+	Convey("should cache the config files", t, func() {
+		requests = 0
+		source := &flagset.HTTPConfigSource{Header: header, CacheTTL: time.Hour, AllowInsecure: true}
+		for i := 0; i < 2; i++ {
+			b, err := source.ReadConfig(server.URL + "/app.json")
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, `{"port": 8080}`)
+		}
+		So(requests, ShouldEqual, 1)
+
+		source.CacheTTL = time.Nanosecond // validated by the ETag
+		b, err := source.ReadConfig(server.URL + "/app.json")
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"port": 8080}`)
+		So(requests, ShouldEqual, 2)
+	})
+
+	Convey("should validate the config files by the ETag without a cache TTL", t, func() {
+		requests, validated = 0, 0
+		source := &flagset.HTTPConfigSource{Header: header, AllowInsecure: true}
+		for i := 0; i < 2; i++ {
+			b, err := source.ReadConfig(server.URL + "/app.json")
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, `{"port": 8080}`)
+		}
+		So(requests, ShouldEqual, 2)
+		So(validated, ShouldEqual, 1)
+	})
+
+	Convey("should fail to read the config files", t, func() {
+		source := &flagset.HTTPConfigSource{}
+		_, err := source.ReadConfig(server.URL + "/app.json")
+		So(err, ShouldBeError, errors.New("unexpected status 401 Unauthorized"))
+
+		requests = 0
+		source.Header = header
+		_, err = source.ReadConfig(server.URL + "/app.json")
+		So(err, ShouldBeError, errors.New("headers can't be sent over insecure http"))
+		So(requests, ShouldEqual, 0)
+
+		source.AllowInsecure = true
+		_, err = source.ReadConfig(server.URL + "/missing.json")
+		So(err, ShouldBeError, errors.New("unexpected status 404 Not Found"))
+
+		// The headers are not sent by the redirects from https to http regardless of the client
+		requests = 0
+		tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, server.URL+"/app.json", http.StatusFound)
+		}))
+		defer tlsServer.Close()
+		for _, client := range []*http.Client{tlsServer.Client(), {Transport: tlsServer.Client().Transport, CheckRedirect: func(*http.Request, []*http.Request) error { return nil }}} {
+			source = &flagset.HTTPConfigSource{Header: header, Client: client}
+			_, err = source.ReadConfig(tlsServer.URL + "/app.json")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEndWith, "headers can't be sent over insecure http")
+			So(requests, ShouldEqual, 0)
+		}
+
+		flags := struct {
+			Port int `long:"port"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app"}, ConfigFile: "s3://bucket/app.json"})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet.Errors(), ShouldResemble, []error{errors.New("failed to load config file s3://bucket/app.json due to unsupported location")})
+	})
+}
@@ -0,0 +1,67 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"errors"
+	"strings"
+)
+
+// SplitArgs splits the given command line into arguments by the shell-like rules.
+// Arguments are separated by whitespace, single quotes keep the characters as they are,
+// double quotes allow escaping `"` and `\` by backslash and backslash escapes any character
+// outside of quotes (i.e. [--name foo bar 'baz qux'] for `--name "foo bar" 'baz qux'`).
+func SplitArgs(s string) ([]string, error) {
+	var result []string
+	var cur strings.Builder
+	inArg := false // for empty quoted arguments (i.e. `""`)
+	quote := rune(0)
+	escape := false
+
+	for _, c := range s {
+		if escape {
+			// Inside of double quotes only the special characters can be escaped
+			if quote == '"' && c != '"' && c != '\\' {
+				cur.WriteRune('\\')
+			}
+			cur.WriteRune(c)
+			escape = false
+			continue
+		}
+
+		switch {
+		case c == '\\' && quote != '\'':
+			escape = true
+			inArg = true
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				result = append(result, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	if escape {
+		return nil, errors.New("unterminated escape character")
+	} else if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		result = append(result, cur.String())
+	}
+	return result, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSplitArgs(t *testing.T) {
+	Convey("should split the command line into arguments", t, func() {
+		args, err := flagset.SplitArgs("")
+		So(err, ShouldBeNil)
+		So(args, ShouldBeNil)
+
+		args, err = flagset.SplitArgs("  ./app  -f\t--bar=baz \n qux ")
+		So(err, ShouldBeNil)
+		So(args, ShouldResemble, []string{"./app", "-f", "--bar=baz", "qux"})
+
+		args, err = flagset.SplitArgs(`./app --name "foo bar" 'baz "qux"' --tag="a b"c ""`)
+		So(err, ShouldBeNil)
+		So(args, ShouldResemble, []string{"./app", "--name", "foo bar", `baz "qux"`, "--tag=a bc", ""})
+
+		args, err = flagset.SplitArgs(`foo\ bar "a\"b\\c\d" 'a\b' \'x`)
+		So(err, ShouldBeNil)
+		So(args, ShouldResemble, []string{"foo bar", `a"b\c\d`, `a\b`, "'x"})
+	})
+
+	Convey("should fail to split the command line", t, func() {
+		args, err := flagset.SplitArgs(`./app "foo`)
+		So(err, ShouldBeError, errors.New("unterminated quote"))
+		So(args, ShouldBeNil)
+
+		args, err = flagset.SplitArgs(`./app 'foo`)
+		So(err, ShouldBeError, errors.New("unterminated quote"))
+		So(args, ShouldBeNil)
+
+		args, err = flagset.SplitArgs(`./app foo\`)
+		So(err, ShouldBeError, errors.New("unterminated escape character"))
+		So(args, ShouldBeNil)
+	})
+
+	Convey("should parse the split arguments", t, func() {
+		flags := struct {
+			Name string `long:"name"`
+		}{}
+		args, err := flagset.SplitArgs(`./app --name "foo bar"`)
+		So(err, ShouldBeNil)
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Name, ShouldEqual, "foo bar")
+
+		So(flagSet.ParseString(`./app --name 'baz qux'`), ShouldBeNil)
+		So(flagSet.Errors(), ShouldBeNil)
+		So(flags.Name, ShouldEqual, "baz qux")
+
+		So(flagSet.ParseString(`./app --name "baz`), ShouldBeError, errors.New("unterminated quote"))
+	})
+}
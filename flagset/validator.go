@@ -0,0 +1,134 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string]ValidatorFunc{
+		"nonempty":     validateNonempty,
+		"url":          validateURL,
+		"email":        validateEmail,
+		"number":       validateNumber,
+		"integer":      validateInteger,
+		"alpha":        validateAlpha,
+		"alphanumeric": validateAlphanumeric,
+		"ip":           validateIP,
+		"port":         validatePort,
+	}
+)
+
+// ValidatorFunc represents a validator function for the flag values
+// It's called for each value (i.e. each element of the delimited values) and
+// the returned error is used as the reason (i.e. `must be a valid url`)
+type ValidatorFunc func(value string) error
+
+// RegisterValidator registers a validator by the given name
+// Registered validators can be used by the validate tag (i.e. `validate:"name"`) and
+// they override the built-in validators those have the same name.
+func RegisterValidator(name string, fn ValidatorFunc) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("invalid validator name")
+	} else if fn == nil {
+		return fmt.Errorf("invalid validator function for %s", name)
+	}
+	validatorsMu.Lock()
+	validators[name] = fn
+	validatorsMu.Unlock()
+	return nil
+}
+
+// validator returns the validator by the given name
+func validator(name string) (ValidatorFunc, bool) {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+	fn, ok := validators[name]
+	return fn, ok
+}
+
+// validateNonempty checks whether the value is not empty
+func validateNonempty(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("must not be empty")
+	}
+	return nil
+}
+
+// validateURL checks whether the value is an absolute URL
+func validateURL(value string) error {
+	if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("must be a valid url")
+	}
+	return nil
+}
+
+// validateEmail checks whether the value is an email address
+func validateEmail(value string) error {
+	if a, err := mail.ParseAddress(value); err != nil || a.Address != value {
+		return errors.New("must be a valid email")
+	}
+	return nil
+}
+
+// validateNumber checks whether the value is a number
+func validateNumber(value string) error {
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return errors.New("must be a number")
+	}
+	return nil
+}
+
+// validateInteger checks whether the value is an integer
+func validateInteger(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return errors.New("must be an integer")
+	}
+	return nil
+}
+
+// validateAlpha checks whether the value contains letters only
+func validateAlpha(value string) error {
+	if value == "" || strings.IndexFunc(value, func(r rune) bool { return !unicode.IsLetter(r) }) > -1 {
+		return errors.New("must contain letters only")
+	}
+	return nil
+}
+
+// validateAlphanumeric checks whether the value contains letters and digits only
+func validateAlphanumeric(value string) error {
+	if value == "" || strings.IndexFunc(value, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) > -1 {
+		return errors.New("must contain letters and digits only")
+	}
+	return nil
+}
+
+// validateIP checks whether the value is an IP address
+func validateIP(value string) error {
+	if net.ParseIP(value) == nil {
+		return errors.New("must be a valid ip address")
+	}
+	return nil
+}
+
+// validatePort checks whether the value is a port number
+func validatePort(value string) error {
+	if p, err := strconv.ParseUint(value, 10, 16); err != nil || p == 0 {
+		return errors.New("must be a valid port number")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package flagset_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegisterValidator(t *testing.T) {
+	Convey("should fail to register a validator", t, func() {
+		err := flagset.RegisterValidator("", func(value string) error { return nil })
+		So(err, ShouldBeError, errors.New("invalid validator name"))
+
+		err = flagset.RegisterValidator("test", nil)
+		So(err, ShouldBeError, errors.New("invalid validator function for test"))
+	})
+
+	Convey("should register a validator", t, func() {
+		err := flagset.RegisterValidator("test-lowercase", func(value string) error {
+			if strings.ToLower(value) != value {
+				return errors.New("must be lowercase")
+			}
+			return nil
+		})
+		So(err, ShouldBeNil)
+
+		flags := struct {
+			Name string `long:"name" validate:"test-lowercase"`
+		}{}
+		args := []string{"./app", "--name=Foo"}
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+		So(err, ShouldResemble, flagSet.Err())
+		So(flagSet, ShouldNotBeNil)
+		flagErrors := flagSet.Errors()
+		So(flagErrors, ShouldNotBeNil)
+		So(flagErrors, ShouldContain, errors.New("argument --name must be lowercase"))
+	})
+
+	Convey("should register the validators while parsing", t, func() {
+		flags := struct {
+			Name string `long:"name" validate:"test-concurrent"`
+		}{}
+		err := flagset.RegisterValidator("test-concurrent", func(value string) error { return nil })
+		So(err, ShouldBeNil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				flagset.RegisterValidator("test-concurrent", func(value string) error { return nil })
+			}()
+			go func() {
+				defer wg.Done()
+				flagset.New(flagset.Options{Flags: &struct {
+					Name string `long:"name" validate:"test-concurrent"`
+				}{}, Args: []string{"./app", "--name=foo"}})
+			}()
+		}
+		wg.Wait()
+
+		flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: []string{"./app", "--name=foo"}})
+		So(err, ShouldBeNil)
+		So(flagSet, ShouldNotBeNil)
+		So(flags.Name, ShouldEqual, "foo")
+	})
+}
+
+func TestValidators(t *testing.T) {
+	Convey("should validate the values by the built-in validators", t, func() {
+		tests := []struct {
+			validate string
+			valid    []string
+			invalid  []string
+			err      string
+		}{
+			{"nonempty", []string{"a", " a "}, []string{"", "  "}, "must not be empty"},
+			{"url", []string{"http://example.com", "https://example.com/a?b=c"}, []string{"example.com", "http://", "foo"}, "must be a valid url"},
+			{"email", []string{"foo@example.com"}, []string{"foo", "Foo <foo@example.com>"}, "must be a valid email"},
+			{"number", []string{"1", "-1.5", "1e3"}, []string{"a", "1,5"}, "must be a number"},
+			{"integer", []string{"1", "-10"}, []string{"1.5", "a"}, "must be an integer"},
+			{"alpha", []string{"abc", "äbc"}, []string{"", "ab1", "a b"}, "must contain letters only"},
+			{"alphanumeric", []string{"abc1"}, []string{"", "ab-1"}, "must contain letters and digits only"},
+			{"ip", []string{"127.0.0.1", "::1"}, []string{"256.0.0.1", "localhost"}, "must be a valid ip address"},
+			{"port", []string{"1", "8080", "65535"}, []string{"0", "65536", "-1", "a"}, "must be a valid port number"},
+		}
+		for _, test := range tests {
+			for _, v := range test.valid {
+				flags := struct {
+					Nonempty     string `long:"nonempty" validate:"nonempty"`
+					URL          string `long:"url" validate:"url"`
+					Email        string `long:"email" validate:"email"`
+					Number       string `long:"number" validate:"number"`
+					Integer      string `long:"integer" validate:"integer"`
+					Alpha        string `long:"alpha" validate:"alpha"`
+					Alphanumeric string `long:"alphanumeric" validate:"alphanumeric"`
+					IP           string `long:"ip" validate:"ip"`
+					Port         string `long:"port" validate:"port"`
+				}{}
+				args := []string{"./app", "--" + test.validate + "=" + v}
+				flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+				So(err, ShouldBeNil)
+				So(flagSet, ShouldNotBeNil)
+				So(flagSet.Errors(), ShouldBeNil)
+			}
+			for _, v := range test.invalid {
+				flags := struct {
+					Nonempty     string `long:"nonempty" validate:"nonempty"`
+					URL          string `long:"url" validate:"url"`
+					Email        string `long:"email" validate:"email"`
+					Number       string `long:"number" validate:"number"`
+					Integer      string `long:"integer" validate:"integer"`
+					Alpha        string `long:"alpha" validate:"alpha"`
+					Alphanumeric string `long:"alphanumeric" validate:"alphanumeric"`
+					IP           string `long:"ip" validate:"ip"`
+					Port         string `long:"port" validate:"port"`
+				}{}
+				args := []string{"./app", "--" + test.validate + "=" + v}
+				flagSet, err := flagset.New(flagset.Options{Flags: &flags, Args: args})
+				So(err, ShouldResemble, flagSet.Err())
+				So(flagSet, ShouldNotBeNil)
+				So(flagSet.Errors(), ShouldResemble, []error{fmt.Errorf("argument --%s %s", test.validate, test.err)})
+			}
+		}
+	})
+}
@@ -7,10 +7,13 @@
 package gocmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
@@ -31,7 +34,8 @@ type Options struct {
 	Version string
 	// Description is the command description
 	Description string
-	// Flags hold user defined command line arguments and commands
+	// Flags hold user defined command line arguments and commands (i.e. a struct pointer or
+	// a slice of struct pointers those are merged into one namespace, see flagset.Options)
 	Flags interface{}
 	// Logger represents the logger that is being used for printing errors
 	Logger Logger
@@ -45,25 +49,112 @@ type Options struct {
 	AutoVersion bool
 	// ExitOnError prints the error and exits the program when there is an error
 	ExitOnError bool
+	// AutoRun runs the deepest present command that implements the Runner interface (see Run method)
+	AutoRun bool
+	// Context is the context that is passed to the runners. Default is context.Background()
+	Context context.Context
+	// CaseInsensitive matches the long arguments regardless of the case
+	CaseInsensitive bool
+	// Ordered stops parsing at the first unnamed argument so the rest is kept as is
+	Ordered bool
+	// Partial collects the unknown arguments instead of failing (see Remaining method)
+	Partial bool
+	// Repeat is the default policy for the repeated arguments (last, first, error or append)
+	Repeat string
+	// LenientNumbers accepts the separators and the scientific notation for the numeric arguments
+	LenientNumbers bool
+	// Plugins runs the executables named `<name>-<command>` on PATH for the unknown top level commands
+	Plugins bool
+	// Recover converts the panics of the handlers and the runners into errors (see PanicError)
+	Recover bool
+	// FlagOrder is the order of the options in the usage: declaration (default), alphabetical or required-first
+	FlagOrder string
+	// CommandOrder is the order of the commands in the usage: declaration (default) or alphabetical
+	CommandOrder string
+	// HideDefaults hides the default values of the options in the usage (i.e. `(default: 8080)`)
+	HideDefaults bool
+	// HideEnvVars hides the env variable names of the options in the usage (i.e. `[$APP_PORT]`)
+	HideEnvVars bool
+	// HideRequired hides the required markers of the options in the usage (i.e. `(required)`)
+	HideRequired bool
+	// Color is the color mode of the usage content and the errors: auto (default), always or never.
+	// The auto mode enables the colors when the standard output is a terminal and `NO_COLOR` is not set
+	Color string
+	// Width is the width of the usage content for wrapping the descriptions. Default is the terminal width
+	// (no wrapping when the standard output is not a terminal). -1 disables the wrapping
+	Width int
+	// Chain runs the sibling commands in one invocation sequentially (i.e. `app build test publish`).
+	// It's the policy for the runner errors: stop (on the first error) or continue (see ChainError)
+	Chain string
+	// Localizer translates the errors, the warnings and the usage headings (see flagset.Localizer).
+	// Default is English
+	Localizer flagset.Localizer
+	// Pager pipes the usage through the pager (`$PAGER` or `less`) when it doesn't fit the terminal.
+	// The usage is printed as is when the standard output is not a terminal or there is no pager
+	Pager bool
+	// UsageOnError is what is printed after the flag errors: usage (of the present command), hint
+	// (i.e. `Run 'app deploy --help' for usage.`) or none. By default the usage is printed only for
+	// the unknown commands and the commands those require a subcommand
+	UsageOnError string
+	// ErrorWriter is the writer of the errors and the warnings when there is no logger (i.e. os.Stderr).
+	// Default is os.Stdout
+	ErrorWriter io.Writer
+	// Header is the text those is printed before the usage of all the commands
+	Header string
+	// Footer is the text those is printed after the usage of all the commands (i.e. support links)
+	Footer string
+	// Banner is the text those is printed before the top level usage only (i.e. ASCII art)
+	Banner string
+	// Topics are the help topics those are printed by the help command (i.e. `app help environment`)
+	// and listed in the top level usage
+	Topics []HelpTopic
+	// VersionFormat is the format of the version for the version flags (`-v`, `--version`): short (default),
+	// full or json. The format can be also given by the flag value (i.e. `--version=json`)
+	VersionFormat string
+	// HelpStyle is the layout of the usage content (see HelpStyle type)
+	HelpStyle HelpStyle
+	// ConfigFile is the path of the JSON or INI config file those values are used when the arguments
+	// and the env variables are not present (see flagset.Options.ConfigFile)
+	ConfigFile string
+	// ConfigFiles are the paths of the config files those are merged in order (see flagset.Options.ConfigFiles)
+	ConfigFiles []string
+	// ConfigSource reads the config files those have a URL (see flagset.Options.ConfigSource)
+	ConfigSource flagset.ConfigSource
+	// SecretStore is the store of the secrets for the keyring tags (see flagset.Options.SecretStore)
+	SecretStore flagset.SecretStore
+	// SecretsDir is the directory of the secret files (see flagset.Options.SecretsDir)
+	SecretsDir string
+	// ExpandConfig expands the env variables in the config values (see flagset.Options.ExpandConfig)
+	ExpandConfig bool
+	// ConfigPaths are the paths those are searched in order for the config file when there is no
+	// explicit one (see flagset.Options.ConfigPaths)
+	ConfigPaths []string
+	// Precedence is the order of the value sources: arg, env, config and default (see flagset.Options.Precedence)
+	Precedence []string
+	// EnvPrefix is the prefix of all the env variable names (i.e. `MYAPP_`, see flagset.Options.EnvPrefix)
+	EnvPrefix string
+	// AutoEnv derives the env variable names from the long names (i.e. `LOG_LEVEL` for `--log-level`)
+	// when there is no env tag (see flagset.Options.AutoEnv)
+	AutoEnv bool
+
+	builtins *flagset.Builder // built-in flags those are composed with the flags (see App.builtinFlags)
+}
+
+// HelpStyle represents the layout of the usage content. The zero values keep the defaults.
+type HelpStyle struct {
+	// Indent is the number of the spaces per level of the options and the commands. Default is 2
+	Indent int
+	// ColumnGap is the number of the spaces between the columns. Default is a space followed by a tab stop
+	ColumnGap int
+	// MaxWidth is the maximum width of the usage content for wrapping the descriptions (i.e. for wide terminals).
+	// It doesn't enable the wrapping when the width is unknown (see Options.Width)
+	MaxWidth int
+	// ShowTypes shows the value types of the options those have no placeholder (i.e. `--port int`)
+	ShowTypes bool
 }
 
 // New returns a command by the given options
 func New(o Options) (*Cmd, error) {
-	// Init the command
-	cmd := Cmd{
-		name:        o.Name,
-		version:     o.Version,
-		description: o.Description,
-		flags:       o.Flags,
-		flagSet:     &flagset.FlagSet{},
-		logger:      o.Logger,
-	}
-
-	// Check the logger
-	if cmd.logger == nil {
-		cmd.logger = log.New(os.Stdout, "", 0)
-	}
-
 	// Check the config type
 	switch o.ConfigType {
 	case ConfigTypeAuto:
@@ -73,98 +164,239 @@ func New(o Options) (*Cmd, error) {
 		o.ExitOnError = true
 	}
 
-	// If there is no any flag then
-	if o.Flags == nil {
-		return &cmd, nil
-	}
-
-	// Parse flags
-	var err error
-	cmd.flagSet, err = flagset.New(flagset.Options{Flags: o.Flags})
+	// Init the command and parse flags
+	cmd, err := newCmd(o)
 	if err != nil {
 		if o.ExitOnError {
-			cmd.logger.Printf("%s\n", err)
+			cmd.printError(err)
 			cmd.exit(1)
 		}
 		return nil, err
-	} else if (o.AnyError || o.ExitOnError) && len(cmd.flagSet.Errors()) > 0 {
-		if o.ExitOnError {
-			cmd.logger.Printf("%s\n", cmd.flagSet.Errors()[0])
-			cmd.exit(1)
-		}
-		return nil, cmd.flagSet.Errors()[0]
+	} else if o.Flags == nil {
+		return cmd, nil // there is no any flag
 	}
 
-	// Auto version
-	if o.AutoVersion {
-		ver := false
-		if f := cmd.flagSet.FlagByArg("v", ""); f != nil {
-			if v, ok := f.Value().(bool); ok && v {
-				ver = true
+	// Plugin commands
+	if o.Plugins {
+		if path, args := cmd.pluginCommand(); path != "" {
+			code, err := runPlugin(path, args)
+			if err != nil {
+				cmd.printError(err)
 			}
+			cmd.exit(code)
+			return cmd, err
 		}
-		if !ver {
-			if f := cmd.flagSet.FlagByArg("version", ""); f != nil {
-				if v, ok := f.Value().(bool); ok && v {
-					ver = true
-				}
-			}
+	}
+
+	// Help command
+	if o.AutoHelp {
+		var args []string
+		if len(os.Args) > 1 {
+			args = os.Args[1:]
 		}
-		verEx := false
-		if f := cmd.flagSet.FlagByArg("vv", ""); f != nil {
-			if v, ok := f.Value().(bool); ok && v {
-				verEx = true
+		if topic := cmd.helpTopic(args); topic != nil {
+			cmd.printUsageContent(cmd.topicContent(topic))
+			cmd.exit(0)
+			return cmd, nil
+		} else if ok, flag, err := cmd.helpCommand(args); ok && err == nil {
+			cmd.printUsageContent(cmd.commandUsageContent(flag))
+			cmd.exit(0)
+			return cmd, nil
+		}
+	}
+
+	// Help flags print the usage of the present command (i.e. `app foo --help`)
+	if o.AutoHelp && cmd.helpFlagged() {
+		cmd.printUsageContent(cmd.commandUsageContent(cmd.flagSet.ActiveCommand()))
+		cmd.exit(0)
+		return cmd, nil
+	}
+
+	// Version flags those have a format print the version regardless of their own parse errors
+	// (i.e. `--version=json` for a bool flag)
+	if o.AutoVersion {
+		if format, err := cmd.versionArgFormat(); err != nil && (o.AnyError || o.ExitOnError) {
+			if o.ExitOnError {
+				cmd.printError(err)
+				cmd.printErrorUsage(cmd.flagSet.ActiveCommand(), false, "")
+				cmd.exit(DefaultExitCode(&UsageError{Err: err}))
 			}
+			return nil, err
+		} else if format != "" {
+			cmd.PrintVersionFormat(format)
+			cmd.exit(0)
+			return cmd, nil
 		}
+	}
+
+	if (o.AnyError || o.ExitOnError) && len(cmd.flagSet.Errors()) > 0 {
+		err := cmd.flagSet.Errors()[0]
+		if o.ExitOnError {
+			cmd.printError(err)
+			cmd.printErrorUsage(cmd.flagSet.ActiveCommand(), false, "")
+			cmd.exit(DefaultExitCode(&UsageError{Err: err}))
+		}
+		return nil, err
+	}
 
-		if ver || verEx {
-			cmd.PrintVersion(verEx)
+	// Auto version
+	if o.AutoVersion {
+		if format, _ := cmd.versionFormat(); format != "" {
+			cmd.PrintVersionFormat(format)
 			cmd.exit(0)
 		}
 	}
 
 	// Auto help
 	if o.AutoHelp {
-		help := false
-		if len(os.Args) == 1 {
-			help = true
-		} else {
-			if f := cmd.flagSet.FlagByArg("h", ""); f != nil {
-				if v, ok := f.Value().(bool); ok && v {
-					help = true
-				}
-			}
-			if !help {
-				if f := cmd.flagSet.FlagByArg("help", ""); f != nil {
-					if v, ok := f.Value().(bool); ok && v {
-						help = true
-					}
-				}
-			}
-		}
-
-		if help {
+		if cmd.helpRequested() {
 			cmd.PrintUsage()
 			cmd.exit(0)
 		}
 	}
 
 	// Check handlers
-	sort.Sort(byFlagHandlerPriority(flagHandlers))
-	for _, v := range flagHandlers {
-		args := cmd.FlagArgs(v.name)
-		if cmd.FlagArgs(v.name) != nil {
-			err := v.handler(&cmd, args)
-			if err != nil {
-				if v.exitOnError {
-					cmd.logger.Printf("%s\n", err)
-					cmd.exit(1)
-				}
-				return nil, err
+	if fh, err := cmd.runHandlers(); err != nil {
+		if fh.exitOnError {
+			cmd.printError(err)
+			cmd.exit(1)
+		}
+		return nil, err
+	}
+
+	// Auto run
+	if o.AutoRun {
+		if err := cmd.Run(o.Context); err != nil {
+			if o.ExitOnError {
+				cmd.printError(err)
+				cmd.exit(1)
 			}
+			return nil, err
 		}
 	}
 
+	return cmd, nil
+}
+
+// newCmd returns a command by the given options after parsing the flags
+// The command is returned even if there is an error for logging purposes
+func newCmd(o Options) (*Cmd, error) {
+	// Init the command
+	cmd := Cmd{
+		name:         o.Name,
+		version:      o.Version,
+		description:  o.Description,
+		flags:        o.Flags,
+		flagSet:      &flagset.FlagSet{},
+		logger:       o.Logger,
+		recover:      o.Recover,
+		chain:        o.Chain,
+		width:        o.Width,
+		flagOrder:    o.FlagOrder,
+		commandOrder: o.CommandOrder,
+		hideDefaults: o.HideDefaults,
+		hideEnvVars:  o.HideEnvVars,
+		hideRequired: o.HideRequired,
+		localizer:    o.Localizer,
+		pager:        o.Pager,
+		usageOnError: o.UsageOnError,
+		header:       o.Header,
+		footer:       o.Footer,
+		banner:       o.Banner,
+		topics:       o.Topics,
+		versionFmt:   o.VersionFormat,
+		style:        o.HelpStyle,
+	}
+
+	// Check the logger
+	if cmd.logger == nil && o.ErrorWriter != nil {
+		cmd.logger = log.New(o.ErrorWriter, "", 0)
+	} else if cmd.logger == nil {
+		cmd.logger = log.New(os.Stdout, "", 0)
+	}
+
+	// Check the colors
+	color, err := colorEnabled(o.Color)
+	if err != nil {
+		return &cmd, err
+	}
+	cmd.color = color
+
+	// Built-in flags (i.e. `--dry-run`) are composed with the flags so they are parsed and listed as the others
+	if o.builtins != nil {
+		switch v := o.Flags.(type) {
+		case nil:
+			o.Flags = o.builtins
+		case []interface{}:
+			o.Flags = append(append([]interface{}{}, v...), o.builtins)
+		default:
+			o.Flags = []interface{}{v, o.builtins}
+		}
+	}
+
+	// If there is no any flag then
+	if o.Flags == nil {
+		return &cmd, nil
+	}
+	if o.Chain != "" && o.Chain != "stop" && o.Chain != "continue" {
+		return &cmd, fmt.Errorf("invalid chain policy %s", o.Chain)
+	}
+	switch o.FlagOrder {
+	case "", "declaration", "alphabetical", "required-first":
+	default:
+		return &cmd, fmt.Errorf("invalid flag order %s", o.FlagOrder)
+	}
+	switch o.CommandOrder {
+	case "", "declaration", "alphabetical":
+	default:
+		return &cmd, fmt.Errorf("invalid command order %s", o.CommandOrder)
+	}
+	switch o.UsageOnError {
+	case "", "usage", "hint", "none":
+	default:
+		return &cmd, fmt.Errorf("invalid usage on error mode %s", o.UsageOnError)
+	}
+	switch o.VersionFormat {
+	case "", "short", "full", "json":
+	default:
+		return &cmd, fmt.Errorf("invalid version format %s", o.VersionFormat)
+	}
+	topics := map[string]bool{}
+	for _, v := range o.Topics {
+		if v.Name == "" {
+			return &cmd, errors.New("help topic name is required")
+		} else if topics[v.Name] {
+			return &cmd, fmt.Errorf("help topic %s is already defined", v.Name)
+		}
+		topics[v.Name] = true
+	}
+
+	// Parse flags
+	flagSet, err := flagset.New(flagset.Options{
+		Flags:           o.Flags,
+		CaseInsensitive: o.CaseInsensitive,
+		Ordered:         o.Ordered,
+		Partial:         o.Partial,
+		Repeat:          o.Repeat,
+		LenientNumbers:  o.LenientNumbers,
+		Chain:           o.Chain != "",
+		Localizer:       o.Localizer,
+		ConfigFile:      o.ConfigFile,
+		ConfigFiles:     o.ConfigFiles,
+		ConfigSource:    o.ConfigSource,
+		SecretStore:     o.SecretStore,
+		SecretsDir:      o.SecretsDir,
+		ExpandConfig:    o.ExpandConfig,
+		ConfigPaths:     o.ConfigPaths,
+		Precedence:      o.Precedence,
+		EnvPrefix:       o.EnvPrefix,
+		AutoEnv:         o.AutoEnv,
+	})
+	if flagSet == nil {
+		return &cmd, err
+	}
+	cmd.flagSet = flagSet // the parse errors are handled by the callers (see FlagErrors)
+
 	return &cmd, nil
 }
 
@@ -180,12 +412,31 @@ const (
 
 // Cmd represents a command
 type Cmd struct {
-	name        string
-	version     string
-	description string
-	flags       interface{}
-	flagSet     *flagset.FlagSet
-	logger      Logger
+	name         string
+	version      string
+	description  string
+	flags        interface{}
+	flagSet      *flagset.FlagSet
+	logger       Logger
+	recover      bool
+	chain        string
+	dryRun       bool
+	width        int
+	color        bool
+	flagOrder    string
+	commandOrder string
+	hideDefaults bool
+	hideEnvVars  bool
+	hideRequired bool
+	localizer    flagset.Localizer
+	pager        bool
+	usageOnError string
+	header       string
+	footer       string
+	banner       string
+	topics       []HelpTopic
+	versionFmt   string
+	style        HelpStyle
 }
 
 // Name returns the name of the command
@@ -232,6 +483,38 @@ func (cmd *Cmd) FlagArgs(name string) []string {
 	return nil
 }
 
+// PassthroughArgs returns the arguments after the end-of-flags terminator (i.e. `--`)
+func (cmd *Cmd) PassthroughArgs() []string {
+	return cmd.flagSet.PassthroughArgs()
+}
+
+// RawArgs returns the original arguments of the given command as they are
+// Nested flags are separated by dot (i.e. Foo.Bar)
+func (cmd *Cmd) RawArgs(name string) []string {
+	return cmd.flagSet.RawArgs(name)
+}
+
+// Remaining returns the unknown arguments in partial mode
+func (cmd *Cmd) Remaining() []string {
+	return cmd.flagSet.Remaining()
+}
+
+// Warnings returns the warnings for the deprecated commands those are present
+func (cmd *Cmd) Warnings() []string {
+	return cmd.flagSet.Warnings()
+}
+
+// ConfigFile returns the path of the loaded config file (the last one when there are multiple
+// files) or empty string if there is none
+func (cmd *Cmd) ConfigFile() string {
+	return cmd.flagSet.ConfigFile()
+}
+
+// ConfigFiles returns the paths of the loaded config files in order
+func (cmd *Cmd) ConfigFiles() []string {
+	return cmd.flagSet.ConfigFiles()
+}
+
 // FlagErrors returns the list of the flag errors
 func (cmd *Cmd) FlagErrors() []error {
 	return cmd.flagSet.Errors()
@@ -250,8 +533,15 @@ func (cmd *Cmd) PrintVersion(extra bool) {
 
 	// Set version
 	if extra == true {
+		info := cmd.VersionInfo()
 		version += fmt.Sprintf("App name    : %s\n", cmd.Name())
 		version += fmt.Sprintf("App version : %s\n", strings.TrimPrefix(cmd.Version(), "v"))
+		if info.Revision != "" {
+			version += fmt.Sprintf("Revision    : %s\n", info.Revision)
+		}
+		if info.BuildDate != "" {
+			version += fmt.Sprintf("Build date  : %s\n", info.BuildDate)
+		}
 		version += fmt.Sprintf("Go version  : %s", goVersion)
 	} else {
 		version = fmt.Sprintf("%s", strings.TrimPrefix(cmd.Version(), "v"))
@@ -262,7 +552,7 @@ func (cmd *Cmd) PrintVersion(extra bool) {
 
 // PrintUsage prints usage
 func (cmd *Cmd) PrintUsage() {
-	fmt.Println(cmd.usageContent())
+	cmd.printUsageContent(cmd.usageContent())
 }
 
 // usageItem represents a usage item
@@ -272,6 +562,7 @@ type usageItem struct {
 	parentID int
 	left     string
 	right    string
+	group    string
 	level    int
 }
 
@@ -281,27 +572,44 @@ func (cmd *Cmd) usageItems(kind string, parentID int, level int) []*usageItem {
 	var result []*usageItem
 
 	// Iterate over the flags
+	var flags []*flagset.Flag
 	for _, flag := range cmd.flagSet.Flags() {
 		if flag.ParentID() != parentID {
 			continue
 		} else if kind != "" && flag.Kind() != kind {
 			continue
 		}
-
-		level = len(flag.FieldIndex())
-
+		flags = append(flags, flag)
+	}
+	filter := cmd.helpFilter()
+	for _, flag := range cmd.sortFlags(flags) {
+		level = len(cmd.commandPath(flag))
 		if flag.Kind() == "command" {
 			command := flag.Command()
+			right := flag.Description()
+			// Default commands run when no other command is given
+			if flag.DefaultCommand() {
+				right = fmt.Sprintf(cmd.message("%s (default)"), right)
+			}
+			if flag.Deprecated() != "" {
+				right = fmt.Sprintf(cmd.message("%s (deprecated)"), right)
+			}
 			result = append(result, &usageItem{
 				kind:     "command",
 				flagID:   flag.ID(),
 				parentID: parentID,
 				left:     command,
-				right:    flag.Description(),
+				right:    right,
 				level:    level,
 			})
 			result = append(result, cmd.usageItems("", flag.ID(), level)...)
 		} else if flag.Kind() == "arg" {
+			// Only the required options for `--help=required` and the advanced ones for `--help-all`
+			if filter == "required" && !flag.Required() {
+				continue
+			} else if filter != "all" && flag.Advanced() {
+				continue
+			}
 			arg := ""
 			if flag.Short() != "" && flag.Long() != "" {
 				arg = fmt.Sprintf("-%s, --%s", flag.Short(), flag.Long())
@@ -310,35 +618,37 @@ func (cmd *Cmd) usageItems(kind string, parentID int, level int) []*usageItem {
 			} else if flag.Long() != "" {
 				arg = fmt.Sprintf("    --%s", flag.Long())
 			}
-			right := flag.Description()
-			def := false
-			env := false
-			if flag.ValueDefault() != "" && flag.ValueDefault() != "false" {
-				def = true
+			if flag.Placeholder() != "" {
+				arg = fmt.Sprintf("%s %s", arg, flag.Placeholder())
+			} else if cmd.style.ShowTypes && flag.ValueType() != "bool" {
+				arg = fmt.Sprintf("%s %s", arg, flag.ValueType())
 			}
-			if flag.Env() != "" {
-				env = true
+			// Annotations (i.e. `Port (default: 8080) [$APP_PORT] (required)`)
+			right := flag.Description()
+			if v := flag.ValueDefault(); v != "" && v != "false" && !cmd.hideDefaults {
+				if flag.Secret() {
+					v = flagset.SecretPlaceholder
+				}
+				right = fmt.Sprintf(cmd.message("%s (default: %s)"), right, v)
 			}
-			if def || env {
-				right = fmt.Sprintf("%s (default", right)
+			if flag.Env() != "" && !cmd.hideEnvVars {
+				right = fmt.Sprintf("%s [$%s]", right, flag.Env())
 			}
-			if def {
-				right = fmt.Sprintf("%s %s", right, flag.ValueDefault())
-				if env {
-					right = fmt.Sprintf("%s - override $%s", right, flag.Env())
-				}
-			} else if env {
-				right = fmt.Sprintf("%s $%s", right, flag.Env())
+			// Global arguments are accepted after any command
+			if flag.Global() {
+				right = fmt.Sprintf(cmd.message("%s (global)"), right)
 			}
-			if def || env {
-				right = fmt.Sprintf("%s)", right)
+			if flag.Required() && !cmd.hideRequired {
+				right = fmt.Sprintf(cmd.message("%s (required)"), right)
 			}
+			right = strings.TrimSpace(right)
 			result = append(result, &usageItem{
 				kind:     "arg",
 				flagID:   flag.ID(),
 				parentID: parentID,
 				left:     arg,
 				right:    right,
+				group:    flag.Group(),
 				level:    level,
 			})
 		}
@@ -347,12 +657,73 @@ func (cmd *Cmd) usageItems(kind string, parentID int, level int) []*usageItem {
 	return result
 }
 
+// sortFlags returns the given flags of a command in the usage order (see Options.FlagOrder and Options.CommandOrder)
+// Arguments come before the commands unless both of them are in the declaration order.
+func (cmd *Cmd) sortFlags(flags []*flagset.Flag) []*flagset.Flag {
+	flagOrder := cmd.flagOrder != "" && cmd.flagOrder != "declaration"
+	commandOrder := cmd.commandOrder != "" && cmd.commandOrder != "declaration"
+	if !flagOrder && !commandOrder {
+		return flags
+	}
+	result := make([]*flagset.Flag, len(flags))
+	copy(result, flags)
+	key := func(f *flagset.Flag) string {
+		if f.Kind() == "command" {
+			return strings.ToLower(f.Command())
+		} else if f.Long() != "" {
+			return strings.ToLower(f.Long())
+		}
+		return strings.ToLower(f.Short())
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Kind() != b.Kind() {
+			return a.Kind() == "arg"
+		} else if a.Kind() == "command" {
+			return commandOrder && key(a) < key(b)
+		} else if !flagOrder {
+			return false
+		} else if cmd.flagOrder == "required-first" {
+			return a.Required() && !b.Required()
+		}
+		return key(a) < key(b)
+	})
+	return result
+}
+
+// PrintCommandUsage prints the usage of the given command
+// Nested commands are separated by dot (i.e. Foo.Bar)
+func (cmd *Cmd) PrintCommandUsage(name string) {
+	if flag := cmd.flagSet.FlagByName(name); flag != nil && flag.Kind() == "command" {
+		cmd.printUsageContent(cmd.commandUsageContent(flag))
+	}
+}
+
 // usageContent parses the flags and return the usage content
 func (cmd *Cmd) usageContent() string {
+	return cmd.commandUsageContent(nil)
+}
+
+// commandUsageContent returns the usage content of the given command or the top level one for nil
+func (cmd *Cmd) commandUsageContent(flag *flagset.Flag) string {
 	// Init vars
 	hasOpt := false
 	hasCmd := false
-	usageItems := cmd.usageItems("", -1, 0)
+	parentID := -1
+	base := 0 // level of the command
+	name := cmd.name
+	description := cmd.description
+	if flag != nil {
+		parentID = flag.ID()
+		base = len(cmd.commandPath(flag))
+		name = strings.TrimSpace(name + " " + strings.Join(cmd.commandPath(flag), " "))
+		description = flag.Description()
+		// Long descriptions replace the short ones in the usage of their commands
+		if flag.LongDescription() != "" {
+			description = flag.LongDescription()
+		}
+	}
+	usageItems := cmd.usageItems("", parentID, 0)
 	for _, v := range usageItems {
 		if v.kind == "arg" {
 			hasOpt = true
@@ -363,40 +734,62 @@ func (cmd *Cmd) usageContent() string {
 			continue
 		}
 	}
-	t := table.New(table.Options{})
+	t := cmd.usageTable()
+
+	// Banner (top level only) and header
+	usage := ""
+	if flag == nil && strings.TrimSpace(cmd.banner) != "" {
+		usage += strings.Trim(cmd.banner, "\n") + "\n\n"
+	}
+	if cmd.header != "" {
+		usage += strings.TrimSpace(cmd.header) + "\n\n"
+	}
 
-	// Header and description
-	usage := "Usage: " + cmd.name
+	// Usage line and description
+	usage += cmd.colorize(cmd.message("Usage:"), colorBold) + " " + name
 	if hasOpt {
-		usage += " [options...]"
+		usage += " " + cmd.message("[options...]")
 	}
 	if hasCmd {
-		usage += " COMMAND [options...]"
+		usage += " " + cmd.message("COMMAND [options...]")
 	}
 	usage += "\n\n"
-	if cmd.description != "" {
-		usage += cmd.description + "\n\n"
+	if description != "" {
+		usage += description + "\n\n"
 	}
 
 	// Options
 	if hasOpt {
-		t.AddRow("Options:")
-		for _, v := range usageItems {
-			if v.kind == "arg" && v.parentID == -1 {
-				t.AddRow(fmt.Sprintf("%s%s ", strings.Repeat("  ", v.level), v.left), v.right)
+		groups, options := cmd.groupOptions(flag, usageItems, parentID)
+		for _, g := range groups {
+			if g == "" {
+				t.AddRow(cmd.colorize(cmd.message("Options:"), colorBold))
+			} else {
+				t.AddRow(cmd.colorize(g+":", colorBold))
 			}
+			for _, v := range options[g] {
+				t.AddRow(cmd.usageCell(cmd.colorize(v.left, colorCyan), v.level-base), v.right)
+			}
+			t.AddRow(" ")
 		}
-		t.AddRow(" ")
 	}
 
 	if hasCmd {
-		t.AddRow("Commands:")
-		l := len(usageItems)
-		for i := 0; i < l; i++ {
-			v := usageItems[i]
-			if v.kind == "command" || (v.kind == "arg" && v.parentID != -1) {
-				// Commands and their arguments are already sorted
-				t.AddRow(fmt.Sprintf("%s%s ", strings.Repeat("  ", v.level), v.left), v.right)
+		t.AddRow(cmd.colorize(cmd.message("Commands:"), colorBold))
+		for _, v := range usageItems {
+			if v.kind != "command" {
+				continue
+			}
+			// Commands are already sorted and their options are grouped like the top level ones
+			t.AddRow(cmd.usageCell(cmd.colorize(v.left, colorCyan), v.level-base), v.right)
+			groups, options := cmd.groupOptions(cmd.flagByID(v.flagID), usageItems, v.flagID)
+			for _, g := range groups {
+				if g != "" {
+					t.AddRow(cmd.usageCell(cmd.colorize(g+":", colorBold), options[g][0].level-base))
+				}
+				for _, vv := range options[g] {
+					t.AddRow(cmd.usageCell(cmd.colorize(vv.left, colorCyan), vv.level-base), vv.right)
+				}
 			}
 		}
 	}
@@ -405,9 +798,361 @@ func (cmd *Cmd) usageContent() string {
 		usage += t.FormattedData()
 	}
 
+	// Help topics of the top level usage
+	if flag == nil && cmd.topics != nil {
+		usage = sectionBreak(usage) + cmd.colorize(cmd.message("Help topics:"), colorBold) + "\n"
+		tt := cmd.usageTable()
+		for _, v := range cmd.topics {
+			tt.AddRow(cmd.usageCell(cmd.colorize(v.Name, colorCyan), 1), v.Description)
+		}
+		usage += tt.FormattedData()
+	}
+
+	// Examples of the command and its flags
+	var examples []string
+	if flag != nil {
+		examples = append(examples, flag.Examples()...)
+	}
+	for _, v := range cmd.flagSet.Flags() {
+		if v.ParentID() == parentID {
+			examples = append(examples, v.Examples()...)
+		}
+	}
+	if examples != nil {
+		usage = sectionBreak(usage) + cmd.colorize(cmd.message("Examples:"), colorBold) + "\n"
+		for _, v := range examples {
+			usage += cmd.indent(1) + v + "\n"
+		}
+	}
+
+	// Related commands
+	if flag != nil && flag.SeeAlso() != nil {
+		usage = sectionBreak(usage) + cmd.colorize(cmd.message("See also:"), colorBold) + "\n"
+		for _, v := range flag.SeeAlso() {
+			usage += cmd.indent(1) + v + "\n"
+		}
+	}
+
+	// Footer
+	if cmd.footer != "" {
+		usage = sectionBreak(usage) + strings.TrimSpace(cmd.footer) + "\n"
+	}
+
 	return usage
 }
 
+// printErrorUsage prints the usage of the given command with the given extra content, a hint or
+// nothing after the flag errors by the usage on error mode (see Options.UsageOnError).
+// The default mode prints the usage only when it's forced (i.e. unknown commands).
+func (cmd *Cmd) printErrorUsage(flag *flagset.Flag, forced bool, extra string) {
+	switch cmd.usageOnError {
+	case "none":
+		return
+	case "hint":
+		name := cmd.name
+		if name == "" && len(os.Args) > 0 {
+			name = filepath.Base(os.Args[0])
+		}
+		if flag != nil {
+			name = strings.TrimSpace(name + " " + strings.Join(cmd.commandPath(flag), " "))
+		}
+		cmd.logger.Printf("%s\n", fmt.Sprintf(cmd.message("Run '%s --help' for usage."), name))
+		return
+	case "":
+		if !forced {
+			return
+		}
+	}
+	cmd.logger.Printf("%s\n", cmd.commandUsageContent(flag)+extra)
+}
+
+// sectionBreak returns the given usage content by ending it with a blank line for the next section
+func sectionBreak(usage string) string {
+	return strings.TrimRight(usage, " \n") + "\n\n"
+}
+
+// usageWidth returns the width of the usage content (0 for no wrapping)
+func (cmd *Cmd) usageWidth() int {
+	width := cmd.width
+	if width < 0 {
+		return 0
+	} else if width == 0 {
+		width = terminalWidth()
+	}
+	if cmd.style.MaxWidth > 0 && width > cmd.style.MaxWidth {
+		return cmd.style.MaxWidth
+	}
+	return width
+}
+
+// usageTable returns a table for the usage content by the help style
+func (cmd *Cmd) usageTable() *table.Table {
+	o := table.Options{Width: cmd.usageWidth()}
+	if cmd.style.ColumnGap > 0 {
+		o.Separator = strings.Repeat(" ", cmd.style.ColumnGap)
+	}
+	return table.New(o)
+}
+
+// usageCell returns the left column of a usage table row by the given level
+// The default column gap is a space followed by a tab stop.
+func (cmd *Cmd) usageCell(val string, level int) string {
+	val = cmd.indent(level) + val
+	if cmd.style.ColumnGap > 0 {
+		return val
+	}
+	return val + " "
+}
+
+// indent returns the indentation of the usage content by the given level (see HelpStyle.Indent)
+func (cmd *Cmd) indent(level int) string {
+	size := cmd.style.Indent
+	if size <= 0 {
+		size = 2
+	}
+	return strings.Repeat(" ", size*level)
+}
+
+// groupShown returns whether the given group is shown in the usage of the given command or not
+// (see `show-groups` and `hide-groups` tags). Ungrouped options are always shown.
+func groupShown(flag *flagset.Flag, group string) bool {
+	if flag == nil || group == "" {
+		return true
+	}
+	for _, v := range flag.HideGroups() {
+		if v == group {
+			return false
+		}
+	}
+	if flag.ShowGroups() == nil {
+		return true
+	}
+	for _, v := range flag.ShowGroups() {
+		if v == group {
+			return true
+		}
+	}
+	return false
+}
+
+// groupOptions returns the options of the given command (nil for the top level) in the given usage items
+// by their groups those are shown in the usage. Ungrouped options come first (empty group name) and
+// grouped ones follow them by the order of the groups.
+func (cmd *Cmd) groupOptions(flag *flagset.Flag, usageItems []*usageItem, parentID int) ([]string, map[string][]*usageItem) {
+	var groups []string
+	options := map[string][]*usageItem{}
+	for _, v := range usageItems {
+		if v.kind != "arg" || v.parentID != parentID {
+			continue
+		} else if !groupShown(flag, v.group) && cmd.helpFilter() != "all" {
+			continue
+		}
+		if _, ok := options[v.group]; !ok && v.group != "" {
+			groups = append(groups, v.group)
+		}
+		options[v.group] = append(options[v.group], v)
+	}
+	if len(options[""]) > 0 {
+		groups = append([]string{""}, groups...)
+	}
+	return groups, options
+}
+
+// flagByID returns the flag by the given id or returns nil if it doesn't exist
+func (cmd *Cmd) flagByID(id int) *flagset.Flag {
+	for _, v := range cmd.flagSet.Flags() {
+		if v.ID() == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// commandPath returns the command names from the top level one to the given command (i.e. [foo bar])
+func (cmd *Cmd) commandPath(flag *flagset.Flag) []string {
+	var result []string
+	for flag != nil {
+		result = append([]string{flag.Command()}, result...)
+		flag = cmd.flagByID(flag.ParentID())
+	}
+	return result
+}
+
+// invocationName returns the command name with the path of the present commands (i.e. `app deploy rollback`)
+func (cmd *Cmd) invocationName() string {
+	name := cmd.name
+	if name == "" && len(os.Args) > 0 {
+		name = filepath.Base(os.Args[0])
+	}
+	result := []string{name}
+	for _, f := range cmd.flagSet.ChainedCommands() {
+		result = append(result, cmd.commandPath(f)...)
+	}
+	return strings.Join(result, " ")
+}
+
+// subcommands returns the names of the subcommands of the given command
+func (cmd *Cmd) subcommands(flag *flagset.Flag) []string {
+	var result []string
+	for _, v := range cmd.flagSet.Flags() {
+		if v.Kind() == "command" && v.ParentID() == flag.ID() {
+			result = append(result, v.Command())
+		}
+	}
+	return result
+}
+
+// hasHandler returns whether the given flag has a handler or not (see HandleFlag)
+func (cmd *Cmd) hasHandler(flag *flagset.Flag) bool {
+	for _, v := range flagHandlers {
+		if cmd.flagSet.FlagByName(v.name) == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// helpCommand returns whether the help command is requested by the given arguments (i.e. `help foo bar`)
+// and the flag of the given command path (nil for top level). The help command is not available
+// when there is a top level command with the same name.
+func (cmd *Cmd) helpCommand(args []string) (bool, *flagset.Flag, error) {
+	if len(args) == 0 || args[0] != "help" {
+		return false, nil, nil
+	}
+	for _, v := range cmd.flagSet.Flags() {
+		if v.Kind() == "command" && v.ParentID() == -1 && v.Command() == "help" {
+			return false, nil, nil
+		}
+	}
+
+	// Iterate over the command path
+	var result *flagset.Flag
+	for _, name := range args[1:] {
+		parentID := -1
+		if result != nil {
+			parentID = result.ID()
+		}
+		var flag *flagset.Flag
+		for _, v := range cmd.flagSet.Flags() {
+			if v.Kind() == "command" && v.ParentID() == parentID && v.Command() == name {
+				flag = v
+				break
+			}
+		}
+		if flag == nil {
+			return true, result, cmd.errorf("unknown command: %s", name)
+		}
+		result = flag
+	}
+	return true, result, nil
+}
+
+// versionRequested returns whether the version flags (`-v`, `--version` and `--vv` for extended) are present or not
+func (cmd *Cmd) versionRequested() (bool, bool) {
+	ver := false
+	if f := cmd.flagSet.FlagByArg("v", ""); f != nil {
+		if v, ok := f.Value().(bool); ok && v {
+			ver = true
+		}
+	}
+	if !ver {
+		if f := cmd.flagSet.FlagByArg("version", ""); f != nil {
+			if v, ok := f.Value().(bool); ok && v {
+				ver = true
+			}
+		}
+	}
+	verEx := false
+	if f := cmd.flagSet.FlagByArg("vv", ""); f != nil {
+		if v, ok := f.Value().(bool); ok && v {
+			verEx = true
+		}
+	}
+	return ver, verEx
+}
+
+// helpRequested returns whether the help flags (`-h`, `--help`) are present or there is no argument
+// (unless there is a default command)
+func (cmd *Cmd) helpRequested() bool {
+	if len(os.Args) == 1 && cmd.flagSet.ActiveCommand() == nil {
+		return true
+	}
+	return cmd.helpFlagged()
+}
+
+// helpFlagged returns whether the help flags (`-h`, `--help`) are present at any command level
+// The help flags those are not defined by the flags are detected in the arguments (i.e. `app foo --help`)
+func (cmd *Cmd) helpFlagged() bool {
+	for _, f := range cmd.flagSet.Flags() {
+		if f.Kind() == "arg" && f.ParentID() != -1 && (f.Short() == "h" || f.Long() == "help") {
+			if v, ok := f.Value().(bool); ok && v {
+				return true
+			}
+		}
+	}
+	for _, name := range []string{"h", "help"} {
+		f := cmd.flagSet.FlagByArg(name, "")
+		if f != nil {
+			if v, ok := f.Value().(bool); ok && v {
+				return true
+			}
+			continue
+		}
+		arg := "--" + name
+		if len(name) == 1 {
+			arg = "-" + name
+		}
+		for _, v := range cmd.flaggedArgs() {
+			if v == arg {
+				return true
+			}
+		}
+	}
+	return cmd.helpFilter() != ""
+}
+
+// helpFilter returns the filter of the usage content by the help flag (i.e. required for `--help=required`
+// or all for `--help-all` those shows the advanced options and the hidden groups too)
+func (cmd *Cmd) helpFilter() string {
+	for _, v := range cmd.flaggedArgs() {
+		if v == "--help=required" {
+			return "required"
+		} else if v == "--help-all" {
+			return "all"
+		}
+	}
+	return ""
+}
+
+// flaggedArgs returns the parsed arguments before the end-of-flags terminator or the first unnamed
+// argument in ordered mode (i.e. [run] for `app run kubectl -h` so `-h` is passed through)
+func (cmd *Cmd) flaggedArgs() []string {
+	var result []string
+	for _, arg := range cmd.flagSet.Args() {
+		if arg.Kind() == "terminator" || arg.Terminated() {
+			break
+		}
+		result = append(result, arg.Arg())
+	}
+	return result
+}
+
+// runHandlers runs the handlers of the present flags by their priorities
+// It returns the failed handler and its error if any
+func (cmd *Cmd) runHandlers() (*FlagHandler, error) {
+	sort.Sort(byFlagHandlerPriority(flagHandlers))
+	for _, v := range flagHandlers {
+		args := cmd.FlagArgs(v.name)
+		if args != nil {
+			handler := v.handler
+			if err := cmd.safely(func() error { return handler(cmd, args) }); err != nil {
+				return v, err
+			}
+		}
+	}
+	return nil, nil
+}
+
 func (cmd *Cmd) isTest() bool {
 	if len(os.Args) > 0 {
 		if strings.Contains(os.Args[0], "gocmd.test") {
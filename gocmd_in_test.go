@@ -6,8 +6,20 @@
 package gocmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/devfacet/gocmd/flagset"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -84,13 +96,13 @@ func TestCmd_usageItems(t *testing.T) {
 		So(usageItems[3].right, ShouldEqual, "Test quux")
 		So(usageItems[3].level, ShouldEqual, 3)
 		So(usageItems[4].left, ShouldEqual, "-s, --string")
-		So(usageItems[4].right, ShouldEqual, "Test (default /go - override $GOPATH)")
+		So(usageItems[4].right, ShouldEqual, "Test (default: /go) [$GOPATH]")
 		So(usageItems[4].level, ShouldEqual, 3)
 		So(usageItems[5].left, ShouldEqual, "-d, --default")
-		So(usageItems[5].right, ShouldEqual, "Test (default default)")
+		So(usageItems[5].right, ShouldEqual, "Test (default: default)")
 		So(usageItems[5].level, ShouldEqual, 3)
 		So(usageItems[6].left, ShouldEqual, "-e, --env")
-		So(usageItems[6].right, ShouldEqual, "Test (default $GOPATH)")
+		So(usageItems[6].right, ShouldEqual, "Test [$GOPATH]")
 		So(usageItems[6].level, ShouldEqual, 3)
 	})
 }
@@ -115,7 +127,332 @@ func TestCmd_usageContent(t *testing.T) {
 		So(cmd, ShouldNotBeNil)
 		usage := cmd.usageContent()
 		So(usage, ShouldNotBeEmpty)
-		So(usage, ShouldEqual, "Usage: test [options...] COMMAND [options...]\n\nTest\n\nOptions:\n  -f, --foo    \tTest foo\n  -b           \tTest bar\n      --baz    \tTest baz\n\nCommands:\n  qux          \tQux command\n    -f, --foo  \tTest foo\n        --quux \tTest quux (default test)\n")
+		So(usage, ShouldEqual, "Usage: test [options...] COMMAND [options...]\n\nTest\n\nOptions:\n  -f, --foo    \tTest foo\n  -b           \tTest bar\n      --baz    \tTest baz\n\nCommands:\n  qux          \tQux command\n    -f, --foo  \tTest foo\n        --quux \tTest quux (default: test)\n")
+	})
+
+	Convey("should return correct usage content (group)", t, func() {
+		cmd, err := New(Options{
+			Name:        "test",
+			Version:     "1.0.0",
+			Description: "Test",
+			Flags: &struct {
+				Host    string `long:"host" group:"Networking" description:"Test host"`
+				Verbose bool   `short:"v" description:"Test verbose"`
+				Port    int    `long:"port" group:"Networking" description:"Test port"`
+				Debug   bool   `long:"debug" group:"Debugging" description:"Test debug"`
+			}{},
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		usage := cmd.usageContent()
+		So(usage, ShouldNotBeEmpty)
+		So(usage, ShouldEqual, "Usage: test [options...]\n\nTest\n\nOptions:\n  -v          \tTest verbose\n\nNetworking:\n      --host  \tTest host\n      --port  \tTest port\n\nDebugging:\n      --debug \tTest debug\n\n")
+
+		cmd, err = New(Options{
+			Name: "test",
+			Flags: &struct {
+				Foo struct {
+					Host    string `long:"host" group:"Networking" description:"Test host"`
+					Verbose bool   `short:"v" description:"Test verbose"`
+					Bar     struct {
+						Debug bool `long:"debug" group:"Debugging" description:"Test debug"`
+						Port  int  `long:"port" description:"Test port"`
+					} `command:"bar" description:"Bar command"`
+				} `command:"foo" description:"Foo command"`
+			}{},
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.usageContent(), ShouldEqual, "Usage: test [options...] COMMAND [options...]\n\nCommands:\n  foo             \tFoo command\n    -v            \tTest verbose\n    Networking:\n        --host    \tTest host\n    bar           \tBar command\n          --port  \tTest port\n      Debugging:\n          --debug \tTest debug\n")
+	})
+
+	Convey("should return correct usage content (secret)", t, func() {
+		cmd, err := New(Options{
+			Name:        "test",
+			Version:     "1.0.0",
+			Description: "Test",
+			Flags: &struct {
+				Token string `long:"token" default:"hunter2" env:"TOKEN" secret:"true" description:"Test token"`
+			}{},
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		usage := cmd.usageContent()
+		So(usage, ShouldNotBeEmpty)
+		So(usage, ShouldNotContainSubstring, "hunter2")
+		So(usage, ShouldEqual, "Usage: test [options...]\n\nTest\n\nOptions:\n      --token \tTest token (default: ***) [$TOKEN]\n\n")
+	})
+
+	Convey("should return correct usage content (placeholder)", t, func() {
+		cmd, err := New(Options{
+			Name:        "test",
+			Version:     "1.0.0",
+			Description: "Test",
+			Flags: &struct {
+				Config string `short:"c" long:"config" placeholder:"FILE" description:"Test config"`
+				Port   int    `long:"port" placeholder:"PORT" description:"Test port"`
+				Debug  bool   `short:"d" description:"Test debug"`
+			}{},
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		usage := cmd.usageContent()
+		So(usage, ShouldNotBeEmpty)
+		So(usage, ShouldEqual, "Usage: test [options...]\n\nTest\n\nOptions:\n  -c, --config FILE \tTest config\n      --port PORT   \tTest port\n  -d                \tTest debug\n\n")
+	})
+
+	Convey("should return correct usage content (flag order)", t, func() {
+		flags := &struct {
+			Verbose bool   `short:"v" long:"verbose" description:"Test verbose"`
+			Name    string `long:"name" required:"true" description:"Test name"`
+			Debug   bool   `long:"debug" description:"Test debug"`
+		}{}
+		cmd, err := New(Options{Name: "test", Flags: flags, FlagOrder: "alphabetical"})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.usageContent(), ShouldEqual, "Usage: test [options...]\n\nOptions:\n      --debug   \tTest debug\n      --name    \tTest name (required)\n  -v, --verbose \tTest verbose\n\n")
+
+		cmd, err = New(Options{Name: "test", Flags: flags, FlagOrder: "required-first"})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.usageContent(), ShouldEqual, "Usage: test [options...]\n\nOptions:\n      --name    \tTest name (required)\n  -v, --verbose \tTest verbose\n      --debug   \tTest debug\n\n")
+
+		cmd, err = New(Options{Name: "test", Flags: flags, FlagOrder: "random"})
+		So(err, ShouldResemble, errors.New("invalid flag order random"))
+		So(cmd, ShouldBeNil)
+	})
+
+	Convey("should return correct usage content (command order)", t, func() {
+		cmd, err := New(Options{
+			Name: "test",
+			Flags: &struct {
+				Zoo struct {
+				} `command:"zoo" description:"Zoo command"`
+				Verbose bool `short:"v" description:"Test verbose"`
+				Bar     struct {
+					Foo bool `long:"foo" description:"Test foo"`
+				} `command:"bar" description:"Bar command"`
+			}{},
+			CommandOrder: "alphabetical",
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.usageContent(), ShouldEqual, "Usage: test [options...] COMMAND [options...]\n\nOptions:\n  -v          \tTest verbose\n\nCommands:\n  bar         \tBar command\n        --foo \tTest foo\n  zoo         \tZoo command\n")
+
+		cmd, err = New(Options{Name: "test", Flags: &struct{}{}, CommandOrder: "random"})
+		So(err, ShouldResemble, errors.New("invalid command order random"))
+		So(cmd, ShouldBeNil)
+	})
+
+	Convey("should return correct usage content (group visibility)", t, func() {
+		cmd, err := New(Options{
+			Name: "test",
+			Flags: &struct {
+				Foo struct {
+					Host    string `long:"host" group:"Networking" description:"Test host"`
+					Verbose bool   `short:"v" description:"Test verbose"`
+					Debug   bool   `long:"debug" group:"Debugging" description:"Test debug"`
+				} `command:"foo" hide-groups:"Debugging" description:"Foo command"`
+				Bar struct {
+					Host  string `long:"host" group:"Networking" description:"Test host"`
+					Debug bool   `long:"debug" group:"Debugging" description:"Test debug"`
+				} `command:"bar" show-groups:"Debugging" description:"Bar command"`
+			}{},
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.commandUsageContent(cmd.flagSet.FlagByName("Foo")), ShouldEqual, "Usage: test foo [options...]\n\nFoo command\n\nOptions:\n  -v         \tTest verbose\n\nNetworking:\n      --host \tTest host\n\n")
+		So(cmd.commandUsageContent(cmd.flagSet.FlagByName("Bar")), ShouldEqual, "Usage: test bar [options...]\n\nBar command\n\nDebugging:\n      --debug \tTest debug\n\n")
+	})
+
+	Convey("should return correct usage content (annotations)", t, func() {
+		flags := &struct {
+			Port int `long:"port" default:"8080" env:"APP_PORT" required:"true" description:"Test port"`
+		}{}
+		cmd, err := New(Options{Name: "test", Flags: flags})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.usageContent(), ShouldEqual, "Usage: test [options...]\n\nOptions:\n      --port \tTest port (default: 8080) [$APP_PORT] (required)\n\n")
+
+		cmd, err = New(Options{Name: "test", Flags: flags, HideDefaults: true, HideRequired: true})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.usageContent(), ShouldEqual, "Usage: test [options...]\n\nOptions:\n      --port \tTest port [$APP_PORT]\n\n")
+
+		cmd, err = New(Options{Name: "test", Flags: flags, HideEnvVars: true})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.usageContent(), ShouldEqual, "Usage: test [options...]\n\nOptions:\n      --port \tTest port (default: 8080) (required)\n\n")
+	})
+
+	Convey("should return correct usage content (color)", t, func() {
+		cmd, err := New(Options{
+			Name:        "test",
+			Version:     "1.0.0",
+			Description: "Test",
+			Flags: &struct {
+				Foo bool `short:"f" long:"foo" description:"Test foo"`
+				Bar bool `short:"b" description:"Test bar"`
+			}{},
+			Color: "always",
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		usage := cmd.usageContent()
+		So(usage, ShouldNotBeEmpty)
+		So(usage, ShouldEqual, "\x1b[1mUsage:\x1b[0m test [options...]\n\nTest\n\n\x1b[1mOptions:\x1b[0m\n  \x1b[36m-f, --foo\x1b[0m \tTest foo\n  \x1b[36m-b\x1b[0m        \tTest bar\n\n")
+	})
+}
+
+func TestColorEnabled(t *testing.T) {
+	Convey("should return whether the colors are enabled", t, func() {
+		v, err := colorEnabled("always")
+		So(err, ShouldBeNil)
+		So(v, ShouldBeTrue)
+		v, err = colorEnabled("never")
+		So(err, ShouldBeNil)
+		So(v, ShouldBeFalse)
+		v, err = colorEnabled("foo")
+		So(err, ShouldResemble, errors.New("invalid color mode foo"))
+		So(v, ShouldBeFalse)
+
+		noColor, ok := os.LookupEnv("NO_COLOR")
+		os.Setenv("NO_COLOR", "1")
+		v, err = colorEnabled("auto")
+		So(err, ShouldBeNil)
+		So(v, ShouldBeFalse)
+		if ok {
+			os.Setenv("NO_COLOR", noColor)
+		} else {
+			os.Unsetenv("NO_COLOR")
+		}
+	})
+}
+
+func TestPage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pager test requires a shell")
+	}
+
+	Convey("should pipe the content through the pager", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		out := filepath.Join(dir, "out")
+		pager, ok := os.LookupEnv("PAGER")
+		defer func() {
+			if ok {
+				os.Setenv("PAGER", pager)
+			} else {
+				os.Unsetenv("PAGER")
+			}
+		}()
+
+		os.Setenv("PAGER", "sh -c 'cat > "+out+"'")
+		So(page("a\nb\nc", 0), ShouldBeFalse)
+		So(page("a\nb\nc", 4), ShouldBeFalse)
+		So(page("a\nb\nc", 3), ShouldBeTrue)
+		b, err := ioutil.ReadFile(out)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "a\nb\nc\n")
+
+		os.Setenv("PAGER", "gocmd-no-such-pager")
+		So(page("a\nb\nc", 3), ShouldBeFalse)
+	})
+}
+
+type watchFlags struct {
+	Port    int `long:"port"`
+	path    string
+	changed chan []string
+}
+
+func (f *watchFlags) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	if err := ioutil.WriteFile(f.path, []byte(`{"port": 8080}`), 0644); err != nil {
+		return err
+	}
+	select {
+	case keys := <-f.changed:
+		if len(keys) != 1 || keys[0] != "port" || f.Port != 80 {
+			return errors.New("unexpected change")
+		}
+		return nil
+	case <-time.After(5 * time.Second):
+		return errors.New("no change")
+	}
+}
+
+func TestApp_WatchConfig(t *testing.T) {
+	Convey("should reload the config files and call the callbacks", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "config.json")
+		So(ioutil.WriteFile(path, []byte(`{"port": 80}`), 0644), ShouldBeNil)
+
+		interval := configWatchInterval
+		configWatchInterval = 10 * time.Millisecond
+		defer func() { configWatchInterval = interval }()
+
+		flags := watchFlags{path: path, changed: make(chan []string, 1)}
+		app := App{Name: "test", Flags: &flags, ConfigFile: path}
+		app.WatchConfig(func(cmd *Cmd, keys []string) {
+			if cmd.FlagValue("Port") != int64(8080) {
+				keys = nil
+			}
+			flags.changed <- keys
+		})
+		resetArgs()
+		os.Args = os.Args[:1]
+		So(app.Run(), ShouldEqual, 0)
+
+		resetArgs()
+	})
+}
+
+func TestApp_watchConfig(t *testing.T) {
+	Convey("should reload the remote config files by the poll interval", t, func() {
+		requests := 0
+		var mu sync.Mutex
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			fmt.Fprint(w, `{"port": 80}`)
+		}))
+		defer server.Close()
+
+		interval, poll := configWatchInterval, configPollInterval
+		configWatchInterval = time.Millisecond
+		defer func() { configWatchInterval, configPollInterval = interval, poll }()
+
+		flags := struct {
+			Port int `long:"port"`
+		}{}
+		os.Args = os.Args[:1]
+		cmd, err := New(Options{Flags: &flags, ConfigFile: server.URL + "/app.json"})
+		So(err, ShouldBeNil)
+		So(flags.Port, ShouldEqual, 80)
+		resetArgs()
+
+		for _, v := range []struct {
+			poll time.Duration
+			want bool
+		}{
+			{time.Hour, false},
+			{0, true},
+		} {
+			mu.Lock()
+			requests = 0
+			mu.Unlock()
+			configPollInterval = v.poll
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			(&App{}).watchConfig(ctx, cmd, configState(cmd.ConfigFiles()))
+			cancel()
+			mu.Lock()
+			So(requests > 0, ShouldEqual, v.want)
+			mu.Unlock()
+		}
 	})
 }
 
@@ -152,3 +489,60 @@ func TestCmd_exit(t *testing.T) {
 		resetArgs()
 	})
 }
+
+func TestCmd_helpFlagged(t *testing.T) {
+	Convey("should detect the help flags before the terminator", t, func() {
+		flags := struct {
+			Run struct {
+				Args []string `args:"true"`
+			} `command:"run"`
+		}{}
+
+		for _, v := range []struct {
+			args    []string
+			ordered bool
+			want    bool
+		}{
+			{[]string{"run", "-h"}, false, true},
+			{[]string{"run", "--", "kubectl", "-h"}, false, false},
+			{[]string{"run", "kubectl", "-h"}, true, false},
+			{[]string{"run", "kubectl", "--help-all"}, true, false},
+			{[]string{"run", "--help-all", "kubectl"}, true, true},
+		} {
+			os.Args = append([]string{"./app"}, v.args...)
+			cmd, err := New(Options{Flags: &flags, Ordered: v.ordered})
+			So(err, ShouldBeNil)
+			So(cmd, ShouldNotBeNil)
+			So(cmd.helpFlagged(), ShouldEqual, v.want)
+		}
+		resetArgs()
+	})
+}
+
+func TestWithoutFlagArgs(t *testing.T) {
+	Convey("should return the arguments without the arguments of the flag", t, func() {
+		var b flagset.Builder
+		b.Bool("detach", "", "")
+		flags := struct {
+			Config  []string `long:"config"`
+			Message string   `short:"m" long:"message"`
+			Force   bool     `short:"f"`
+			Verbose bool     `short:"v"`
+		}{}
+		flagSet, err := flagset.New(flagset.Options{Flags: []interface{}{&flags, &b}})
+		So(err, ShouldResemble, flagSet.Err())
+
+		for _, v := range []struct {
+			name string
+			args []string
+			want []string
+		}{
+			{"detach", []string{"--detach", "-fv", "-m", "x"}, []string{"-fv", "-m", "x"}},
+			{"detach", []string{"-m=--detach", "--detach=true", "-f", "--", "--detach"}, []string{"-m=--detach", "-f", "--", "--detach"}},
+			{"config", []string{"--config", "a.json", "-f", "--config=b.json", "-m", "a.json"}, []string{"-f", "-m", "a.json"}},
+		} {
+			So(flagSet.Parse(append([]string{"./app"}, v.args...)), ShouldBeNil)
+			So(withoutFlagArgs(flagSet, flagSet.FlagByLong(v.name), v.args), ShouldResemble, v.want)
+		}
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"testing"
 
 	"github.com/devfacet/gocmd"
+	"github.com/devfacet/gocmd/flagset"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -224,6 +225,67 @@ func TestCmd_FlagArgs(t *testing.T) {
 	})
 }
 
+func TestCmd_PassthroughArgs(t *testing.T) {
+	Convey("should return the passthrough args", t, func() {
+		resetArgs()
+		os.Args = append(os.Args, "-f", "--", "-f", "bar")
+
+		cmd, err := gocmd.New(gocmd.Options{
+			Flags: &struct {
+				Foo bool `short:"f"`
+			}{},
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.PassthroughArgs(), ShouldResemble, []string{"-f", "bar"})
+
+		resetArgs()
+	})
+}
+
+func TestCmd_RawArgs(t *testing.T) {
+	Convey("should return the raw args", t, func() {
+		resetArgs()
+		os.Args = append(os.Args[:1], "-f", "foo", "-ab")
+
+		cmd, err := gocmd.New(gocmd.Options{
+			Flags: &struct {
+				Foo bool `short:"f"`
+				Bar struct {
+					A bool `short:"a"`
+					B bool `short:"b"`
+				} `command:"foo"`
+			}{},
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.RawArgs(""), ShouldResemble, []string{"-f"})
+		So(cmd.RawArgs("Bar"), ShouldResemble, []string{"-ab"})
+
+		resetArgs()
+	})
+}
+
+func TestCmd_Remaining(t *testing.T) {
+	Convey("should return the remaining args", t, func() {
+		resetArgs()
+		os.Args = append(os.Args[:1], "-f", "--bar=baz")
+
+		cmd, err := gocmd.New(gocmd.Options{
+			Flags: &struct {
+				Foo bool `short:"f"`
+			}{},
+			Partial: true,
+		})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.FlagErrors(), ShouldBeNil)
+		So(cmd.Remaining(), ShouldResemble, []string{"--bar=baz"})
+
+		resetArgs()
+	})
+}
+
 func TestCmd_FlagErrors(t *testing.T) {
 	Convey("should return the flag errors", t, func() {
 		cmd, err := gocmd.New(gocmd.Options{
@@ -317,7 +379,7 @@ func ExampleNew_usage() {
 	// A basic app
 	//
 	// Options:
-	//   -h, --help         	Display usage
+	//   -h, --help         	Display usage (global)
 	//   -v, --version      	Display version
 	//       --vv           	Display version (extended)
 	//
@@ -325,10 +387,10 @@ func ExampleNew_usage() {
 	//   echo               	Print arguments
 	//   math               	Math functions
 	//     sqrt             	Calculate square root
-	//       -n, --number   	Number
+	//       -n, --number   	Number (required)
 	//     pow              	Calculate base exponential
-	//       -b, --base     	Base
-	//       -e, --exponent 	Exponent
+	//       -b, --base     	Base (required)
+	//       -e, --exponent 	Exponent (required)
 
 	resetArgs()
 }
@@ -351,7 +413,7 @@ func ExampleNew_usage_h() {
 	// A basic app
 	//
 	// Options:
-	//   -h, --help 	Display usage
+	//   -h, --help 	Display usage (global)
 
 	resetArgs()
 }
@@ -374,7 +436,152 @@ func ExampleNew_usage_help() {
 	// A basic app
 	//
 	// Options:
-	//       --help 	Display usage
+	//       --help 	Display usage (global)
+
+	resetArgs()
+}
+
+func ExampleNew_usage_command() {
+	os.Args = []string{"gocmd.test", "math", "pow", "--help"}
+
+	gocmd.New(gocmd.Options{
+		Name:        "basic",
+		Version:     "1.0.0",
+		Description: "A basic app",
+		Flags: &struct {
+			Math struct {
+				Pow struct {
+					Base     float64 `short:"b" long:"base" required:"true" description:"Base"`
+					Exponent float64 `short:"e" long:"exponent" default:"2" env:"EXPONENT" description:"Exponent"`
+				} `command:"pow" description:"Calculate base exponential"`
+			} `command:"math" description:"Math functions"`
+		}{},
+		ConfigType: gocmd.ConfigTypeAuto,
+	})
+	// Output:
+	// Usage: basic math pow [options...]
+	//
+	// Calculate base exponential
+	//
+	// Options:
+	//   -b, --base     	Base (required)
+	//   -e, --exponent 	Exponent (default: 2) [$EXPONENT]
+
+	resetArgs()
+}
+
+func ExampleNew_usage_required() {
+	os.Args = []string{"gocmd.test", "math", "pow", "--help=required"}
+
+	gocmd.New(gocmd.Options{
+		Name: "basic",
+		Flags: &struct {
+			Math struct {
+				Pow struct {
+					Base     float64 `short:"b" long:"base" required:"true" description:"Base"`
+					Exponent float64 `short:"e" long:"exponent" description:"Exponent"`
+				} `command:"pow" description:"Calculate base exponential"`
+			} `command:"math" description:"Math functions"`
+		}{},
+		ConfigType: gocmd.ConfigTypeAuto,
+	})
+	// Output:
+	// Usage: basic math pow [options...]
+	//
+	// Calculate base exponential
+	//
+	// Options:
+	//   -b, --base 	Base (required)
+
+	resetArgs()
+}
+
+func ExampleNew_usage_seeAlso() {
+	os.Args = []string{"gocmd.test", "math", "pow", "--help"}
+
+	gocmd.New(gocmd.Options{
+		Name: "basic",
+		Flags: &struct {
+			Math struct {
+				Pow struct {
+					Base float64 `short:"b" long:"base" description:"Base"`
+				} `command:"pow" description:"Calculate base exponential" long-description:"Calculate base exponential.\nThe exponent is 2." see-also:"basic math sqrt, basic math log"`
+			} `command:"math" description:"Math functions"`
+		}{},
+		ConfigType: gocmd.ConfigTypeAuto,
+	})
+	// Output:
+	// Usage: basic math pow [options...]
+	//
+	// Calculate base exponential.
+	// The exponent is 2.
+	//
+	// Options:
+	//   -b, --base 	Base
+	//
+	// See also:
+	//   basic math sqrt
+	//   basic math log
+
+	resetArgs()
+}
+
+func ExampleNew_usage_localizer() {
+	os.Args = []string{"gocmd.test", "--help"}
+
+	gocmd.New(gocmd.Options{
+		Name: "basic",
+		Flags: &struct {
+			Help bool `short:"h" long:"help" description:"Hilfe anzeigen"`
+			Math struct {
+				Base float64 `short:"b" long:"base" default:"2" description:"Basis"`
+			} `command:"math" description:"Mathematische Funktionen"`
+		}{},
+		ConfigType: gocmd.ConfigTypeAuto,
+		Localizer: flagset.Catalog{
+			"Usage:":               "Verwendung:",
+			"[options...]":         "[Optionen...]",
+			"COMMAND [options...]": "BEFEHL [Optionen...]",
+			"Options:":             "Optionen:",
+			"Commands:":            "Befehle:",
+			"%s (default: %s)":     "%s (Standard: %s)",
+		},
+	})
+	// Output:
+	// Verwendung: basic [Optionen...] BEFEHL [Optionen...]
+	//
+	// Optionen:
+	//   -h, --help   	Hilfe anzeigen
+	//
+	// Befehle:
+	//   math         	Mathematische Funktionen
+	//     -b, --base 	Basis (Standard: 2)
+
+	resetArgs()
+}
+
+func ExampleNew_usage_width() {
+	os.Args = []string{"gocmd.test", "-h"}
+
+	gocmd.New(gocmd.Options{
+		Name:        "basic",
+		Description: "A basic app",
+		Flags: &struct {
+			Help bool   `short:"h" long:"help" description:"Display usage"`
+			Name string `long:"name" description:"Name of the resource those is created by the app"`
+		}{},
+		ConfigType: gocmd.ConfigTypeAuto,
+		Width:      48,
+	})
+	// Output:
+	// Usage: basic [options...]
+	//
+	// A basic app
+	//
+	// Options:
+	//   -h, --help 	Display usage
+	//       --name 	Name of the resource those is
+	//              	created by the app
 
 	resetArgs()
 }
@@ -436,6 +643,24 @@ func ExampleNew_version_version() {
 	resetArgs()
 }
 
+func ExampleNew_version_json() {
+	os.Args = []string{"gocmd.test", "--version=json"}
+
+	gocmd.New(gocmd.Options{
+		Name:        "basic",
+		Version:     "1.0.0",
+		Description: "A basic app",
+		Flags: &struct {
+			Version bool `long:"version" description:"Display version"`
+		}{},
+		ConfigType: gocmd.ConfigTypeAuto,
+	})
+	// Output:
+	// {"name":"basic","version":"1.0.0","goVersion":"vTest"}
+
+	resetArgs()
+}
+
 func ExampleNew_command() {
 	os.Args = []string{"gocmd.test", "math", "sqrt", "-n=9"}
 
@@ -497,3 +722,18 @@ func ExampleCmd_PrintVersion() {
 	}
 	// Output: 1.0.0
 }
+
+func ExampleCmd_PrintVersionFormat() {
+	cmd, err := gocmd.New(gocmd.Options{
+		Name:       "basic",
+		Version:    "1.0.0",
+		ConfigType: gocmd.ConfigTypeAuto,
+	})
+	if err == nil {
+		cmd.PrintVersionFormat("json")
+		fmt.Println(cmd.PrintVersionFormat("yaml"))
+	}
+	// Output:
+	// {"name":"basic","version":"1.0.0","goVersion":"vTest"}
+	// invalid version format yaml
+}
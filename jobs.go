@@ -0,0 +1,260 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devfacet/gocmd/flagset"
+	"github.com/devfacet/gocmd/table"
+)
+
+var (
+	// jobExecutable returns the executable those is started for the background jobs
+	jobExecutable = os.Executable
+)
+
+// Job represents a background job those is started by the detach flag (see App.Jobs)
+type Job struct {
+	// ID is the job id
+	ID int `json:"id"`
+	// PID is the process id of the job
+	PID int `json:"pid"`
+	// Args are the command line arguments of the job (without the program name). The values of
+	// the secret flags are redacted (see flagset.SecretPlaceholder)
+	Args []string `json:"args"`
+	// Started is the start time of the job
+	Started time.Time `json:"started"`
+	// ProcessStart is the start time of the process by the system (see processStart function).
+	// It tells the job process apart from a process those reuses its id
+	ProcessStart string `json:"process_start,omitempty"`
+}
+
+// Running returns whether the process of the job is running or not. The process must have the start
+// time of the job process so a process those reuses the process id is not the job.
+func (job *Job) Running() bool {
+	return processRunning(job.PID) && processStart(job.PID) == job.ProcessStart
+}
+
+// redactedArgs returns the arguments of the job those the values of the secret flags are redacted
+// by parsing them with the given flag set
+func (job *Job) redactedArgs(flagSet *flagset.FlagSet) []string {
+	flagSet.Parse(append([]string{""}, job.Args...)) // the parse errors are not relevant for the listing
+	return flagSet.RedactedArgs()
+}
+
+// jobDir returns the directory of the job files. Default is `<name>/jobs` in the cache directory
+// of the user (i.e. `~/.cache/app/jobs`) so the job files are not shared with the other users.
+func (app *App) jobDir(cmd *Cmd) (string, error) {
+	if app.JobsDir != "" {
+		return app.JobsDir, nil
+	}
+	name := cmd.name
+	if name == "" && len(os.Args) > 0 {
+		name = filepath.Base(os.Args[0])
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", cmd.errorf("failed to find the jobs directory: %s", err)
+	}
+	return filepath.Join(dir, name, "jobs"), nil
+}
+
+// jobs returns the background jobs in order
+func (app *App) jobs(cmd *Cmd) ([]*Job, error) {
+	dir, err := app.jobDir(cmd)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Iterate over the job files
+	var result []*Job
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		} else if len(b) == 0 {
+			continue // reserved by a job those is starting (see startJob)
+		}
+		var job Job
+		if err := json.Unmarshal(b, &job); err != nil {
+			return nil, fmt.Errorf("invalid job file %s: %s", f.Name(), err)
+		}
+		result = append(result, &job)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// job returns the background job by the given id argument
+func (app *App) job(cmd *Cmd, id string) (*Job, error) {
+	jobs, err := app.jobs(cmd)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if strconv.Itoa(job.ID) == id {
+			return job, nil
+		}
+	}
+	return nil, cmd.errorf("unknown job: %s", id)
+}
+
+// startJob starts the executable in the background with the given arguments by capturing its output.
+// The job is saved with the given redacted arguments so the secret values are not written to the disk.
+func (app *App) startJob(cmd *Cmd, args, redacted []string) (*Job, error) {
+	dir, err := app.jobDir(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	jobs, err := app.jobs(cmd)
+	if err != nil {
+		return nil, err
+	}
+	job := Job{ID: 1, Args: redacted, Started: time.Now()}
+	if len(jobs) > 0 {
+		job.ID = jobs[len(jobs)-1].ID + 1
+	}
+
+	// Reserve the job id by creating its file exclusively so the concurrent jobs get different ids
+	var f *os.File
+	for {
+		f, err = os.OpenFile(filepath.Join(dir, fmt.Sprintf("%d.json", job.ID)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			job.ID++
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		break
+	}
+	defer f.Close()
+
+	// Start the process
+	exe, err := jobExecutable()
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	out, err := os.OpenFile(filepath.Join(dir, fmt.Sprintf("%d.log", job.ID)), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	defer out.Close()
+	c := exec.Command(exe, args...)
+	c.Stdout = out
+	c.Stderr = out
+	c.SysProcAttr = detachedProcAttr()
+	if err := c.Start(); err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	job.PID = c.Process.Pid
+	job.ProcessStart = processStart(job.PID)
+	c.Process.Release()
+
+	// Save the job
+	if err := json.NewEncoder(f).Encode(job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// jobCommand runs the job commands by the given arguments (i.e. `jobs`, `logs 1` or `kill 1`) and
+// returns whether one of them is requested or not. The job commands are not available when there
+// is a top level command with the same name.
+func (app *App) jobCommand(cmd *Cmd, args []string) (bool, error) {
+	if len(args) == 0 || (args[0] != "jobs" && args[0] != "logs" && args[0] != "kill") {
+		return false, nil
+	}
+	for _, v := range cmd.flagSet.Flags() {
+		if v.Kind() == "command" && v.ParentID() == -1 && v.Command() == args[0] {
+			return false, nil
+		}
+	}
+
+	// List the jobs
+	if args[0] == "jobs" {
+		if len(args) > 1 {
+			return true, &UsageError{Err: cmd.errorf("unknown argument: %s", args[1])}
+		}
+		jobs, err := app.jobs(cmd)
+		if err != nil {
+			return true, err
+		}
+		// The arguments are parsed by a copy of the flag set for redacting the secret values
+		// (i.e. the job files those are saved by older versions)
+		flagSet, err := cmd.flagSet.Clone()
+		if err != nil {
+			return true, err
+		}
+		t := table.New(table.Options{})
+		t.AddRow("ID", "PID", "STATUS", "COMMAND")
+		for _, job := range jobs {
+			status := "exited"
+			if job.Running() {
+				status = "running"
+			}
+			t.AddRow(strconv.Itoa(job.ID), strconv.Itoa(job.PID), status, strings.Join(job.redactedArgs(flagSet), " "))
+		}
+		fmt.Print(t.FormattedData())
+		return true, nil
+	}
+
+	// Logs and kill require a job id
+	if len(args) != 2 {
+		return true, &UsageError{Err: cmd.errorf("command %s requires a job id", args[0])}
+	}
+	job, err := app.job(cmd, args[1])
+	if err != nil {
+		return true, err
+	}
+	if args[0] == "logs" {
+		dir, err := app.jobDir(cmd)
+		if err != nil {
+			return true, err
+		}
+		f, err := os.Open(filepath.Join(dir, fmt.Sprintf("%d.log", job.ID)))
+		if err != nil {
+			return true, err
+		}
+		defer f.Close()
+		_, err = io.Copy(os.Stdout, f)
+		return true, err
+	}
+	if !job.Running() {
+		return true, cmd.errorf("job %d is not running", job.ID)
+	}
+	if err := terminateProcess(job.PID); err != nil {
+		return true, cmd.errorf("failed to kill job %d: %s", job.ID, err)
+	}
+	fmt.Printf(cmd.message("job %d killed")+"\n", job.ID)
+	return true, nil
+}
@@ -0,0 +1,165 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// captureStdout returns the standard output of the given function
+func captureStdout(fn func()) string {
+	f, err := ioutil.TempFile("", "gocmd")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	stdout := os.Stdout
+	os.Stdout = f
+	fn()
+	os.Stdout = stdout
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func TestApp_Run_jobs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("job test requires a shell")
+	}
+
+	Convey("should start and manage the background jobs", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		script := filepath.Join(dir, "job")
+		So(ioutil.WriteFile(script, []byte("#!/bin/sh\necho \"$@\"\nexec sleep 10\n"), 0755), ShouldBeNil)
+		defer func(fn func() (string, error)) { jobExecutable = fn }(jobExecutable)
+		jobExecutable = func() (string, error) { return script, nil }
+
+		var buf bytes.Buffer
+		flags := struct {
+			Build struct {
+				Force bool   `short:"f"`
+				Token string `long:"token" secret:"true"`
+			} `command:"build"`
+		}{}
+		app := App{
+			Name:    "test",
+			Flags:   &flags,
+			Logger:  log.New(&buf, "", 0),
+			Jobs:    true,
+			JobsDir: filepath.Join(dir, "jobs"),
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "--detach", "build", "-f", "--token=foo")
+		var code int
+		out := captureStdout(func() { code = app.Run() })
+		So(code, ShouldEqual, 0)
+		So(out, ShouldStartWith, "job 1 started (pid ")
+		So(buf.String(), ShouldEqual, "")
+
+		// Wait for the output of the job
+		for i := 0; i < 100; i++ {
+			if b, _ := ioutil.ReadFile(filepath.Join(dir, "jobs", "1.log")); len(b) > 0 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		os.Args = append(os.Args[:1], "logs", "1")
+		out = captureStdout(func() { code = app.Run() })
+		So(code, ShouldEqual, 0)
+		So(out, ShouldEqual, "build -f --token=foo\n")
+		b, err := ioutil.ReadFile(filepath.Join(dir, "jobs", "1.json"))
+		So(err, ShouldBeNil)
+		So(string(b), ShouldContainSubstring, `"args":["build","-f","--token=***"]`)
+
+		os.Args = append(os.Args[:1], "jobs")
+		out = captureStdout(func() { code = app.Run() })
+		So(code, ShouldEqual, 0)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		So(lines, ShouldHaveLength, 2)
+		So(strings.Fields(lines[0]), ShouldResemble, []string{"ID", "PID", "STATUS", "COMMAND"})
+		So(strings.Fields(lines[1])[0], ShouldEqual, "1")
+		So(strings.Fields(lines[1])[2], ShouldEqual, "running")
+		So(lines[1], ShouldEndWith, "build -f --token=***")
+
+		// The secret values of the job files those are saved by older versions are redacted
+		b, err = json.Marshal(Job{ID: 4, PID: -1, Args: []string{"build", "--token", "foo"}})
+		So(err, ShouldBeNil)
+		So(ioutil.WriteFile(filepath.Join(dir, "jobs", "4.json"), b, 0600), ShouldBeNil)
+		out = captureStdout(func() { code = app.Run() })
+		So(code, ShouldEqual, 0)
+		lines = strings.Split(strings.TrimSpace(out), "\n")
+		So(lines, ShouldHaveLength, 3)
+		So(lines[2], ShouldEndWith, "build --token ***")
+		So(os.Remove(filepath.Join(dir, "jobs", "4.json")), ShouldBeNil)
+
+		os.Args = append(os.Args[:1], "--detach", "build", "--x")
+		out = captureStdout(func() { code = app.Run() })
+		So(code, ShouldEqual, 2)
+		So(buf.String(), ShouldEqual, "unknown argument: --x\n")
+		_, err = os.Stat(filepath.Join(dir, "jobs", "2.json"))
+		So(os.IsNotExist(err), ShouldBeTrue)
+
+		buf.Reset()
+		os.Args = append(os.Args[:1], "logs")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldEqual, "command logs requires a job id\n")
+
+		buf.Reset()
+		os.Args = append(os.Args[:1], "kill", "2")
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "unknown job: 2\n")
+
+		os.Args = append(os.Args[:1], "kill", "1")
+		out = captureStdout(func() { code = app.Run() })
+		So(code, ShouldEqual, 0)
+		So(out, ShouldEqual, "job 1 killed\n")
+
+		// A process those reuses the id of a job is not the job
+		b, err = json.Marshal(Job{ID: 3, PID: os.Getpid(), ProcessStart: "foo"})
+		So(err, ShouldBeNil)
+		So(ioutil.WriteFile(filepath.Join(dir, "jobs", "3.json"), b, 0600), ShouldBeNil)
+		buf.Reset()
+		os.Args = append(os.Args[:1], "kill", "3")
+		So(app.Run(), ShouldEqual, 1)
+		So(buf.String(), ShouldEqual, "job 3 is not running\n")
+
+		buf.Reset()
+		app.Jobs = false
+		os.Args = append(os.Args[:1], "jobs")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldStartWith, "unknown argument: jobs\nUsage: ")
+
+		resetArgs()
+	})
+
+	Convey("should return the jobs directory in the cache directory of the user", t, func() {
+		cache, err := os.UserCacheDir()
+		So(err, ShouldBeNil)
+		dir, err := (&App{}).jobDir(&Cmd{name: "test"})
+		So(err, ShouldBeNil)
+		So(dir, ShouldEqual, filepath.Join(cache, "test", "jobs"))
+		dir, err = (&App{JobsDir: "foo"}).jobDir(&Cmd{name: "test"})
+		So(err, ShouldBeNil)
+		So(dir, ShouldEqual, "foo")
+	})
+}
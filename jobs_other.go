@@ -0,0 +1,36 @@
+//go:build !unix && !windows
+// +build !unix,!windows
+
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"errors"
+	"syscall"
+)
+
+// detachedProcAttr returns the process attributes for starting the jobs
+// There is no session or process group support on this platform so it returns nil
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// processRunning returns whether the process by the given id is running or not
+// The process state is not supported on this platform so it returns false
+func processRunning(pid int) bool {
+	return false
+}
+
+// processStart returns empty string since the process state is not supported on this platform
+func processStart(pid int) string {
+	return ""
+}
+
+// terminateProcess returns an error since the termination is not supported on this platform
+func terminateProcess(pid int) error {
+	return errors.New("terminating the jobs is not supported on this platform")
+}
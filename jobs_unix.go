@@ -0,0 +1,54 @@
+//go:build unix
+// +build unix
+
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// detachedProcAttr returns the process attributes for starting the jobs in a new session
+// so they survive the exit of the terminal
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// processRunning returns whether the process by the given id is running or not
+func processRunning(pid int) bool {
+	return pid > 0 && syscall.Kill(pid, syscall.Signal(0)) == nil
+}
+
+// processStart returns the start time of the process by the given id or empty string if it's unknown.
+// It's read from `/proc` when it's available (Linux), otherwise from `ps` (i.e. macOS and BSD).
+func processStart(pid int) string {
+	if b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		// The command name may have spaces so the fields after it are used (starttime is the 22nd field)
+		if i := bytes.LastIndexByte(b, ')'); i > -1 {
+			if fields := strings.Fields(string(b[i+1:])); len(fields) > 19 {
+				return fields[19]
+			}
+		}
+		return ""
+	}
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// terminateProcess sends the termination signal to the process by the given id
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
@@ -0,0 +1,25 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"fmt"
+
+	"github.com/devfacet/gocmd/flagset"
+)
+
+// message returns the translation of the given message format by the localizer of the command
+func (cmd *Cmd) message(format string) string {
+	if cmd.localizer == nil {
+		return flagset.English.Localize(format)
+	}
+	return cmd.localizer.Localize(format)
+}
+
+// errorf returns an error by the translation of the given message format
+func (cmd *Cmd) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf(cmd.message(format), a...)
+}
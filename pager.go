@@ -0,0 +1,62 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/devfacet/gocmd/flagset"
+)
+
+// printUsageContent prints the given usage content through the pager if it's enabled and
+// the content doesn't fit the terminal, otherwise prints it to the standard output
+func (cmd *Cmd) printUsageContent(usage string) {
+	if cmd.pager && page(usage, terminalHeight()) {
+		return
+	}
+	fmt.Println(usage)
+}
+
+// page pipes the given content through the pager (`$PAGER` or `less`) when it has more lines
+// than the given terminal height and returns whether the pager is started or not.
+// It returns false when the height is 0 (i.e. not a terminal) or there is no pager.
+func page(content string, height int) bool {
+	if height <= 0 || strings.Count(content, "\n")+1 < height {
+		return false
+	}
+
+	// Find the pager
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+	args, err := flagset.SplitArgs(pager)
+	if err != nil || len(args) == 0 {
+		return false
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return false
+	}
+
+	// Start the pager (less keeps the colors and quits when the content fits the screen)
+	c := exec.Command(path, args[1:]...)
+	c.Stdin = strings.NewReader(content + "\n")
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if args[0] == "less" && os.Getenv("LESS") == "" {
+		c.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := c.Start(); err != nil {
+		return false
+	}
+	c.Wait()
+
+	return true
+}
@@ -0,0 +1,79 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// pluginCommand returns the path of the plugin executable and its arguments when the first unnamed
+// top level argument is not a command (i.e. `app-foo` on PATH for `app -v foo bar`).
+// Otherwise it returns an empty path.
+func (cmd *Cmd) pluginCommand() (string, []string) {
+	name := cmd.name
+	if name == "" && len(os.Args) > 0 {
+		name = filepath.Base(os.Args[0])
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	// Iterate over the arguments and find the first unnamed one
+	skip := false
+	for k, arg := range os.Args {
+		if k == 0 {
+			continue
+		} else if skip {
+			skip = false
+			continue
+		} else if arg == "--" {
+			break
+		}
+
+		if strings.HasPrefix(arg, "-") {
+			// Skip the value of the argument (i.e. `foo` for `--name foo`)
+			if !strings.Contains(arg, "=") {
+				if f := cmd.flagSet.FlagByArg(strings.TrimLeft(arg, "-"), ""); f != nil && f.ValueType() != "bool" && f.ValueType() != "[]bool" {
+					skip = true
+				}
+			}
+			continue
+		}
+
+		// Commands have priority over the plugins
+		for _, f := range cmd.flagSet.Flags() {
+			if f.Kind() == "command" && f.ParentID() == -1 && f.Command() == arg {
+				return "", nil
+			}
+		}
+
+		path, err := exec.LookPath(name + "-" + arg)
+		if err != nil {
+			return "", nil
+		}
+		return path, os.Args[k+1:]
+	}
+	return "", nil
+}
+
+// runPlugin runs the given plugin executable with the given arguments by inheriting the standard
+// input, output and error. It returns the exit code of the plugin.
+func runPlugin(path string, args []string) (int, error) {
+	c := exec.Command(path, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && ee.ExitCode() != -1 {
+			return ee.ExitCode(), nil
+		}
+		return 1, err
+	}
+	return 0, nil
+}
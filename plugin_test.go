@@ -0,0 +1,74 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/devfacet/gocmd"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestApp_Run_plugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test requires a shell")
+	}
+
+	Convey("should run the plugin executables for the unknown commands", t, func() {
+		dir, err := ioutil.TempDir("", "gocmd")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		out := filepath.Join(dir, "out")
+		script := "#!/bin/sh\necho \"$@\" > " + out + "\nexit 3\n"
+		So(ioutil.WriteFile(filepath.Join(dir, "test-hello"), []byte(script), 0755), ShouldBeNil)
+		path := os.Getenv("PATH")
+		os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+		defer os.Setenv("PATH", path)
+
+		var buf bytes.Buffer
+		flags := struct {
+			Verbose bool   `short:"v"`
+			Name    string `long:"name"`
+			Hello   struct {
+			} `command:"hi"`
+		}{}
+		app := gocmd.App{
+			Name:    "test",
+			Flags:   &flags,
+			Logger:  log.New(&buf, "", 0),
+			Plugins: true,
+		}
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "-v", "--name", "hello", "hello", "a", "--b")
+		So(app.Run(), ShouldEqual, 3)
+		b, err := ioutil.ReadFile(out)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "a --b\n")
+		So(buf.String(), ShouldEqual, "")
+
+		os.Args = append(os.Args[:1], "hi")
+		So(app.Run(), ShouldEqual, 0)
+
+		os.Args = append(os.Args[:1], "bye")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldStartWith, "unknown argument: bye\nUsage: ")
+
+		app.Plugins = false
+		buf.Reset()
+		os.Args = append(os.Args[:1], "hello")
+		So(app.Run(), ShouldEqual, 2)
+		So(buf.String(), ShouldStartWith, "unknown argument: hello\nUsage: ")
+
+		resetArgs()
+	})
+}
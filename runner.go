@@ -0,0 +1,211 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/devfacet/gocmd/flagset"
+)
+
+// Runner is the interface that must be implemented by the commands those run automatically.
+// The flags struct itself can implement it for running when there is no command.
+type Runner interface {
+	Run(ctx context.Context, fs *flagset.FlagSet) error
+}
+
+// BeforeRunner is the interface that can be implemented by the runners for the setup.
+// The runner doesn't run if BeforeRun returns an error.
+type BeforeRunner interface {
+	BeforeRun(ctx context.Context, fs *flagset.FlagSet) error
+}
+
+// AfterRunner is the interface that can be implemented by the runners for the teardown.
+// AfterRun is always called after BeforeRun and Run with their error (nil if there is none).
+type AfterRunner interface {
+	AfterRun(ctx context.Context, fs *flagset.FlagSet, err error) error
+}
+
+// Run runs the deepest present command that implements the Runner interface.
+// If the command doesn't implement it then its parent commands and the flags struct are checked.
+// BeforeRun and AfterRun methods of the runner are called around it (see BeforeRunner and AfterRunner).
+// When the chaining is enabled the chained commands run sequentially (see Options.Chain).
+func (cmd *Cmd) Run(ctx context.Context) error {
+	if cmd.flags == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if cmd.chain != "" {
+		if flags := cmd.flagSet.ChainedCommands(); len(flags) > 1 {
+			return cmd.runChain(ctx, flags)
+		}
+	}
+	if r := cmd.deepestRunner(cmd.flagSet.ActiveCommand()); r != nil {
+		return cmd.run(ctx, r)
+	}
+	return nil
+}
+
+// runChain runs the runners of the given commands in order by the chain policy.
+// A runner those is shared by the commands (i.e. a parent command) runs once.
+func (cmd *Cmd) runChain(ctx context.Context, flags []*flagset.Flag) error {
+	var errs []error
+	ran := map[Runner]bool{}
+	for _, flag := range flags {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		r := cmd.deepestRunner(flag)
+		if r == nil || ran[r] {
+			continue
+		}
+		ran[r] = true
+		if err := cmd.run(ctx, r); err != nil {
+			errs = append(errs, err)
+			if cmd.chain == "stop" {
+				break
+			}
+		}
+	}
+
+	if errs == nil {
+		return nil
+	} else if len(errs) == 1 {
+		return errs[0]
+	}
+	return &ChainError{Errors: errs}
+}
+
+// deepestRunner returns the runner of the given command, its parent commands or the flags struct in order.
+// It returns nil if none of them implements the Runner interface.
+func (cmd *Cmd) deepestRunner(flag *flagset.Flag) Runner {
+	// Iterate over the command and its parents
+	var index []int
+	if flag != nil {
+		index = flag.FieldIndex()
+	}
+	for i := len(index); i > 0; i-- {
+		field, ok := cmd.fieldByIndex(index[:i])
+		if !ok || !field.CanAddr() {
+			continue
+		}
+		if r, ok := field.Addr().Interface().(Runner); ok {
+			return r
+		}
+	}
+	if r, ok := cmd.flags.(Runner); ok {
+		return r
+	}
+	return nil
+}
+
+// ChainError represents the errors of the chained commands those run with the continue policy
+type ChainError struct {
+	// Errors are the runner errors in order
+	Errors []error
+}
+
+// Error returns the error messages line by line
+func (e *ChainError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the runner errors (see errors.Is and errors.As)
+func (e *ChainError) Unwrap() []error {
+	return e.Errors
+}
+
+// ExitCode returns the exit code of the first error
+func (e *ChainError) ExitCode() int {
+	return DefaultExitCode(e.Errors[0])
+}
+
+// runnerOf returns the runner of the given command or nil if it doesn't implement the Runner interface
+func (cmd *Cmd) runnerOf(flag *flagset.Flag) Runner {
+	if flag == nil {
+		return nil
+	}
+	field, ok := cmd.fieldByIndex(flag.FieldIndex())
+	if !ok || !field.CanAddr() {
+		return nil
+	}
+	if r, ok := field.Addr().Interface().(Runner); ok {
+		return r
+	}
+	return nil
+}
+
+// fieldByIndex returns the struct field of the flags by the given field index.
+// When the flags are composed (i.e. `[]interface{}{&logFlags, &tlsFlags}`), the first index is the struct index.
+// The fields of the builders are resolved by their generated structs (see flagset.FlagSet.Target).
+func (cmd *Cmd) fieldByIndex(index []int) (reflect.Value, bool) {
+	target := cmd.flagSet.Target()
+	root := reflect.ValueOf(target)
+	if flags, ok := target.([]interface{}); ok {
+		if len(index) == 0 || index[0] >= len(flags) {
+			return reflect.Value{}, false
+		}
+		root, index = reflect.ValueOf(flags[index[0]]), index[1:]
+	}
+	if root.Kind() != reflect.Ptr || root.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return root.Elem().FieldByIndex(index), true
+}
+
+// run runs the given runner with its hooks
+func (cmd *Cmd) run(ctx context.Context, r Runner) (err error) {
+	if ar, ok := r.(AfterRunner); ok {
+		defer func() {
+			if aerr := cmd.safely(func() error { return ar.AfterRun(ctx, cmd.flagSet, err) }); err == nil {
+				err = aerr
+			}
+		}()
+	}
+	if br, ok := r.(BeforeRunner); ok {
+		if err = cmd.safely(func() error { return br.BeforeRun(ctx, cmd.flagSet) }); err != nil {
+			return err
+		}
+	}
+	return cmd.safely(func() error { return r.Run(ctx, cmd.flagSet) })
+}
+
+// PanicError represents a panic those is recovered from a handler or a runner (see Options.Recover)
+type PanicError struct {
+	// Value is the value of the panic
+	Value interface{}
+	// Stack is the stack trace of the panic
+	Stack []byte
+}
+
+// Error returns the panic value and the stack trace
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// safely calls the given function and converts its panic into an error if the recovery is enabled
+func (cmd *Cmd) safely(fn func() error) (err error) {
+	if cmd.recover {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return fn()
+}
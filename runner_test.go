@@ -0,0 +1,304 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/devfacet/gocmd"
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type runnerKey struct{}
+
+type runnerApp struct {
+	Verbose bool         `short:"v"`
+	Deploy  runnerDeploy `command:"deploy"`
+	Status  struct {
+		Short bool `short:"s"`
+	} `command:"status"`
+	ran string
+}
+
+func (app *runnerApp) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	app.ran = "app"
+	return nil
+}
+
+type runnerDeploy struct {
+	Env      string         `long:"env"`
+	Rollback runnerRollback `command:"rollback"`
+	ran      string
+}
+
+func (d *runnerDeploy) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	if v, ok := ctx.Value(runnerKey{}).(string); ok {
+		d.ran = v
+	} else {
+		d.ran = "deploy"
+	}
+	if d.Env == "" {
+		return errors.New("env is required")
+	}
+	return nil
+}
+
+type runnerRollback struct {
+	ran bool
+}
+
+func (r *runnerRollback) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	r.ran = fs.ActiveCommand().Command() == "rollback"
+	return nil
+}
+
+func TestCmd_Run(t *testing.T) {
+	Convey("should run the deepest command", t, func() {
+		resetArgs()
+		os.Args = append(os.Args[:1], "deploy", "--env=prod", "rollback")
+		flags := runnerApp{}
+		cmd, err := gocmd.New(gocmd.Options{Flags: &flags})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.Run(context.Background()), ShouldBeNil)
+		So(flags.Deploy.Rollback.ran, ShouldBeTrue)
+		So(flags.Deploy.ran, ShouldEqual, "")
+		So(flags.ran, ShouldEqual, "")
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "deploy", "--env=prod")
+		flags = runnerApp{}
+		cmd, err = gocmd.New(gocmd.Options{Flags: &flags, AutoRun: true, Context: context.WithValue(context.Background(), runnerKey{}, "ctx")})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(flags.Deploy.ran, ShouldEqual, "ctx")
+		So(flags.Deploy.Env, ShouldEqual, "prod")
+
+		resetArgs()
+	})
+
+	Convey("should run the parent command or the flags", t, func() {
+		resetArgs()
+		os.Args = append(os.Args[:1], "status", "-s")
+		flags := runnerApp{}
+		cmd, err := gocmd.New(gocmd.Options{Flags: &flags, AutoRun: true})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(flags.ran, ShouldEqual, "app")
+		So(flags.Status.Short, ShouldBeTrue)
+
+		resetArgs()
+		os.Args = os.Args[:1]
+		flags = runnerApp{}
+		cmd, err = gocmd.New(gocmd.Options{Flags: &flags})
+		So(err, ShouldBeNil)
+		So(cmd.Run(nil), ShouldBeNil)
+		So(flags.ran, ShouldEqual, "app")
+
+		cmd, err = gocmd.New(gocmd.Options{Flags: &struct{}{}})
+		So(err, ShouldBeNil)
+		So(cmd.Run(context.Background()), ShouldBeNil)
+
+		resetArgs()
+	})
+
+	Convey("should run the command of the composed flags", t, func() {
+		resetArgs()
+		os.Args = append(os.Args[:1], "deploy", "--env=prod", "rollback", "--debug")
+		logFlags := struct {
+			Debug bool `long:"debug"`
+		}{}
+		flags := runnerApp{}
+		cmd, err := gocmd.New(gocmd.Options{Flags: []interface{}{&logFlags, &flags}})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.Run(context.Background()), ShouldBeNil)
+		So(flags.Deploy.Rollback.ran, ShouldBeTrue)
+		So(logFlags.Debug, ShouldBeTrue)
+
+		resetArgs()
+		os.Args = append(os.Args[:1], "status", "-s")
+		flags = runnerApp{}
+		cmd, err = gocmd.New(gocmd.Options{Flags: []interface{}{&logFlags, &flags}, AutoRun: true})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(flags.ran, ShouldEqual, "app")
+
+		resetArgs()
+	})
+
+	Convey("should return the runner error", t, func() {
+		resetArgs()
+		os.Args = append(os.Args[:1], "deploy")
+		flags := runnerApp{}
+		cmd, err := gocmd.New(gocmd.Options{Flags: &flags, AutoRun: true})
+		So(err, ShouldBeError, errors.New("env is required"))
+		So(cmd, ShouldBeNil)
+		So(flags.Deploy.ran, ShouldEqual, "deploy")
+
+		resetArgs()
+	})
+}
+
+type runnerHooks struct {
+	Fail  string `long:"fail"`
+	calls []string
+}
+
+func (h *runnerHooks) BeforeRun(ctx context.Context, fs *flagset.FlagSet) error {
+	h.calls = append(h.calls, "before")
+	if h.Fail == "before" {
+		return errors.New("before failed")
+	}
+	return nil
+}
+
+func (h *runnerHooks) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	h.calls = append(h.calls, "run")
+	if h.Fail == "run" {
+		return errors.New("run failed")
+	} else if h.Fail == "panic" {
+		panic("run panicked")
+	}
+	return nil
+}
+
+func (h *runnerHooks) AfterRun(ctx context.Context, fs *flagset.FlagSet, err error) error {
+	if err != nil {
+		h.calls = append(h.calls, "after: "+err.Error())
+	} else {
+		h.calls = append(h.calls, "after")
+	}
+	if h.Fail == "after" {
+		return errors.New("after failed")
+	}
+	return nil
+}
+
+func TestCmd_Run_hooks(t *testing.T) {
+	Convey("should run the hooks around the runner", t, func() {
+		for _, v := range []struct {
+			fail  string
+			err   error
+			calls []string
+		}{
+			{"", nil, []string{"before", "run", "after"}},
+			{"before", errors.New("before failed"), []string{"before", "after: before failed"}},
+			{"run", errors.New("run failed"), []string{"before", "run", "after: run failed"}},
+			{"after", errors.New("after failed"), []string{"before", "run", "after"}},
+		} {
+			resetArgs()
+			os.Args = append(os.Args[:1], "--fail="+v.fail)
+			flags := runnerHooks{}
+			cmd, err := gocmd.New(gocmd.Options{Flags: &flags})
+			So(err, ShouldBeNil)
+			So(cmd, ShouldNotBeNil)
+			if v.err == nil {
+				So(cmd.Run(context.Background()), ShouldBeNil)
+			} else {
+				So(cmd.Run(context.Background()), ShouldBeError, v.err)
+			}
+			So(flags.calls, ShouldResemble, v.calls)
+		}
+
+		resetArgs()
+	})
+}
+
+type runnerChain struct {
+	Build runnerStep `command:"build"`
+	Test  runnerStep `command:"test"`
+	Lint  struct {
+	} `command:"lint"`
+	Publish runnerStep `command:"publish"`
+	ran     []string
+}
+
+func (c *runnerChain) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	c.ran = append(c.ran, "app")
+	return nil
+}
+
+type runnerStep struct {
+	Fail bool `long:"fail"`
+	ran  bool
+}
+
+func (s *runnerStep) Run(ctx context.Context, fs *flagset.FlagSet) error {
+	s.ran = true
+	if s.Fail {
+		return errors.New("step failed")
+	}
+	return nil
+}
+
+func TestCmd_Run_chain(t *testing.T) {
+	Convey("should run the chained commands in order", t, func() {
+		resetArgs()
+		os.Args = append(os.Args[:1], "build", "lint", "test", "--fail", "publish")
+		flags := runnerChain{}
+		cmd, err := gocmd.New(gocmd.Options{Flags: &flags, Chain: "stop"})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(cmd.Run(context.Background()), ShouldResemble, errors.New("step failed"))
+		So(flags.Build.ran, ShouldEqual, true)
+		So(flags.ran, ShouldResemble, []string{"app"})
+		So(flags.Test.ran, ShouldEqual, true)
+		So(flags.Publish.ran, ShouldEqual, false)
+
+		os.Args = append(os.Args[:1], "build", "--fail", "test", "--fail", "publish")
+		flags = runnerChain{}
+		cmd, err = gocmd.New(gocmd.Options{Flags: &flags, Chain: "continue"})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		err = cmd.Run(context.Background())
+		So(err, ShouldResemble, &gocmd.ChainError{Errors: []error{errors.New("step failed"), errors.New("step failed")}})
+		So(err.Error(), ShouldEqual, "step failed\nstep failed")
+		So(gocmd.DefaultExitCode(err), ShouldEqual, 1)
+		So(errors.Is(&gocmd.ChainError{Errors: []error{err, context.Canceled}}, context.Canceled), ShouldBeTrue)
+		So(flags.Publish.ran, ShouldEqual, true)
+
+		cmd, err = gocmd.New(gocmd.Options{Flags: &flags, Chain: "first"})
+		So(err, ShouldResemble, errors.New("invalid chain policy first"))
+		So(cmd, ShouldBeNil)
+
+		cmd, err = gocmd.New(gocmd.Options{Flags: &flags, AnyError: true})
+		So(err, ShouldResemble, errors.New("command test can't be used with command build"))
+		So(cmd, ShouldBeNil)
+
+		resetArgs()
+	})
+}
+
+func TestCmd_Run_recover(t *testing.T) {
+	Convey("should convert the panics into errors", t, func() {
+		resetArgs()
+		os.Args = append(os.Args[:1], "--fail=panic")
+		flags := runnerHooks{}
+		cmd, err := gocmd.New(gocmd.Options{Flags: &flags, Recover: true})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		err = cmd.Run(context.Background())
+		So(err, ShouldHaveSameTypeAs, &gocmd.PanicError{})
+		So(err.(*gocmd.PanicError).Value, ShouldEqual, "run panicked")
+		So(err.(*gocmd.PanicError).Stack, ShouldNotBeEmpty)
+		So(err.Error(), ShouldStartWith, "panic: run panicked\n\n")
+		So(flags.calls, ShouldHaveLength, 3)
+		So(flags.calls[2], ShouldStartWith, "after: panic: run panicked")
+
+		flags = runnerHooks{}
+		cmd, err = gocmd.New(gocmd.Options{Flags: &flags})
+		So(err, ShouldBeNil)
+		So(cmd, ShouldNotBeNil)
+		So(func() { cmd.Run(context.Background()) }, ShouldPanic)
+
+		resetArgs()
+	})
+}
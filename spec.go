@@ -0,0 +1,154 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"encoding/json"
+
+	"github.com/devfacet/gocmd/flagset"
+)
+
+// Spec represents the machine-readable description of an app (see App.Spec)
+type Spec struct {
+	// Name is the app name
+	Name string `json:"name"`
+	// Version is the app version
+	Version string `json:"version,omitempty"`
+	// Description is the app description
+	Description string `json:"description,omitempty"`
+	// Options are the top level arguments
+	Options []SpecOption `json:"options,omitempty"`
+	// Commands are the top level commands
+	Commands []SpecCommand `json:"commands,omitempty"`
+}
+
+// SpecCommand represents a command of the spec
+type SpecCommand struct {
+	// Name is the command name
+	Name string `json:"name"`
+	// Description is the command description
+	Description string `json:"description,omitempty"`
+	// LongDescription is the detailed description of the command
+	LongDescription string `json:"longDescription,omitempty"`
+	// Default is whether the command is used when no other command of its level is present
+	Default bool `json:"default,omitempty"`
+	// Required is whether the command must be present (and one of its subcommands if it has any)
+	Required bool `json:"required,omitempty"`
+	// Deprecated is the deprecation message of the command (`true` for none)
+	Deprecated string `json:"deprecated,omitempty"`
+	// Examples are the invocation examples of the command
+	Examples []string `json:"examples,omitempty"`
+	// SeeAlso are the related commands or references of the command
+	SeeAlso []string `json:"seeAlso,omitempty"`
+	// Options are the arguments of the command
+	Options []SpecOption `json:"options,omitempty"`
+	// Commands are the subcommands of the command
+	Commands []SpecCommand `json:"commands,omitempty"`
+}
+
+// SpecOption represents an argument of the spec
+type SpecOption struct {
+	// Name is the field name of the argument
+	Name string `json:"name"`
+	// Short is the short argument name (i.e. `v` for `-v`)
+	Short string `json:"short,omitempty"`
+	// Long is the long argument name (i.e. `verbose` for `--verbose`)
+	Long string `json:"long,omitempty"`
+	// Type is the value type of the argument (i.e. `bool`, `string` or `[]int`)
+	Type string `json:"type"`
+	// Placeholder is the value name of the argument for usage
+	Placeholder string `json:"placeholder,omitempty"`
+	// Description is the argument description
+	Description string `json:"description,omitempty"`
+	// Group is the group name of the argument
+	Group string `json:"group,omitempty"`
+	// Default is the default value of the argument (secret ones are masked)
+	Default string `json:"default,omitempty"`
+	// Env is the env variable name of the argument
+	Env string `json:"env,omitempty"`
+	// Required is whether the argument must be present or not
+	Required bool `json:"required,omitempty"`
+	// Global is whether the argument is available for the subcommands or not
+	Global bool `json:"global,omitempty"`
+	// Secret is whether the value of the argument is masked or not
+	Secret bool `json:"secret,omitempty"`
+	// Advanced is whether the argument is shown only in the verbose usage or not
+	Advanced bool `json:"advanced,omitempty"`
+	// Validate are the names of the validators of the argument values
+	Validate []string `json:"validate,omitempty"`
+	// Examples are the invocation examples of the argument
+	Examples []string `json:"examples,omitempty"`
+}
+
+// Spec returns the JSON description of the full command and argument tree of the app (see Spec type)
+// for the external tools such as documentation sites, GUIs and completion engines. The flags of
+// the app are not changed.
+func (app *App) Spec() ([]byte, error) {
+	spec := Spec{Name: app.Name, Version: app.Version, Description: app.Description}
+
+	// Parse a copy of the flags without arguments
+	if app.Flags != nil {
+		flags, err := flagset.ZeroFlags(app.Flags)
+		if err != nil {
+			return nil, err
+		}
+		flagSet, err := flagset.New(flagset.Options{Flags: flags, Args: []string{app.Name}, EnvPrefix: app.EnvPrefix, AutoEnv: app.AutoEnv})
+		if flagSet == nil {
+			return nil, err
+		}
+		spec.Options, spec.Commands = specItems(flagSet.Flags(), -1)
+	}
+
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// specItems returns the arguments and the commands of the spec by the given parent id in order
+func specItems(flags []*flagset.Flag, parentID int) ([]SpecOption, []SpecCommand) {
+	var options []SpecOption
+	var commands []SpecCommand
+	for _, flag := range flags {
+		if flag.ParentID() != parentID {
+			continue
+		}
+		if flag.Kind() == "command" {
+			command := SpecCommand{
+				Name:            flag.Command(),
+				Description:     flag.Description(),
+				LongDescription: flag.LongDescription(),
+				Default:         flag.DefaultCommand(),
+				Required:        flag.Required(),
+				Deprecated:      flag.Deprecated(),
+				Examples:        flag.Examples(),
+				SeeAlso:         flag.SeeAlso(),
+			}
+			command.Options, command.Commands = specItems(flags, flag.ID())
+			commands = append(commands, command)
+		} else if flag.Kind() == "arg" {
+			def := flag.ValueDefault()
+			if def != "" && flag.Secret() {
+				def = flagset.SecretPlaceholder
+			}
+			options = append(options, SpecOption{
+				Name:        flag.Name(),
+				Short:       flag.Short(),
+				Long:        flag.Long(),
+				Type:        flag.ValueType(),
+				Placeholder: flag.Placeholder(),
+				Description: flag.Description(),
+				Group:       flag.Group(),
+				Default:     def,
+				Env:         flag.Env(),
+				Required:    flag.Required(),
+				Global:      flag.Global(),
+				Secret:      flag.Secret(),
+				Advanced:    flag.Advanced(),
+				Validate:    flag.Validate(),
+				Examples:    flag.Examples(),
+			})
+		}
+	}
+	return options, commands
+}
@@ -0,0 +1,115 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd_test
+
+import (
+	"testing"
+
+	"github.com/devfacet/gocmd"
+	"github.com/devfacet/gocmd/flagset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestApp_Spec(t *testing.T) {
+	Convey("should return the spec of the app", t, func() {
+		flags := struct {
+			Verbose bool   `short:"v" long:"verbose" description:"Display verbose output"`
+			Token   string `long:"token" env:"APP_TOKEN" default:"abc" secret:"true"`
+			Deploy  struct {
+				Env  string `short:"e" long:"env" placeholder:"ENV" default:"dev" required:"true" validate:"nonempty"`
+				Undo struct {
+				} `command:"undo" deprecated:"true"`
+			} `command:"deploy" description:"Deploy the app" example:"app deploy -e prod"`
+		}{}
+		app := gocmd.App{Name: "app", Version: "1.0.0", Flags: &flags}
+		b, err := app.Spec()
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{
+  "name": "app",
+  "version": "1.0.0",
+  "options": [
+    {
+      "name": "Verbose",
+      "short": "v",
+      "long": "verbose",
+      "type": "bool",
+      "description": "Display verbose output"
+    },
+    {
+      "name": "Token",
+      "long": "token",
+      "type": "string",
+      "default": "***",
+      "env": "APP_TOKEN",
+      "secret": true
+    }
+  ],
+  "commands": [
+    {
+      "name": "deploy",
+      "description": "Deploy the app",
+      "examples": [
+        "app deploy -e prod"
+      ],
+      "options": [
+        {
+          "name": "Env",
+          "short": "e",
+          "long": "env",
+          "type": "string",
+          "placeholder": "ENV",
+          "default": "dev",
+          "required": true,
+          "validate": [
+            "nonempty"
+          ]
+        }
+      ],
+      "commands": [
+        {
+          "name": "undo",
+          "deprecated": "true"
+        }
+      ]
+    }
+  ]
+}`)
+		So(flags.Token, ShouldEqual, "")
+
+		app = gocmd.App{Name: "app", Flags: struct{}{}}
+		_, err = app.Spec()
+		So(err, ShouldBeError, "flags must be a struct pointer")
+	})
+
+	Convey("should return the spec of the builder flags", t, func() {
+		var fb flagset.Builder
+		name := fb.String("name", "n", "Name of the user", flagset.WithDefault("x"))
+		fb.Command("deploy", "Deploy the app")
+		app := gocmd.App{Name: "app", Flags: &fb}
+		b, err := app.Spec()
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{
+  "name": "app",
+  "options": [
+    {
+      "name": "Name",
+      "short": "n",
+      "long": "name",
+      "type": "string",
+      "description": "Name of the user",
+      "default": "x"
+    }
+  ],
+  "commands": [
+    {
+      "name": "deploy",
+      "description": "Deploy the app"
+    }
+  ]
+}`)
+		So(*name, ShouldEqual, "")
+	})
+}
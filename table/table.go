@@ -9,28 +9,45 @@ package table
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
 )
 
 // Options represents the options that can be set when creating a new table
 type Options struct {
 	Data [][]string
+	// Width wraps the last column of the rows by words to fit the given width (0 for no wrapping)
+	Width int
+	// Separator is the separator of the columns. Default is tab
+	Separator string
 }
 
 // New returns a table by the given options
 func New(o Options) *Table {
 	// Init vars
 	t := Table{
-		data: o.Data,
+		data:      o.Data,
+		width:     o.Width,
+		separator: o.Separator,
+	}
+	if t.separator == "" {
+		t.separator = "\t"
 	}
 	return &t
 }
 
 // Table represent a table
 type Table struct {
-	data     [][]string
-	colSizes map[int]int
+	data      [][]string
+	colSizes  map[int]int
+	width     int
+	separator string
 }
 
 // Data returns the data of the table
@@ -69,8 +86,8 @@ func (t *Table) SetData(row, col int, val string) error {
 		t.colSizes = make(map[int]int)
 	}
 
-	if len(val) > t.colSizes[col-1] {
-		t.colSizes[col-1] = len(val)
+	if l := visibleLen(val); l > t.colSizes[col-1] {
+		t.colSizes[col-1] = l
 	}
 
 	return nil
@@ -104,15 +121,76 @@ func (t *Table) FormattedData() string {
 	// Iterate over the rows and prepare result
 	result := ""
 	rowVal := ""
-	colSize := ""
 	for _, row := range t.data {
-		rowVal = ""
-		for i, c := range row {
-			colSize = fmt.Sprintf("%d", t.colSizes[i])
-			rowVal += fmt.Sprintf("%-"+colSize+"s\t", c)
+		// Wrapped lines of the last column are aligned by the empty columns
+		lines := []string{""}
+		if len(row) > 0 {
+			lines = t.wrap(row[len(row)-1], len(row)-1)
+		}
+		for k, line := range lines {
+			rowVal = ""
+			for i, c := range row {
+				if i == len(row)-1 {
+					c = line
+				} else if k > 0 {
+					c = ""
+				}
+				rowVal += c
+				if pad := t.colSizes[i] - visibleLen(c); pad > 0 {
+					rowVal += strings.Repeat(" ", pad)
+				}
+				rowVal += t.separator
+			}
+			result += fmt.Sprintf("%s\n", strings.TrimRightFunc(rowVal, unicode.IsSpace))
 		}
-		result += fmt.Sprintf("%s\n", strings.TrimRightFunc(rowVal, unicode.IsSpace))
 	}
 
 	return result
 }
+
+// visibleLen returns the number of the characters of the given value without the ANSI escape sequences (i.e. colors)
+func visibleLen(val string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(val, ""))
+}
+
+// wrap returns the lines of the given value those fit the width after the given number of columns
+// The value is not wrapped if there is no room for at least 20 characters
+func (t *Table) wrap(val string, col int) []string {
+	// Find the position of the column by expanding the tabs (8 characters)
+	pos := 0
+	for i := 0; i < col; i++ {
+		if t.separator == "\t" {
+			pos = (pos+t.colSizes[i])/8*8 + 8
+		} else {
+			pos += t.colSizes[i] + visibleLen(t.separator)
+		}
+	}
+	size := t.width - pos
+	if t.width <= 0 || col == 0 || size < 20 {
+		return []string{val}
+	}
+	return Wrap(val, size)
+}
+
+// Wrap returns the lines of the given value by words those fit the given width (0 for no wrapping)
+// The words those are longer than the width are not split.
+func Wrap(val string, width int) []string {
+	if width <= 0 || visibleLen(val) <= width {
+		return []string{val}
+	}
+
+	// Iterate over the words
+	var result []string
+	line := ""
+	for _, word := range strings.Fields(val) {
+		if line != "" && visibleLen(line)+1+visibleLen(word) > width {
+			result = append(result, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return append(result, line)
+}
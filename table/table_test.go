@@ -96,4 +96,44 @@ func TestTable_FormattedData(t *testing.T) {
 		So(t.AddRow("foo", "bar"), ShouldBeNil)
 		So(t.FormattedData(), ShouldEqual, "foo	bar\n")
 	})
+
+	Convey("should wrap the last column by the width", t, func() {
+		t := table.New(table.Options{Width: 40})
+
+		So(t, ShouldNotBeNil)
+		So(t.AddRow("Options:"), ShouldBeNil)
+		So(t.AddRow("  -f, --foo", "Lorem ipsum dolor sit amet, consectetur adipiscing elit"), ShouldBeNil)
+		So(t.AddRow("  -b", "Short"), ShouldBeNil)
+		So(t.FormattedData(), ShouldEqual, "Options:\n"+
+			"  -f, --foo	Lorem ipsum dolor sit\n"+
+			"           	amet, consectetur\n"+
+			"           	adipiscing elit\n"+
+			"  -b       	Short\n")
+
+		t = table.New(table.Options{Width: 20})
+		So(t.AddRow("  -f, --foo", "Lorem ipsum dolor sit amet"), ShouldBeNil)
+		So(t.FormattedData(), ShouldEqual, "  -f, --foo	Lorem ipsum dolor sit amet\n")
+	})
+
+	Convey("should separate the columns by the separator", t, func() {
+		t := table.New(table.Options{Width: 36, Separator: "   "})
+
+		So(t, ShouldNotBeNil)
+		So(t.AddRow("  -f, --foo", "Lorem ipsum dolor sit amet"), ShouldBeNil)
+		So(t.AddRow("  -b", "Short"), ShouldBeNil)
+		So(t.FormattedData(), ShouldEqual, "  -f, --foo   Lorem ipsum dolor sit\n"+
+			"              amet\n"+
+			"  -b          Short\n")
+	})
+}
+
+func TestWrap(t *testing.T) {
+	Convey("should wrap the value by words", t, func() {
+		So(table.Wrap("foo bar baz", 0), ShouldResemble, []string{"foo bar baz"})
+		So(table.Wrap("foo bar baz", 11), ShouldResemble, []string{"foo bar baz"})
+		So(table.Wrap("foo bar baz", 7), ShouldResemble, []string{"foo bar", "baz"})
+		So(table.Wrap("foobarbaz qux", 5), ShouldResemble, []string{"foobarbaz", "qux"})
+		So(table.Wrap("äöü ßéè çñå", 7), ShouldResemble, []string{"äöü ßéè", "çñå"})
+		So(table.Wrap("\x1b[1mfoo\x1b[0m bar baz", 7), ShouldResemble, []string{"\x1b[1mfoo\x1b[0m bar", "baz"})
+	})
 }
@@ -0,0 +1,40 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// terminalWidth returns the width of the terminal those is attached to the standard output
+// It returns 0 when the standard output is not a terminal
+func terminalWidth() int {
+	col, _ := terminalSize()
+	return col
+}
+
+// terminalHeight returns the height of the terminal those is attached to the standard output
+// It returns 0 when the standard output is not a terminal
+func terminalHeight() int {
+	_, row := terminalSize()
+	return row
+}
+
+// terminalSize returns the number of the columns and the rows of the terminal
+func terminalSize() (int, int) {
+	var ws struct {
+		row, col, xpixel, ypixel uint16
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0, 0
+	}
+	return int(ws.col), int(ws.row)
+}
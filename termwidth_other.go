@@ -0,0 +1,21 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+// terminalWidth returns the width of the terminal those is attached to the standard output
+// The detection is not supported on this platform so it returns 0 (see Options.Width)
+func terminalWidth() int {
+	return 0
+}
+
+// terminalHeight returns the height of the terminal those is attached to the standard output
+// The detection is not supported on this platform so it returns 0 (see Options.Pager)
+func terminalHeight() int {
+	return 0
+}
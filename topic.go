@@ -0,0 +1,57 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/devfacet/gocmd/table"
+)
+
+// HelpTopic represents a non-executable help topic for the conceptual documentation
+// (i.e. `app help environment`)
+type HelpTopic struct {
+	// Name is the topic name those is used with the help command
+	Name string
+	// Description is the one line description of the topic for the usage
+	Description string
+	// Body is the text of the topic. The paragraphs are wrapped to the usage width and
+	// the lines those start with a whitespace are kept as is (i.e. code samples)
+	Body string
+}
+
+// helpTopic returns the help topic by the given arguments (i.e. `help environment`) or nil if
+// it's not requested. The commands take precedence over the topics with the same name.
+func (cmd *Cmd) helpTopic(args []string) *HelpTopic {
+	if len(args) != 2 || args[0] != "help" {
+		return nil
+	}
+	for _, v := range cmd.flagSet.Flags() {
+		if v.Kind() == "command" && v.ParentID() == -1 && (v.Command() == "help" || v.Command() == args[1]) {
+			return nil
+		}
+	}
+	for i := range cmd.topics {
+		if cmd.topics[i].Name == args[1] {
+			return &cmd.topics[i]
+		}
+	}
+	return nil
+}
+
+// topicContent returns the content of the given help topic
+func (cmd *Cmd) topicContent(topic *HelpTopic) string {
+	var lines []string
+	for _, line := range strings.Split(strings.Trim(topic.Body, "\n"), "\n") {
+		if line == "" || unicode.IsSpace(rune(line[0])) {
+			lines = append(lines, strings.TrimRightFunc(line, unicode.IsSpace))
+			continue
+		}
+		lines = append(lines, table.Wrap(line, cmd.usageWidth())...)
+	}
+	return strings.Join(lines, "\n")
+}
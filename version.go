@@ -0,0 +1,143 @@
+/*
+ * gocmd
+ * For the full copyright and license information, please view the LICENSE.txt file.
+ */
+
+package gocmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/devfacet/gocmd/flagset"
+)
+
+// VersionInfo represents the version information of a command
+type VersionInfo struct {
+	// Name is the command name
+	Name string `json:"name"`
+	// Version is the command version
+	Version string `json:"version"`
+	// Revision is the VCS revision of the build if any
+	Revision string `json:"revision,omitempty"`
+	// BuildDate is the VCS commit time of the build if any
+	BuildDate string `json:"buildDate,omitempty"`
+	// GoVersion is the Go version of the build
+	GoVersion string `json:"goVersion"`
+}
+
+// VersionInfo returns the version information of the command
+// VCS revision and build date are read from the build information (see debug.ReadBuildInfo)
+func (cmd *Cmd) VersionInfo() VersionInfo {
+	result := VersionInfo{
+		Name:      cmd.Name(),
+		Version:   strings.TrimPrefix(cmd.Version(), "v"),
+		GoVersion: runtime.Version(),
+	}
+	result.Revision, result.BuildDate = buildInfo()
+
+	// Update the build information for tests
+	if cmd.isTest() {
+		result.Revision, result.BuildDate, result.GoVersion = "", "", "vTest"
+	}
+
+	return result
+}
+
+// PrintVersionJSON prints the version information in JSON format
+func (cmd *Cmd) PrintVersionJSON() {
+	b, err := json.Marshal(cmd.VersionInfo())
+	if err != nil {
+		cmd.logger.Printf("%s\n", err)
+		return
+	}
+	fmt.Println(string(b))
+}
+
+// versionCommand returns whether the version command is requested by the given arguments
+// (i.e. `version` or `version --json`) and whether it's in JSON format or not. The version command
+// is not available when there is a top level command with the same name.
+func (cmd *Cmd) versionCommand(args []string) (bool, bool) {
+	if len(args) == 0 || args[0] != "version" || len(args) > 2 {
+		return false, false
+	} else if len(args) == 2 && args[1] != "--json" {
+		return false, false
+	}
+	for _, v := range cmd.flagSet.Flags() {
+		if v.Kind() == "command" && v.ParentID() == -1 && v.Command() == "version" {
+			return false, false
+		}
+	}
+	return true, len(args) == 2
+}
+
+// PrintVersionFormat prints the version information in the given format: short (version only),
+// full (with the build information) or json (see VersionInfo)
+func (cmd *Cmd) PrintVersionFormat(format string) error {
+	switch format {
+	case "short":
+		cmd.PrintVersion(false)
+	case "full":
+		cmd.PrintVersion(true)
+	case "json":
+		cmd.PrintVersionJSON()
+	default:
+		return fmt.Errorf("invalid version format %s", format)
+	}
+	return nil
+}
+
+// versionFormat returns the format of the requested version by the version flags (i.e. `short` for `-v`,
+// `full` for `--vv` or `json` for `--version=json`) or empty string if it's not requested
+func (cmd *Cmd) versionFormat() (string, error) {
+	if format, err := cmd.versionArgFormat(); err != nil || format != "" {
+		return format, err
+	}
+	ver, verEx := cmd.versionRequested()
+	if verEx {
+		return "full", nil
+	} else if ver && cmd.versionFmt != "" {
+		return cmd.versionFmt, nil
+	} else if ver {
+		return "short", nil
+	}
+	return "", nil
+}
+
+// versionArgFormat returns the format of the version flag those has a value (i.e. `json` for `--version=json`,
+// `-v=json` or `--version json`) or empty string if there is none. The value is parsed by the version flag
+// so it returns an error for an invalid format or for the other flag errors.
+func (cmd *Cmd) versionArgFormat() (string, error) {
+	// Find the version argument those has a non-bool value
+	ids := map[int]bool{}
+	for _, f := range []*flagset.Flag{cmd.flagSet.FlagByArg("v", ""), cmd.flagSet.FlagByArg("version", "")} {
+		if f != nil {
+			ids[f.ID()] = true
+		}
+	}
+	var arg *flagset.Arg
+	for _, v := range cmd.flagSet.Args() {
+		if v.Kind() == "arg" && v.Err() != nil && ids[v.FlagID()] {
+			arg = v
+			break
+		}
+	}
+	if arg == nil {
+		return "", nil
+	}
+
+	// Check the format and the other errors
+	switch arg.Value() {
+	case "short", "full", "json":
+	default:
+		return "", cmd.errorf("invalid version format %s", arg.Value())
+	}
+	for _, err := range cmd.flagSet.Errors() {
+		if err != arg.Err() {
+			return "", err
+		}
+	}
+	return arg.Value(), nil
+}